@@ -1,10 +1,13 @@
 package auctioncellrep
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/executor"
@@ -15,9 +18,11 @@ import (
 
 //go:generate counterfeiter . AuctionCellClient
 
+// AuctionCellClient stops calling the executor once ctx is done, since the
+// caller has given up on the result.
 type AuctionCellClient interface {
-	State(logger lager.Logger) (rep.CellState, bool, error)
-	Perform(logger lager.Logger, work rep.Work) (rep.Work, error)
+	State(ctx context.Context, logger lager.Logger) (rep.CellState, bool, error)
+	Perform(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error)
 	Reset() error
 }
 
@@ -25,14 +30,39 @@ var ErrPreloadedRootFSNotFound = errors.New("preloaded rootfs path not found")
 var ErrCellUnhealthy = errors.New("internal cell healthcheck failed")
 
 type AuctionCellRep struct {
-	cellID                string
+	cellID               string
+	stack                string
+	zone                 string
+	generateInstanceGuid func() (string, error)
+	client               executor.Client
+	evacuationReporter   evacuation_context.EvacuationReporter
+	cordonReporter       evacuation_context.CordonReporter
+	maxPidsCapacity      int32
+	cpuWeightCapacity    int32
+	labels               map[string]string
+	resourceCeiling      *rep.Resources
+	extendedResources    map[string]int32
+	usageSampler         *UsageSampler
+	topology             []string
+	arbitraryRootFSes    []string
+	rootFSPatterns       map[string]rep.PatternRootFSProvider
+	advertisePatterns    bool
+
+	placementLock sync.RWMutex
+	placement     placementConfig
+
+	// allocationLock makes charging work against the budget and allocating
+	// it one step, so that concurrent auctions see each other's containers.
+	allocationLock sync.Mutex
+}
+
+// placementConfig holds the settings that can be reloaded while the rep is
+// running. It is replaced as a whole so that an auction never sees a mix of
+// old and new settings.
+type placementConfig struct {
 	stackPathMap          rep.StackPathMap
 	rootFSProviders       rep.RootFSProviders
-	stack                 string
-	zone                  string
-	generateInstanceGuid  func() (string, error)
-	client                executor.Client
-	evacuationReporter    evacuation_context.EvacuationReporter
+	advertisedProviders   rep.RootFSProviders
 	placementTags         []string
 	optionalPlacementTags []string
 }
@@ -45,38 +75,96 @@ func New(
 	generateInstanceGuid func() (string, error),
 	client executor.Client,
 	evacuationReporter evacuation_context.EvacuationReporter,
+	cordonReporter evacuation_context.CordonReporter,
 	placementTags []string,
 	optionalPlacementTags []string,
+	maxPidsCapacity int32,
+	cpuWeightCapacity int32,
+	labels map[string]string,
+	resourceCeiling *rep.Resources,
+	extendedResources map[string]int32,
+	usageSampler *UsageSampler,
+	topology []string,
+	rootFSPatterns map[string]rep.PatternRootFSProvider,
+	advertisePatterns bool,
 ) *AuctionCellRep {
-	return &AuctionCellRep{
-		cellID:                cellID,
+	a := &AuctionCellRep{
+		cellID:               cellID,
+		zone:                 zone,
+		generateInstanceGuid: generateInstanceGuid,
+		client:               client,
+		evacuationReporter:   evacuationReporter,
+		cordonReporter:       cordonReporter,
+		maxPidsCapacity:      maxPidsCapacity,
+		cpuWeightCapacity:    cpuWeightCapacity,
+		labels:               labels,
+		resourceCeiling:      resourceCeiling,
+		extendedResources:    extendedResources,
+		usageSampler:         usageSampler,
+		topology:             topology,
+		arbitraryRootFSes:    arbitraryRootFSes,
+		rootFSPatterns:       rootFSPatterns,
+		advertisePatterns:    advertisePatterns,
+	}
+	a.UpdatePlacement(preloadedStackPathMap, placementTags, optionalPlacementTags)
+	return a
+}
+
+// UpdatePlacement replaces the preloaded stacks and placement tags the cell
+// advertises. Auctions already in flight finish with the previous settings.
+func (a *AuctionCellRep) UpdatePlacement(preloadedStackPathMap rep.StackPathMap, placementTags, optionalPlacementTags []string) {
+	config := placementConfig{
 		stackPathMap:          preloadedStackPathMap,
-		rootFSProviders:       rootFSProviders(preloadedStackPathMap, arbitraryRootFSes),
-		zone:                  zone,
-		generateInstanceGuid:  generateInstanceGuid,
-		client:                client,
-		evacuationReporter:    evacuationReporter,
+		rootFSProviders:       rootFSProviders(preloadedStackPathMap, a.arbitraryRootFSes, a.rootFSPatterns),
 		placementTags:         placementTags,
 		optionalPlacementTags: optionalPlacementTags,
 	}
+	config.advertisedProviders = config.rootFSProviders
+	if !a.advertisePatterns {
+		config.advertisedProviders = withoutPatterns(config.rootFSProviders)
+	}
+
+	a.placementLock.Lock()
+	a.placement = config
+	a.placementLock.Unlock()
 }
 
-func rootFSProviders(preloaded rep.StackPathMap, arbitrary []string) rep.RootFSProviders {
+func (a *AuctionCellRep) placementConfig() placementConfig {
+	a.placementLock.RLock()
+	defer a.placementLock.RUnlock()
+	return a.placement
+}
+
+func rootFSProviders(preloaded rep.StackPathMap, arbitrary []string, patterns map[string]rep.PatternRootFSProvider) rep.RootFSProviders {
 	rootFSProviders := rep.RootFSProviders{}
 	for _, scheme := range arbitrary {
 		rootFSProviders[scheme] = rep.ArbitraryRootFSProvider{}
 	}
-
-	stacks := make([]string, 0, len(preloaded))
-	for stack, _ := range preloaded {
-		stacks = append(stacks, stack)
+	for scheme, provider := range patterns {
+		rootFSProviders[scheme] = provider
 	}
+
+	stacks := preloaded.Stacks()
 	rootFSProviders[models.PreloadedRootFSScheme] = rep.NewFixedSetRootFSProvider(stacks...)
 	rootFSProviders[models.PreloadedOCIRootFSScheme] = rep.NewFixedSetRootFSProvider(stacks...)
 
 	return rootFSProviders
 }
 
+// withoutPatterns replaces the pattern providers with arbitrary ones, for
+// auctioneers that cannot decode pattern providers. The cell still rejects
+// work the patterns do not allow when it is asked to perform it.
+func withoutPatterns(providers rep.RootFSProviders) rep.RootFSProviders {
+	advertised := rep.RootFSProviders{}
+	for scheme, provider := range providers {
+		if provider.Type() == rep.RootFSProviderTypePattern {
+			provider = rep.ArbitraryRootFSProvider{}
+		}
+		advertised[scheme] = provider
+	}
+	return advertised
+}
+
 func PathForRootFS(rootFS string, stackPathMap rep.StackPathMap) (string, error) {
 	if rootFS == "" {
 		return rootFS, nil
@@ -88,18 +176,24 @@ func PathForRootFS(rootFS string, stackPathMap rep.StackPathMap) (string, error)
 	}
 
 	if url.Scheme == models.PreloadedRootFSScheme {
-		path, ok := stackPathMap[url.Opaque]
+		path, ok := stackPathMap.PathForStack(url.Opaque, url.Query().Get(rep.StackVersionParam))
 		if !ok {
 			return "", ErrPreloadedRootFSNotFound
 		}
 		return path, nil
 	} else if url.Scheme == models.PreloadedOCIRootFSScheme {
-		path, ok := stackPathMap[url.Opaque]
+		query := url.Query()
+		path, ok := stackPathMap.PathForStack(url.Opaque, query.Get(rep.StackVersionParam))
 		if !ok {
 			return "", ErrPreloadedRootFSNotFound
 		}
 
-		return fmt.Sprintf("%s:%s?%s", url.Scheme, path, url.RawQuery), nil
+		rawQuery := url.RawQuery
+		if _, ok := query[rep.StackVersionParam]; ok {
+			query.Del(rep.StackVersionParam)
+			rawQuery = query.Encode()
+		}
+		return fmt.Sprintf("%s:%s?%s", url.Scheme, path, rawQuery), nil
 	}
 
 	return rootFS, nil
@@ -107,10 +201,14 @@ func PathForRootFS(rootFS string, stackPathMap rep.StackPathMap) (string, error)
 
 // State currently does not return tasks or lrp rootfs, because the
 // auctioneer currently does not need them.
-func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error) {
+func (a *AuctionCellRep) State(ctx context.Context, logger lager.Logger) (rep.CellState, bool, error) {
 	logger = logger.Session("auction-state")
 	logger.Info("providing")
 
+	if err := abandoned(ctx, logger); err != nil {
+		return rep.CellState{}, false, err
+	}
+
 	containers, err := a.client.ListContainers(logger)
 	if err != nil {
 		logger.Error("failed-to-fetch-containers", err)
@@ -129,6 +227,10 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 		return rep.CellState{}, false, err
 	}
 
+	if err := abandoned(ctx, logger); err != nil {
+		return rep.CellState{}, false, err
+	}
+
 	volumeDrivers, err := a.client.VolumeDrivers(logger)
 	if err != nil {
 		logger.Error("failed-to-get-volume-drivers", err)
@@ -140,6 +242,7 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 	lrps := []rep.LRP{}
 	tasks := []rep.Task{}
 	startingContainerCount := 0
+	remaining := a.budget(containers)
 
 	for i := range containers {
 		container := &containers[i]
@@ -153,7 +256,8 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 			continue
 		}
 
-		resource := rep.Resource{MemoryMB: int32(container.MemoryMB), DiskMB: int32(container.DiskMB)}
+		extended := rep.ExtendedResourcesFromTags(container.Tags)
+		resource := rep.Resource{MemoryMB: int32(container.MemoryMB), DiskMB: int32(container.DiskMB), Extended: extended}
 		placementConstraint := rep.PlacementConstraint{}
 
 		switch container.Tags[rep.LifecycleTag] {
@@ -170,19 +274,54 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 		}
 	}
 
+	total := a.convertResources(totalResources)
+	available := a.convertResources(availableResources)
+	if a.maxPidsCapacity > 0 {
+		total.MaxPids = a.maxPidsCapacity
+		available.MaxPids = remaining.pids
+	}
+	if a.cpuWeightCapacity > 0 {
+		total.CPUWeight = a.cpuWeightCapacity
+		available.CPUWeight = remaining.cpuWeight
+	}
+	if len(a.extendedResources) > 0 {
+		total.Extended = map[string]int32{}
+		available.Extended = map[string]int32{}
+		for name, capacity := range a.extendedResources {
+			total.Extended[name] = capacity
+			available.Extended[name] = remaining.extended[name]
+		}
+	}
+
+	config := a.placementConfig()
 	state := rep.NewCellState(
-		a.rootFSProviders,
-		a.convertResources(availableResources),
-		a.convertResources(totalResources),
+		config.advertisedProviders,
+		available,
+		total,
 		lrps,
 		tasks,
 		a.zone,
 		startingContainerCount,
 		a.evacuationReporter.Evacuating(),
 		volumeDrivers,
-		a.placementTags,
-		a.optionalPlacementTags,
+		config.placementTags,
+		config.optionalPlacementTags,
 	)
+	state.Labels = a.labels
+	state.Topology = a.topology
+	state.Cordoned = a.cordonReporter.Cordoned()
+	state.ResourceCeiling = a.resourceCeiling
+
+	if err := abandoned(ctx, logger); err != nil {
+		return rep.CellState{}, false, err
+	}
+
+	if a.usageSampler != nil {
+		state.Usage, err = a.usageSampler.Sample(logger, a.client)
+		if err != nil {
+			logger.Error("failed-to-sample-container-usage", err)
+		}
+	}
 
 	healthy := a.client.Healthy(logger)
 	if !healthy {
@@ -195,18 +334,40 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 		"num-lrps":            len(state.LRPs),
 		"zone":                state.Zone,
 		"evacuating":          state.Evacuating,
+		"cordoned":            state.Cordoned,
+		"usage":               state.Usage,
 	})
 
 	return state, healthy, nil
 }
 
+// containerCPUWeight prefers the weight recorded at allocation time, since
+// the run info is only populated once the container has been run.
+func containerCPUWeight(container *executor.Container) int32 {
+	if weight, err := strconv.Atoi(container.Tags[rep.CPUWeightTag]); err == nil {
+		return int32(weight)
+	}
+	return int32(container.CPUWeight)
+}
+
 func containerIsStarting(container *executor.Container) bool {
 	return container.State == executor.StateReserved ||
 		container.State == executor.StateInitializing ||
 		container.State == executor.StateCreated
 }
 
-func (a *AuctionCellRep) Perform(logger lager.Logger, work rep.Work) (rep.Work, error) {
+// abandoned returns the error of ctx once the caller has given up.
+func abandoned(ctx context.Context, logger lager.Logger) error {
+	err := ctx.Err()
+	if err != nil {
+		logger.Info("caller-gave-up", lager.Data{"error": err.Error()})
+	}
+	return err
+}
+
+// Perform does not allocate work once ctx is done; that work is returned as
+// failed, along with the work that was rejected.
+func (a *AuctionCellRep) Perform(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error) {
 	var failedWork = rep.Work{}
 
 	logger = logger.Session("auction-work", lager.Data{
@@ -218,21 +379,61 @@ func (a *AuctionCellRep) Perform(logger lager.Logger, work rep.Work) (rep.Work,
 		return work, nil
 	}
 
+	if a.cordonReporter.Cordoned() {
+		logger.Info("rejecting-work-while-cordoned")
+		return cordonedWork(work), nil
+	}
+
+	if err := abandoned(ctx, logger); err != nil {
+		return rep.Work{}, err
+	}
+
+	config := a.placementConfig()
+	placement := a.placementState(logger, &config, work)
+
+	var remaining *budget
+	if a.requiresBudget(work) {
+		a.allocationLock.Lock()
+		defer a.allocationLock.Unlock()
+		remaining = a.remainingBudget(logger)
+	}
+
 	if len(work.LRPs) > 0 {
 		lrpLogger := logger.Session("lrp-allocate-instances")
 
-		requests, lrpMap, untranslatedLRPs := a.lrpsToAllocationRequest(work.LRPs)
+		lrps := make([]rep.LRP, 0, len(work.LRPs))
+		for i := range work.LRPs {
+			lrp := &work.LRPs[i]
+			if reason := placementViolation(&placement, &lrp.PlacementConstraint); reason != "" {
+				lrpLogger.Info("rejected-lrp-placement", lager.Data{"lrp": lrp.Identifier(), "reason": reason})
+				failedWork.LRPs = append(failedWork.LRPs, *lrp)
+				failedWork.AddFailureReason(lrp.Identifier(), reason)
+				continue
+			}
+			reason := ceilingViolation(a.resourceCeiling, &lrp.Resource)
+			if reason == "" {
+				reason = remaining.charge(&lrp.Resource)
+			}
+			if reason != "" {
+				lrpLogger.Info("rejected-lrp-resources", lager.Data{"lrp": lrp.Identifier(), "reason": reason})
+				failedWork.LRPs = append(failedWork.LRPs, *lrp)
+				failedWork.AddFailureReason(lrp.Identifier(), reason)
+				continue
+			}
+			lrps = append(lrps, *lrp)
+		}
+
+		requests, lrpMap, untranslatedLRPs := a.lrpsToAllocationRequest(lrps, config.stackPathMap)
 		if len(untranslatedLRPs) > 0 {
 			lrpLogger.Info("failed-to-translate-lrps-to-containers", lager.Data{"num-failed-to-translate": len(untranslatedLRPs)})
-			failedWork.LRPs = untranslatedLRPs
 		}
 
-		lrpLogger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
-		failures, err := a.client.AllocateContainers(logger, requests)
+		failures, err := a.allocate(ctx, lrpLogger, requests)
 		if err != nil {
 			lrpLogger.Error("failed-requesting-container-allocation", err)
-			failedWork.LRPs = work.LRPs
+			failedWork.LRPs = append(failedWork.LRPs, lrps...)
 		} else {
+			failedWork.LRPs = append(failedWork.LRPs, untranslatedLRPs...)
 			lrpLogger.Info("succeeded-requesting-container-allocation", lager.Data{"num-failed-to-allocate": len(failures)})
 			for i := range failures {
 				failure := &failures[i]
@@ -247,18 +448,39 @@ func (a *AuctionCellRep) Perform(logger lager.Logger, work rep.Work) (rep.Work,
 	if len(work.Tasks) > 0 {
 		taskLogger := logger.Session("task-allocate-instances")
 
-		requests, taskMap, failedTasks := a.tasksToAllocationRequests(work.Tasks)
+		tasks := make([]rep.Task, 0, len(work.Tasks))
+		for i := range work.Tasks {
+			task := &work.Tasks[i]
+			if reason := placementViolation(&placement, &task.PlacementConstraint); reason != "" {
+				taskLogger.Info("rejected-task-placement", lager.Data{"task-guid": task.TaskGuid, "reason": reason})
+				failedWork.Tasks = append(failedWork.Tasks, *task)
+				failedWork.AddFailureReason(task.Identifier(), reason)
+				continue
+			}
+			reason := ceilingViolation(a.resourceCeiling, &task.Resource)
+			if reason == "" {
+				reason = remaining.charge(&task.Resource)
+			}
+			if reason != "" {
+				taskLogger.Info("rejected-task-resources", lager.Data{"task-guid": task.TaskGuid, "reason": reason})
+				failedWork.Tasks = append(failedWork.Tasks, *task)
+				failedWork.AddFailureReason(task.Identifier(), reason)
+				continue
+			}
+			tasks = append(tasks, *task)
+		}
+
+		requests, taskMap, failedTasks := a.tasksToAllocationRequests(tasks, config.stackPathMap)
 		if len(failedTasks) > 0 {
 			taskLogger.Info("failed-to-translate-tasks-to-containers", lager.Data{"num-failed-to-translate": len(failedTasks)})
-			failedWork.Tasks = failedTasks
 		}
 
-		taskLogger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
-		failures, err := a.client.AllocateContainers(logger, requests)
+		failures, err := a.allocate(ctx, taskLogger, requests)
 		if err != nil {
 			taskLogger.Error("failed-requesting-container-allocation", err)
-			failedWork.Tasks = work.Tasks
+			failedWork.Tasks = append(failedWork.Tasks, tasks...)
 		} else {
+			failedWork.Tasks = append(failedWork.Tasks, failedTasks...)
 			taskLogger.Info("succeeded-requesting-container-allocation", lager.Data{"num-failed-to-allocate": len(failures)})
 			for i := range failures {
 				failure := &failures[i]
@@ -273,7 +495,101 @@ func (a *AuctionCellRep) Perform(logger lager.Logger, work rep.Work) (rep.Work,
 	return failedWork, nil
 }
 
-func (a *AuctionCellRep) lrpsToAllocationRequest(lrps []rep.LRP) ([]executor.AllocationRequest, map[string]*rep.LRP, []rep.LRP) {
+// allocate does not reserve the containers once the caller has given up, and
+// does not call the executor when there is nothing to reserve.
+func (a *AuctionCellRep) allocate(ctx context.Context, logger lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	if err := abandoned(ctx, logger); err != nil {
+		return nil, err
+	}
+
+	logger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
+	return a.client.AllocateContainers(logger, requests)
+}
+
+// placementState returns the subset of the cell state needed to check
+// placement constraints. The volume drivers are only listed when some of the
+// work requires one; if they cannot be listed, that work is rejected.
+func (a *AuctionCellRep) placementState(logger lager.Logger, config *placementConfig, work rep.Work) rep.CellState {
+	state := rep.CellState{
+		RootFSProviders:       config.rootFSProviders,
+		PlacementTags:         config.placementTags,
+		OptionalPlacementTags: config.optionalPlacementTags,
+		Labels:                a.labels,
+	}
+
+	if requiresVolumeDrivers(work) {
+		volumeDrivers, err := a.client.VolumeDrivers(logger)
+		if err != nil {
+			logger.Error("failed-to-get-volume-drivers", err)
+		}
+		state.VolumeDrivers = volumeDrivers
+	}
+
+	return state
+}
+
+func requiresVolumeDrivers(work rep.Work) bool {
+	for i := range work.LRPs {
+		if len(work.LRPs[i].VolumeDrivers) > 0 {
+			return true
+		}
+	}
+	for i := range work.Tasks {
+		if len(work.Tasks[i].VolumeDrivers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// cordonedWork returns all of the work as failed, since a cordoned cell
+// takes none.
+func cordonedWork(work rep.Work) rep.Work {
+	failedWork := rep.Work{LRPs: work.LRPs, Tasks: work.Tasks}
+	for i := range work.LRPs {
+		failedWork.AddFailureReason(work.LRPs[i].Identifier(), rep.CellCordonedReason)
+	}
+	for i := range work.Tasks {
+		failedWork.AddFailureReason(work.Tasks[i].Identifier(), rep.CellCordonedReason)
+	}
+	return failedWork
+}
+
+func placementViolation(cellState *rep.CellState, pc *rep.PlacementConstraint) string {
+	explanation := cellState.ExplainConstraints(pc)
+	if pc.RootFs == "" {
+		// a blank rootfs is passed through to the executor as is
+		explanation.RootFS.Matched = true
+	}
+	return explanation.Reason()
+}
+
+// ceilingViolation returns the reason the resource does not fit in a single
+// container on an overcommitted cell. Auctioneers that predate the ceiling
+// ignore it when they place work, so the cell has to check it itself.
+func ceilingViolation(ceiling *rep.Resources, resource *rep.Resource) string {
+	if ceiling == nil {
+		return ""
+	}
+
+	short := []string{}
+	if ceiling.DiskMB > 0 && resource.DiskMB > ceiling.DiskMB {
+		short = append(short, "disk")
+	}
+	if ceiling.MemoryMB > 0 && resource.MemoryMB > ceiling.MemoryMB {
+		short = append(short, "memory")
+	}
+	if len(short) == 0 {
+		return ""
+	}
+	return "insufficient resources: " + strings.Join(short, ", ")
+}
+
+func (a *AuctionCellRep) lrpsToAllocationRequest(lrps []rep.LRP, stackPathMap rep.StackPathMap) ([]executor.AllocationRequest, map[string]*rep.LRP, []rep.LRP) {
 	requests := make([]executor.AllocationRequest, 0, len(lrps))
 	untranslatedLRPs := make([]rep.LRP, 0)
 	lrpMap := make(map[string]*rep.LRP, len(lrps))
@@ -292,8 +608,12 @@ func (a *AuctionCellRep) lrpsToAllocationRequest(lrps []rep.LRP) ([]executor.All
 		tags[rep.ProcessIndexTag] = strconv.Itoa(int(lrp.Index))
 		tags[rep.LifecycleTag] = rep.LRPLifecycle
 		tags[rep.InstanceGuidTag] = instanceGuid
+		if lrp.CPUWeight > 0 {
+			tags[rep.CPUWeightTag] = strconv.Itoa(int(lrp.CPUWeight))
+		}
+		rep.AddExtendedResourceTags(tags, lrp.Extended)
 
-		rootFSPath, err := PathForRootFS(lrp.RootFs, a.stackPathMap)
+		rootFSPath, err := PathForRootFS(lrp.RootFs, stackPathMap)
 		if err != nil {
 			untranslatedLRPs = append(untranslatedLRPs, *lrp)
 			continue
@@ -309,7 +629,7 @@ func (a *AuctionCellRep) lrpsToAllocationRequest(lrps []rep.LRP) ([]executor.All
 	return requests, lrpMap, untranslatedLRPs
 }
 
-func (a *AuctionCellRep) tasksToAllocationRequests(tasks []rep.Task) ([]executor.AllocationRequest, map[string]*rep.Task, []rep.Task) {
+func (a *AuctionCellRep) tasksToAllocationRequests(tasks []rep.Task, stackPathMap rep.StackPathMap) ([]executor.AllocationRequest, map[string]*rep.Task, []rep.Task) {
 	failedTasks := make([]rep.Task, 0)
 	taskMap := make(map[string]*rep.Task, len(tasks))
 	requests := make([]executor.AllocationRequest, 0, len(tasks))
@@ -317,7 +637,7 @@ func (a *AuctionCellRep) tasksToAllocationRequests(tasks []rep.Task) ([]executor
 	for i := range tasks {
 		task := &tasks[i]
 		taskMap[task.TaskGuid] = task
-		rootFSPath, err := PathForRootFS(task.RootFs, a.stackPathMap)
+		rootFSPath, err := PathForRootFS(task.RootFs, stackPathMap)
 		if err != nil {
 			failedTasks = append(failedTasks, *task)
 			continue
@@ -325,6 +645,10 @@ func (a *AuctionCellRep) tasksToAllocationRequests(tasks []rep.Task) ([]executor
 		tags := executor.Tags{}
 		tags[rep.LifecycleTag] = rep.TaskLifecycle
 		tags[rep.DomainTag] = task.Domain
+		if task.CPUWeight > 0 {
+			tags[rep.CPUWeightTag] = strconv.Itoa(int(task.CPUWeight))
+		}
+		rep.AddExtendedResourceTags(tags, task.Extended)
 
 		resource := executor.NewResource(int(task.MemoryMB), int(task.DiskMB), int(task.MaxPids), rootFSPath)
 		requests = append(requests, executor.NewAllocationRequest(task.TaskGuid, &resource, tags))
@@ -1,12 +1,17 @@
 package auctioncellrep_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/executor"
 	fake_client "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
@@ -26,8 +31,10 @@ var _ = Describe("AuctionCellRep", func() {
 	var (
 		cellRep            auctioncellrep.AuctionCellClient
 		client             *fake_client.FakeClient
+		ctx                context.Context
 		logger             *lagertest.TestLogger
 		evacuationReporter *fake_evacuation_context.FakeEvacuationReporter
+		cordonReporter     *fake_evacuation_context.FakeCordonReporter
 
 		expectedGuid, linuxRootFSURL string
 		commonErr, expectedGuidError error
@@ -35,12 +42,29 @@ var _ = Describe("AuctionCellRep", func() {
 		fakeGenerateContainerGuid func() (string, error)
 
 		placementTags, optionalPlacementTags []string
+
+		maxPidsCapacity, cpuWeightCapacity int32
+
+		labels map[string]string
+
+		resourceCeiling *rep.Resources
+
+		extendedResources map[string]int32
+
+		usageSampler *auctioncellrep.UsageSampler
+
+		topology []string
+
+		rootFSPatterns    map[string]rep.PatternRootFSProvider
+		advertisePatterns bool
 	)
 
 	BeforeEach(func() {
 		client = new(fake_client.FakeClient)
+		ctx = context.Background()
 		logger = lagertest.NewTestLogger("test")
 		evacuationReporter = &fake_evacuation_context.FakeEvacuationReporter{}
+		cordonReporter = &fake_evacuation_context.FakeCordonReporter{}
 
 		expectedGuid = "container-guid"
 		expectedGuidError = nil
@@ -51,6 +75,18 @@ var _ = Describe("AuctionCellRep", func() {
 
 		commonErr = errors.New("Failed to fetch")
 		client.HealthyReturns(true)
+
+		placementTags = nil
+		optionalPlacementTags = nil
+		maxPidsCapacity = 0
+		cpuWeightCapacity = 0
+		labels = nil
+		resourceCeiling = nil
+		extendedResources = nil
+		usageSampler = nil
+		topology = nil
+		rootFSPatterns = nil
+		advertisePatterns = false
 	})
 
 	JustBeforeEach(func() {
@@ -62,8 +98,18 @@ var _ = Describe("AuctionCellRep", func() {
 			fakeGenerateContainerGuid,
 			client,
 			evacuationReporter,
+			cordonReporter,
 			placementTags,
 			optionalPlacementTags,
+			maxPidsCapacity,
+			cpuWeightCapacity,
+			labels,
+			resourceCeiling,
+			extendedResources,
+			usageSampler,
+			topology,
+			rootFSPatterns,
+			advertisePatterns,
 		)
 	})
 
@@ -139,7 +185,7 @@ var _ = Describe("AuctionCellRep", func() {
 		})
 
 		It("queries the client and returns state", func() {
-			state, healthy, err := cellRep.State(logger)
+			state, healthy, err := cellRep.State(ctx, logger)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(healthy).To(BeTrue())
@@ -190,13 +236,32 @@ var _ = Describe("AuctionCellRep", func() {
 			Expect(state.VolumeDrivers).To(ConsistOf(volumeDrivers))
 		})
 
+		It("does not report the cell as cordoned", func() {
+			state, _, err := cellRep.State(ctx, logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Cordoned).To(BeFalse())
+		})
+
+		Context("when the cell is cordoned", func() {
+			BeforeEach(func() {
+				cordonReporter.CordonedReturns(true)
+			})
+
+			It("reports the cell as unschedulable", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.Cordoned).To(BeTrue())
+				Expect(state.Schedulable()).To(BeFalse())
+			})
+		})
+
 		Context("when the cell is not healthy", func() {
 			BeforeEach(func() {
 				client.HealthyReturns(false)
 			})
 
 			It("errors when reporting state", func() {
-				_, healthy, err := cellRep.State(logger)
+				_, healthy, err := cellRep.State(ctx, logger)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(healthy).To(BeFalse())
 			})
@@ -208,7 +273,7 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 
 			It("should return an error and no state", func() {
-				_, _, err := cellRep.State(logger)
+				_, _, err := cellRep.State(ctx, logger)
 				Expect(err).To(MatchError(commonErr))
 			})
 		})
@@ -219,7 +284,7 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 
 			It("should return an error and no state", func() {
-				_, _, err := cellRep.State(logger)
+				_, _, err := cellRep.State(ctx, logger)
 				Expect(err).To(MatchError(commonErr))
 			})
 		})
@@ -230,18 +295,179 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 
 			It("should return an error and no state", func() {
-				_, _, err := cellRep.State(logger)
+				_, _, err := cellRep.State(ctx, logger)
 				Expect(err).To(MatchError(commonErr))
 			})
 		})
 
+		Context("when the caller has given up", func() {
+			BeforeEach(func() {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			})
+
+			It("returns the error of the context without calling the executor", func() {
+				_, _, err := cellRep.State(ctx, logger)
+				Expect(err).To(Equal(context.Canceled))
+				Expect(client.ListContainersCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when pid and cpu weight capacities are configured", func() {
+			BeforeEach(func() {
+				maxPidsCapacity = 1000
+				cpuWeightCapacity = 400
+
+				containers[0].Tags[rep.CPUWeightTag] = "50"
+				containers[1].CPUWeight = 25
+				client.ListContainersReturns(containers, nil)
+			})
+
+			It("reports the configured totals and subtracts the allocated containers", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.TotalResources.MaxPids).To(BeEquivalentTo(1000))
+				Expect(state.TotalResources.CPUWeight).To(BeEquivalentTo(400))
+				Expect(state.AvailableResources.MaxPids).To(BeEquivalentTo(600))
+				Expect(state.AvailableResources.CPUWeight).To(BeEquivalentTo(325))
+			})
+		})
+
+		Context("when extended resources are configured", func() {
+			BeforeEach(func() {
+				extendedResources = map[string]int32{"ssd-slots": 2, "licence-seats": 4, "numa-slots": 1}
+
+				containers[0].Tags[rep.ExtendedResourceTagPrefix+"ssd-slots"] = "1"
+				containers[2].Tags[rep.ExtendedResourceTagPrefix+"licence-seats"] = "2"
+				client.ListContainersReturns(containers, nil)
+			})
+
+			It("reports the configured totals and subtracts the allocated containers", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.TotalResources.Extended).To(Equal(extendedResources))
+				Expect(state.AvailableResources.Extended).To(Equal(map[string]int32{"ssd-slots": 1, "licence-seats": 2, "numa-slots": 1}))
+			})
+
+			It("reports the extended resources allocated to each container", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.LRPs[0].Extended).To(Equal(map[string]int32{"ssd-slots": 1}))
+				Expect(state.Tasks[0].Extended).To(Equal(map[string]int32{"licence-seats": 2}))
+			})
+		})
+
+		Context("when usage sampling is configured", func() {
+			var fakeClock *fakeclock.FakeClock
+
+			BeforeEach(func() {
+				fakeClock = fakeclock.NewFakeClock(time.Now())
+				usageSampler = auctioncellrep.NewUsageSampler(fakeClock, 2)
+
+				client.GetBulkMetricsReturns(map[string]executor.Metrics{
+					"first": {ContainerMetrics: executor.ContainerMetrics{
+						MemoryUsageInBytes: 100 * 1024 * 1024,
+						DiskUsageInBytes:   10 * 1024 * 1024,
+						TimeSpentInCPU:     time.Second,
+					}},
+					"second": {ContainerMetrics: executor.ContainerMetrics{
+						MemoryUsageInBytes: 50 * 1024 * 1024,
+						DiskUsageInBytes:   20 * 1024 * 1024,
+						TimeSpentInCPU:     2 * time.Second,
+					}},
+				}, nil)
+			})
+
+			It("reports the measured memory and disk usage", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.Usage).To(Equal(&rep.Usage{MemoryMB: 150, DiskMB: 30}))
+			})
+
+			It("reports the cpu used since the previous sample", func() {
+				_, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				client.GetBulkMetricsReturns(map[string]executor.Metrics{
+					"first":  {ContainerMetrics: executor.ContainerMetrics{TimeSpentInCPU: 3 * time.Second}},
+					"second": {ContainerMetrics: executor.ContainerMetrics{TimeSpentInCPU: 3 * time.Second}},
+					"third":  {ContainerMetrics: executor.ContainerMetrics{TimeSpentInCPU: 5 * time.Second}},
+				}, nil)
+				fakeClock.Increment(2 * time.Second)
+
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.Usage.CPU).To(BeNumerically("~", 0.75))
+			})
+
+			Context("when the metrics cannot be fetched", func() {
+				BeforeEach(func() {
+					client.GetBulkMetricsReturns(nil, commonErr)
+				})
+
+				It("still returns the state without usage", func() {
+					state, _, err := cellRep.State(ctx, logger)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(state.Usage).To(BeNil())
+				})
+			})
+		})
+
+		Context("when usage sampling is not configured", func() {
+			It("does not fetch metrics", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.Usage).To(BeNil())
+				Expect(client.GetBulkMetricsCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when overcommit is configured", func() {
+			BeforeEach(func() {
+				resourceCeiling = &rep.Resources{MemoryMB: 1000, DiskMB: 2000}
+			})
+
+			It("advertises the capacity the executor allocates against", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.TotalResources).To(Equal(rep.NewResources(1024, 2048, 4)))
+				Expect(state.AvailableResources).To(Equal(rep.NewResources(512, 256, 2)))
+			})
+
+			It("caps a single container at the physical capacity less the reserved headroom", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.ResourceCeiling).To(Equal(&rep.Resources{MemoryMB: 1000, DiskMB: 2000}))
+				resource := rep.NewResource(1001, 10, 0)
+				Expect(state.ResourceMatch(&resource)).To(MatchError("insufficient resources: memory"))
+			})
+		})
+
+		Context("when overcommit is not configured", func() {
+			It("does not advertise a resource ceiling", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.ResourceCeiling).To(BeNil())
+
+				payload, err := json.Marshal(state)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(payload)).NotTo(ContainSubstring("ResourceCeiling"))
+			})
+		})
+
 		Context("when placement tags have been set", func() {
 			BeforeEach(func() {
 				placementTags = []string{"quack", "oink"}
 			})
 
 			It("returns the tags as part of the state", func() {
-				state, healthy, err := cellRep.State(logger)
+				state, healthy, err := cellRep.State(ctx, logger)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(healthy).To(BeTrue())
 				Expect(state.PlacementTags).To(ConsistOf(placementTags))
@@ -254,12 +480,64 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 
 			It("returns the tags as part of the state", func() {
-				state, healthy, err := cellRep.State(logger)
+				state, healthy, err := cellRep.State(ctx, logger)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(healthy).To(BeTrue())
 				Expect(state.OptionalPlacementTags).To(ConsistOf(optionalPlacementTags))
 			})
 		})
+
+		Context("when a topology has been set", func() {
+			BeforeEach(func() {
+				topology = []string{"region", "the-zone", "rack-1"}
+			})
+
+			It("returns the topology alongside the zone", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.Topology).To(Equal(topology))
+				Expect(state.Zone).To(Equal("the-zone"))
+			})
+		})
+
+		Context("when a scheme has rootfs patterns", func() {
+			BeforeEach(func() {
+				provider, err := rep.NewPatternRootFSProvider(rep.RootFSPatterns{Hosts: []string{"registry.internal"}}, rep.RootFSPatterns{})
+				Expect(err).NotTo(HaveOccurred())
+				rootFSPatterns = map[string]rep.PatternRootFSProvider{"docker": provider}
+			})
+
+			It("advertises the scheme as arbitrary", func() {
+				state, _, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.RootFSProviders["docker"]).To(Equal(rep.ArbitraryRootFSProvider{}))
+			})
+
+			Context("when the patterns are advertised", func() {
+				BeforeEach(func() {
+					advertisePatterns = true
+				})
+
+				It("advertises the pattern provider", func() {
+					state, _, err := cellRep.State(ctx, logger)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(state.RootFSProviders["docker"]).To(Equal(rootFSPatterns["docker"]))
+				})
+			})
+		})
+
+		Context("when labels have been set", func() {
+			BeforeEach(func() {
+				labels = map[string]string{"disk": "ssd"}
+			})
+
+			It("returns the labels as part of the state", func() {
+				state, healthy, err := cellRep.State(ctx, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(healthy).To(BeTrue())
+				Expect(state.Labels).To(Equal(labels))
+			})
+		})
 	})
 
 	Describe("Perform", func() {
@@ -294,7 +572,98 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 
 			It("returns all work it was given", func() {
-				Expect(cellRep.Perform(logger, work)).To(Equal(work))
+				Expect(cellRep.Perform(ctx, logger, work)).To(Equal(work))
+			})
+		})
+
+		Context("when cordoned", func() {
+			BeforeEach(func() {
+				cordonReporter.CordonedReturns(true)
+
+				lrp := rep.NewLRP(
+					models.NewActualLRPKey("process-guid", int32(expectedIndex), "tests"),
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+
+				task := rep.NewTask(
+					"the-task-guid",
+					"tests",
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+
+				work = rep.Work{
+					LRPs:  []rep.LRP{lrp},
+					Tasks: []rep.Task{task},
+				}
+			})
+
+			It("returns all work it was given as failed", func() {
+				failedWork, err := cellRep.Perform(ctx, logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(Equal(work.LRPs))
+				Expect(failedWork.Tasks).To(Equal(work.Tasks))
+				Expect(failedWork.FailureReasons).To(Equal(map[string]string{
+					work.LRPs[0].Identifier():  rep.CellCordonedReason,
+					work.Tasks[0].Identifier(): rep.CellCordonedReason,
+				}))
+			})
+
+			It("does not allocate any containers", func() {
+				_, err := cellRep.Perform(ctx, logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.AllocateContainersCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the caller gives up", func() {
+			var cancel context.CancelFunc
+
+			BeforeEach(func() {
+				ctx, cancel = context.WithCancel(ctx)
+
+				lrp := rep.NewLRP(
+					models.NewActualLRPKey("process-guid", int32(expectedIndex), "tests"),
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+
+				task := rep.NewTask(
+					"the-task-guid",
+					"tests",
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+
+				work = rep.Work{
+					LRPs:  []rep.LRP{lrp},
+					Tasks: []rep.Task{task},
+				}
+			})
+
+			AfterEach(func() {
+				cancel()
+			})
+
+			It("does not allocate anything once the caller has given up", func() {
+				cancel()
+				_, err := cellRep.Perform(ctx, logger, work)
+				Expect(err).To(Equal(context.Canceled))
+				Expect(client.AllocateContainersCallCount()).To(BeZero())
+			})
+
+			It("returns the work it has not allocated yet as failed", func() {
+				client.AllocateContainersStub = func(lager.Logger, []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+					cancel()
+					return nil, nil
+				}
+
+				failedWork, err := cellRep.Perform(ctx, logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(failedWork.Tasks).To(Equal(work.Tasks))
 			})
 		})
 
@@ -328,17 +697,17 @@ var _ = Describe("AuctionCellRep", func() {
 				lrpAuctionOne = rep.NewLRP(
 					models.NewActualLRPKey("process-guid", expectedIndexOne, "tests"),
 					rep.NewResource(2048, 1024, 100),
-					rep.NewPlacementConstraint("rootfs", nil, []string{}),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
 				)
 				lrpAuctionTwo = rep.NewLRP(
 					models.NewActualLRPKey("process-guid", expectedIndexTwo, "tests"),
 					rep.NewResource(2048, 1024, 100),
-					rep.NewPlacementConstraint("rootfs", nil, []string{}),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
 				)
 				lrpAuctionThree = rep.NewLRP(
 					models.NewActualLRPKey("process-guid", expectedIndexThree, "tests"),
 					rep.NewResource(2048, 1024, 100),
-					rep.NewPlacementConstraint("rootfs", nil, []string{}),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
 				)
 			})
 
@@ -349,12 +718,12 @@ var _ = Describe("AuctionCellRep", func() {
 			Context("when all LRP Auctions can be successfully translated to container specs", func() {
 				BeforeEach(func() {
 					lrpAuctionOne.RootFs = linuxRootFSURL
-					lrpAuctionTwo.RootFs = "unsupported-arbitrary://still-goes-through"
+					lrpAuctionTwo.RootFs = "docker:///still-goes-through"
 					lrpAuctionThree.RootFs = fmt.Sprintf("%s:linux?somekey=somevalue", models.PreloadedOCIRootFSScheme)
 				})
 
 				It("makes the correct allocation requests for all LRP Auctions", func() {
-					_, err := cellRep.Perform(logger, rep.Work{
+					_, err := cellRep.Perform(ctx, logger, rep.Work{
 						LRPs: lrpAuctions,
 					})
 					Expect(err).NotTo(HaveOccurred())
@@ -382,7 +751,7 @@ var _ = Describe("AuctionCellRep", func() {
 								rep.InstanceGuidTag: expectedGuidTwo,
 								rep.ProcessIndexTag: expectedIndexTwoString,
 							},
-							Resource: executor.NewResource(int(lrpAuctionTwo.MemoryMB), int(lrpAuctionTwo.DiskMB), int(lrpAuctionTwo.MaxPids), "unsupported-arbitrary://still-goes-through"),
+							Resource: executor.NewResource(int(lrpAuctionTwo.MemoryMB), int(lrpAuctionTwo.DiskMB), int(lrpAuctionTwo.MaxPids), "docker:///still-goes-through"),
 						},
 						executor.AllocationRequest{
 							Guid: rep.LRPContainerGuid(lrpAuctionThree.ProcessGuid, expectedGuidThree),
@@ -404,7 +773,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("does not mark any LRP Auctions as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: lrpAuctions})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: lrpAuctions})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork).To(BeZero())
 					})
@@ -425,7 +794,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("marks the corresponding LRP Auctions as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: lrpAuctions})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: lrpAuctions})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionOne))
 					})
@@ -440,7 +809,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("only makes container allocation requests for the remaining LRP Auctions", func() {
-					_, err := cellRep.Perform(logger, rep.Work{LRPs: lrpAuctions})
+					_, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: lrpAuctions})
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(client.AllocateContainersCallCount()).To(Equal(1))
@@ -461,7 +830,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("marks the LRP Auction as failed", func() {
-					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: lrpAuctions})
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: lrpAuctions})
 					Expect(err).NotTo(HaveOccurred())
 					Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionTwo, lrpAuctionThree))
 				})
@@ -481,7 +850,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("marks the corresponding LRP Auctions as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: lrpAuctions})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: lrpAuctions})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionOne, lrpAuctionTwo, lrpAuctionThree))
 					})
@@ -494,7 +863,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("makes the correct allocation request for it, passing along the blank path to the executor client", func() {
-					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
+					_, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne}})
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(client.AllocateContainersCallCount()).To(Equal(1))
@@ -522,7 +891,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("only makes container allocation requests for the remaining LRP Auctions", func() {
-					_, err := cellRep.Perform(logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
+					_, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrpAuctionOne, lrpAuctionTwo}})
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(client.AllocateContainersCallCount()).To(Equal(1))
@@ -543,7 +912,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("marks the LRP Auction as failed", func() {
-					failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: lrpAuctions})
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: lrpAuctions})
 					Expect(err).NotTo(HaveOccurred())
 					Expect(failedWork.LRPs).To(ContainElement(lrpAuctionTwo))
 				})
@@ -554,7 +923,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("does not mark any additional LRP Auctions as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: lrpAuctions})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: lrpAuctions})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionTwo))
 					})
@@ -575,7 +944,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("marks the corresponding LRP Auctions as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{LRPs: lrpAuctions})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: lrpAuctions})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.LRPs).To(ConsistOf(lrpAuctionOne, lrpAuctionTwo))
 					})
@@ -583,6 +952,374 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 		})
 
+		Context("when an LRP requests a cpu weight", func() {
+			var lrp rep.LRP
+
+			BeforeEach(func() {
+				lrp = rep.NewLRP(
+					models.NewActualLRPKey("process-guid", int32(expectedIndex), "tests"),
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+				lrp.CPUWeight = 50
+			})
+
+			It("records the cpu weight in the container tags", func() {
+				_, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				_, arg := client.AllocateContainersArgsForCall(0)
+				Expect(arg).To(HaveLen(1))
+				Expect(arg[0].Tags).To(HaveKeyWithValue(rep.CPUWeightTag, "50"))
+			})
+
+			Context("when the cell limits pids and cpu weight", func() {
+				BeforeEach(func() {
+					maxPidsCapacity = 1000
+					cpuWeightCapacity = 400
+
+					client.ListContainersReturns([]executor.Container{
+						{Guid: "first", Resource: executor.NewResource(20, 10, 900, "rootfs"), Tags: executor.Tags{rep.CPUWeightTag: "300"}},
+					}, nil)
+				})
+
+				It("allocates work that fits in what is left", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(BeEmpty())
+					Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				})
+
+				It("rejects work that asks for more than is left with a reason", func() {
+					lrp.MaxPids = 200
+					lrp.CPUWeight = 150
+
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(Equal([]rep.LRP{lrp}))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(lrp.Identifier(), "insufficient resources: cpu, pids"))
+					Expect(client.AllocateContainersCallCount()).To(BeZero())
+				})
+
+				It("rejects work that does not limit its pids", func() {
+					lrp.MaxPids = 0
+
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(lrp.Identifier(), "insufficient resources: pids"))
+				})
+
+				It("charges each piece of work against what the previous ones left", func() {
+					otherLRP := lrp
+					otherLRP.Index = lrp.Index + 1
+
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp, otherLRP}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(Equal([]rep.LRP{otherLRP}))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(otherLRP.Identifier(), "insufficient resources: pids"))
+				})
+			})
+		})
+
+		Context("when work requests extended resources", func() {
+			var lrp rep.LRP
+
+			BeforeEach(func() {
+				lrp = rep.NewLRP(
+					models.NewActualLRPKey("process-guid", int32(expectedIndex), "tests"),
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+				lrp.Extended = map[string]int32{"ssd-slots": 1}
+
+				task = rep.NewTask("the-task-guid", "tests", rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}))
+				task.Extended = map[string]int32{"licence-seats": 2}
+
+				extendedResources = map[string]int32{"ssd-slots": 2, "licence-seats": 4}
+			})
+
+			It("records the allocations in the container tags", func() {
+				_, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.AllocateContainersCallCount()).To(Equal(2))
+				_, lrpRequests := client.AllocateContainersArgsForCall(0)
+				Expect(lrpRequests).To(HaveLen(1))
+				Expect(lrpRequests[0].Tags).To(HaveKeyWithValue(rep.ExtendedResourceTagPrefix+"ssd-slots", "1"))
+				_, taskRequests := client.AllocateContainersArgsForCall(1)
+				Expect(taskRequests).To(HaveLen(1))
+				Expect(taskRequests[0].Tags).To(HaveKeyWithValue(rep.ExtendedResourceTagPrefix+"licence-seats", "2"))
+			})
+
+			Context("when the cell has less left than the work asks for", func() {
+				var allocated []executor.Container
+
+				BeforeEach(func() {
+					extendedResources = map[string]int32{"ssd-slots": 2, "licence-seats": 1}
+
+					allocated = []executor.Container{}
+					client.ListContainersStub = func(lager.Logger) ([]executor.Container, error) {
+						return allocated, nil
+					}
+					client.AllocateContainersStub = func(_ lager.Logger, requests []executor.AllocationRequest) ([]executor.AllocationFailure, error) {
+						for _, request := range requests {
+							allocated = append(allocated, executor.Container{Guid: request.Guid, Tags: request.Tags})
+						}
+						return nil, nil
+					}
+				})
+
+				It("rejects the work that does not fit with a reason", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(BeEmpty())
+					Expect(failedWork.Tasks).To(Equal([]rep.Task{task}))
+					Expect(failedWork.FailureReasons).To(Equal(map[string]string{
+						task.Identifier(): "insufficient resources: licence-seats",
+					}))
+
+					Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				})
+
+				It("does not give the last seat to two auctions placing work from the same state", func() {
+					task.Extended = map[string]int32{"licence-seats": 1}
+					otherTask := rep.NewTask("other-task-guid", "tests", rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}))
+					otherTask.Extended = map[string]int32{"licence-seats": 1}
+
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.Tasks).To(BeEmpty())
+
+					failedWork, err = cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{otherTask}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.Tasks).To(Equal([]rep.Task{otherTask}))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(otherTask.Identifier(), "insufficient resources: licence-seats"))
+
+					Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				})
+
+				It("rejects the work if the containers cannot be listed", func() {
+					client.ListContainersStub = nil
+					client.ListContainersReturns(nil, commonErr)
+
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(Equal([]rep.LRP{lrp}))
+					Expect(client.AllocateContainersCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		Context("when overcommit is configured", func() {
+			var lrp rep.LRP
+
+			BeforeEach(func() {
+				resourceCeiling = &rep.Resources{MemoryMB: 1000, DiskMB: 2000}
+
+				lrp = rep.NewLRP(
+					models.NewActualLRPKey("process-guid", int32(expectedIndex), "tests"),
+					rep.NewResource(1000, 2000, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+				task = rep.NewTask(
+					"the-task-guid",
+					"tests",
+					rep.NewResource(1001, 2001, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, nil, []string{}),
+				)
+			})
+
+			It("rejects work larger than the ceiling that an auctioneer placed anyway", func() {
+				failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(failedWork.Tasks).To(Equal([]rep.Task{task}))
+				Expect(failedWork.FailureReasons).To(Equal(map[string]string{
+					task.Identifier(): "insufficient resources: disk, memory",
+				}))
+
+				Expect(client.AllocateContainersCallCount()).To(Equal(1))
+				_, arg := client.AllocateContainersArgsForCall(0)
+				Expect(arg).To(HaveLen(1))
+				Expect(arg[0].Guid).To(Equal(rep.LRPContainerGuid(lrp.ProcessGuid, expectedGuid)))
+			})
+		})
+
+		Describe("validating placement constraints", func() {
+			var lrp rep.LRP
+
+			BeforeEach(func() {
+				placementTags = []string{"required"}
+				optionalPlacementTags = []string{"optional"}
+				client.VolumeDriversReturns([]string{"driver-1"}, nil)
+
+				lrp = rep.NewLRP(
+					models.NewActualLRPKey("process-guid", int32(expectedIndex), "tests"),
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, []string{"required"}, []string{"driver-1"}),
+				)
+				task = rep.NewTask(
+					"the-task-guid",
+					"tests",
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, []string{"required", "optional"}, []string{"driver-1"}),
+				)
+			})
+
+			Context("when the work satisfies the cell's constraints", func() {
+				It("allocates it", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork).To(BeZero())
+					Expect(client.AllocateContainersCallCount()).To(Equal(2))
+				})
+			})
+
+			Context("when the rootfs is not provided by the cell", func() {
+				BeforeEach(func() {
+					lrp.RootFs = "oci://some/image"
+					task.RootFs = models.PreloadedRootFS("windows")
+				})
+
+				It("rejects the work with a reason", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(ConsistOf(lrp))
+					Expect(failedWork.Tasks).To(ConsistOf(task))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(lrp.Identifier(), "rootfs unsupported-scheme: oci://some/image"))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(task.Identifier(), "rootfs not-provided: preloaded:windows"))
+					Expect(client.AllocateContainersCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the rootfs is not allowed by the scheme's patterns", func() {
+				BeforeEach(func() {
+					provider, err := rep.NewPatternRootFSProvider(rep.RootFSPatterns{Hosts: []string{"registry.internal"}}, rep.RootFSPatterns{})
+					Expect(err).NotTo(HaveOccurred())
+					rootFSPatterns = map[string]rep.PatternRootFSProvider{"docker": provider}
+
+					lrp.RootFs = "docker://registry.internal/team/app"
+					task.RootFs = "docker://registry.example.com/team/app"
+				})
+
+				It("allocates the allowed work and rejects the rest with a reason", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(BeEmpty())
+					Expect(failedWork.Tasks).To(ConsistOf(task))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(task.Identifier(), "rootfs not-provided: docker://registry.example.com/team/app"))
+
+					_, lrpRequests := client.AllocateContainersArgsForCall(0)
+					Expect(lrpRequests).To(HaveLen(1))
+				})
+			})
+
+			Context("when a volume driver is missing", func() {
+				BeforeEach(func() {
+					lrp.VolumeDrivers = []string{"driver-1", "driver-2"}
+				})
+
+				It("rejects the work with a reason", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(ConsistOf(lrp))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(lrp.Identifier(), "missing volume drivers: driver-2"))
+				})
+			})
+
+			Context("when no work requires a volume driver", func() {
+				BeforeEach(func() {
+					lrp.VolumeDrivers = nil
+					task.VolumeDrivers = nil
+				})
+
+				It("does not list the volume drivers", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork).To(BeZero())
+					Expect(client.VolumeDriversCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the volume drivers cannot be listed", func() {
+				BeforeEach(func() {
+					client.VolumeDriversReturns(nil, commonErr)
+				})
+
+				It("rejects work that requires a volume driver", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(ConsistOf(lrp))
+				})
+			})
+
+			Context("when the placement tags do not match", func() {
+				BeforeEach(func() {
+					lrp.PlacementTags = []string{}
+					task.PlacementTags = []string{"required", "isolated"}
+				})
+
+				It("rejects the work with a reason", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(ConsistOf(lrp))
+					Expect(failedWork.Tasks).To(ConsistOf(task))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(lrp.Identifier(), "missing placement tags: required"))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(task.Identifier(), "unexpected placement tags: isolated"))
+				})
+			})
+
+			Context("when the placement has been updated", func() {
+				BeforeEach(func() {
+					lrp.RootFs = models.PreloadedRootFS("cflinuxfs4")
+					lrp.PlacementTags = []string{"retagged"}
+				})
+
+				JustBeforeEach(func() {
+					cellRep.UpdatePlacement(rep.StackPathMap{"cflinuxfs4": "/data/cflinuxfs4"}, []string{"retagged"}, nil)
+				})
+
+				It("places work against the new stacks and tags", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(BeEmpty())
+					Expect(failedWork.Tasks).To(ConsistOf(task))
+
+					_, lrpRequests := client.AllocateContainersArgsForCall(0)
+					Expect(lrpRequests).To(HaveLen(1))
+					Expect(lrpRequests[0].RootFSPath).To(Equal("/data/cflinuxfs4"))
+				})
+
+				It("reports the new stacks and tags in the state", func() {
+					state, _, err := cellRep.State(ctx, logger)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(state.PlacementTags).To(Equal([]string{"retagged"}))
+					Expect(state.MatchRootFS(models.PreloadedRootFS("cflinuxfs4"))).To(BeTrue())
+					Expect(state.MatchRootFS(linuxRootFSURL)).To(BeFalse())
+				})
+			})
+
+			Context("when a label selector does not match", func() {
+				BeforeEach(func() {
+					labels = map[string]string{"disk": "ssd"}
+					lrp.LabelSelector = rep.LabelSelector{{Key: "disk", Operator: rep.LabelSelectorIn, Values: []string{"ssd"}}}
+					task.LabelSelector = rep.LabelSelector{{Key: "gpu", Operator: rep.LabelSelectorExists}}
+				})
+
+				It("allocates the matching work and rejects the rest with a reason", func() {
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(BeEmpty())
+					Expect(failedWork.Tasks).To(ConsistOf(task))
+					Expect(failedWork.FailureReasons).To(HaveKeyWithValue(task.Identifier(), "unmatched label selectors: gpu"))
+
+					_, lrpRequests := client.AllocateContainersArgsForCall(0)
+					Expect(lrpRequests).To(HaveLen(1))
+				})
+			})
+		})
+
 		Describe("starting tasks", func() {
 			var task1, task2 rep.Task
 
@@ -601,11 +1338,11 @@ var _ = Describe("AuctionCellRep", func() {
 			Context("when all Tasks can be successfully translated to container specs", func() {
 				BeforeEach(func() {
 					task1.RootFs = linuxRootFSURL
-					task2.RootFs = "unsupported-arbitrary://still-goes-through"
+					task2.RootFs = "docker:///still-goes-through"
 				})
 
 				It("makes the correct allocation requests for all Tasks", func() {
-					_, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+					_, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(client.AllocateContainersCallCount()).To(Equal(1))
@@ -622,7 +1359,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("does not mark any Tasks as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork).To(BeZero())
 					})
@@ -642,7 +1379,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("marks the corresponding Tasks as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.Tasks).To(ConsistOf(task1))
 					})
@@ -656,7 +1393,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("only makes container allocation requests for the remaining Tasks", func() {
-					_, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+					_, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(client.AllocateContainersCallCount()).To(Equal(1))
@@ -667,7 +1404,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("marks the Task as failed", func() {
-					failedWork, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 					Expect(err).NotTo(HaveOccurred())
 					Expect(failedWork.Tasks).To(ContainElement(task2))
 				})
@@ -678,7 +1415,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("does not mark any additional Tasks as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.Tasks).To(ConsistOf(task2))
 					})
@@ -692,7 +1429,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("marks the corresponding Tasks as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.Tasks).To(ConsistOf(task1, task2))
 					})
@@ -705,7 +1442,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("makes the correct allocation request for it, passing along the blank path to the executor client", func() {
-					_, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1}})
+					_, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1}})
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(client.AllocateContainersCallCount()).To(Equal(1))
@@ -723,7 +1460,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("only makes container allocation requests for the remaining Tasks", func() {
-					_, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+					_, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(client.AllocateContainersCallCount()).To(Equal(1))
@@ -734,7 +1471,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("marks the Task as failed", func() {
-					failedWork, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+					failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 					Expect(err).NotTo(HaveOccurred())
 					Expect(failedWork.Tasks).To(ContainElement(task2))
 				})
@@ -745,7 +1482,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("does not mark any additional LRP Auctions as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.Tasks).To(ConsistOf(task2))
 					})
@@ -759,7 +1496,7 @@ var _ = Describe("AuctionCellRep", func() {
 					})
 
 					It("marks the corresponding Tasks as failed", func() {
-						failedWork, err := cellRep.Perform(logger, rep.Work{Tasks: []rep.Task{task1, task2}})
+						failedWork, err := cellRep.Perform(ctx, logger, rep.Work{Tasks: []rep.Task{task1, task2}})
 						Expect(err).NotTo(HaveOccurred())
 						Expect(failedWork.Tasks).To(ConsistOf(task1, task2))
 					})
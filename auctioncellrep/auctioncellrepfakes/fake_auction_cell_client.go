@@ -2,6 +2,7 @@
 package auctioncellrepfakes
 
 import (
+	"context"
 	"sync"
 
 	"code.cloudfoundry.org/lager"
@@ -10,9 +11,10 @@ import (
 )
 
 type FakeAuctionCellClient struct {
-	StateStub        func(logger lager.Logger) (rep.CellState, bool, error)
+	StateStub        func(ctx context.Context, logger lager.Logger) (rep.CellState, bool, error)
 	stateMutex       sync.RWMutex
 	stateArgsForCall []struct {
+		ctx    context.Context
 		logger lager.Logger
 	}
 	stateReturns struct {
@@ -25,9 +27,10 @@ type FakeAuctionCellClient struct {
 		result2 bool
 		result3 error
 	}
-	PerformStub        func(logger lager.Logger, work rep.Work) (rep.Work, error)
+	PerformStub        func(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error)
 	performMutex       sync.RWMutex
 	performArgsForCall []struct {
+		ctx    context.Context
 		logger lager.Logger
 		work   rep.Work
 	}
@@ -52,16 +55,17 @@ type FakeAuctionCellClient struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeAuctionCellClient) State(logger lager.Logger) (rep.CellState, bool, error) {
+func (fake *FakeAuctionCellClient) State(ctx context.Context, logger lager.Logger) (rep.CellState, bool, error) {
 	fake.stateMutex.Lock()
 	ret, specificReturn := fake.stateReturnsOnCall[len(fake.stateArgsForCall)]
 	fake.stateArgsForCall = append(fake.stateArgsForCall, struct {
+		ctx    context.Context
 		logger lager.Logger
-	}{logger})
-	fake.recordInvocation("State", []interface{}{logger})
+	}{ctx, logger})
+	fake.recordInvocation("State", []interface{}{ctx, logger})
 	fake.stateMutex.Unlock()
 	if fake.StateStub != nil {
-		return fake.StateStub(logger)
+		return fake.StateStub(ctx, logger)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2, ret.result3
@@ -75,10 +79,10 @@ func (fake *FakeAuctionCellClient) StateCallCount() int {
 	return len(fake.stateArgsForCall)
 }
 
-func (fake *FakeAuctionCellClient) StateArgsForCall(i int) lager.Logger {
+func (fake *FakeAuctionCellClient) StateArgsForCall(i int) (context.Context, lager.Logger) {
 	fake.stateMutex.RLock()
 	defer fake.stateMutex.RUnlock()
-	return fake.stateArgsForCall[i].logger
+	return fake.stateArgsForCall[i].ctx, fake.stateArgsForCall[i].logger
 }
 
 func (fake *FakeAuctionCellClient) StateReturns(result1 rep.CellState, result2 bool, result3 error) {
@@ -106,17 +110,18 @@ func (fake *FakeAuctionCellClient) StateReturnsOnCall(i int, result1 rep.CellSta
 	}{result1, result2, result3}
 }
 
-func (fake *FakeAuctionCellClient) Perform(logger lager.Logger, work rep.Work) (rep.Work, error) {
+func (fake *FakeAuctionCellClient) Perform(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error) {
 	fake.performMutex.Lock()
 	ret, specificReturn := fake.performReturnsOnCall[len(fake.performArgsForCall)]
 	fake.performArgsForCall = append(fake.performArgsForCall, struct {
+		ctx    context.Context
 		logger lager.Logger
 		work   rep.Work
-	}{logger, work})
-	fake.recordInvocation("Perform", []interface{}{logger, work})
+	}{ctx, logger, work})
+	fake.recordInvocation("Perform", []interface{}{ctx, logger, work})
 	fake.performMutex.Unlock()
 	if fake.PerformStub != nil {
-		return fake.PerformStub(logger, work)
+		return fake.PerformStub(ctx, logger, work)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -130,10 +135,10 @@ func (fake *FakeAuctionCellClient) PerformCallCount() int {
 	return len(fake.performArgsForCall)
 }
 
-func (fake *FakeAuctionCellClient) PerformArgsForCall(i int) (lager.Logger, rep.Work) {
+func (fake *FakeAuctionCellClient) PerformArgsForCall(i int) (context.Context, lager.Logger, rep.Work) {
 	fake.performMutex.RLock()
 	defer fake.performMutex.RUnlock()
-	return fake.performArgsForCall[i].logger, fake.performArgsForCall[i].work
+	return fake.performArgsForCall[i].ctx, fake.performArgsForCall[i].logger, fake.performArgsForCall[i].work
 }
 
 func (fake *FakeAuctionCellClient) PerformReturns(result1 rep.Work, result2 error) {
@@ -0,0 +1,125 @@
+package auctioncellrep
+
+import (
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// budget is what the cell has left of the resources that the executor does
+// not account for itself. Auctions place work from cell states that may be
+// stale, so Perform charges the work against the budget before allocating it
+// rather than trusting the auctioneer's arithmetic.
+type budget struct {
+	limitsPids      bool
+	limitsCPUWeight bool
+
+	pids      int32
+	cpuWeight int32
+	extended  map[string]int32
+}
+
+// requiresBudget reports whether the work has to be charged against the
+// budget: the cell limits pids or cpu weight, or the work asks for extended
+// resources.
+func (a *AuctionCellRep) requiresBudget(work rep.Work) bool {
+	if len(work.LRPs)+len(work.Tasks) == 0 {
+		return false
+	}
+	if a.maxPidsCapacity > 0 || a.cpuWeightCapacity > 0 {
+		return true
+	}
+	for i := range work.LRPs {
+		if len(work.LRPs[i].Extended) > 0 {
+			return true
+		}
+	}
+	for i := range work.Tasks {
+		if len(work.Tasks[i].Extended) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// remainingBudget works out the budget from the containers on the cell. If
+// they cannot be listed, nothing is left, and the work is rejected.
+func (a *AuctionCellRep) remainingBudget(logger lager.Logger) *budget {
+	containers, err := a.client.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-fetch-containers", err)
+		return &budget{
+			limitsPids:      true,
+			limitsCPUWeight: true,
+			extended:        map[string]int32{},
+		}
+	}
+	return a.budget(containers)
+}
+
+// budget returns what is left of the cell's capacities once the given
+// containers are deducted.
+func (a *AuctionCellRep) budget(containers []executor.Container) *budget {
+	b := &budget{
+		limitsPids:      a.maxPidsCapacity > 0,
+		limitsCPUWeight: a.cpuWeightCapacity > 0,
+		pids:            a.maxPidsCapacity,
+		cpuWeight:       a.cpuWeightCapacity,
+		extended:        map[string]int32{},
+	}
+	for name, capacity := range a.extendedResources {
+		b.extended[name] = capacity
+	}
+	for i := range containers {
+		b.deduct(&containers[i])
+	}
+	return b
+}
+
+// deduct takes the resources held by a container from the budget.
+func (b *budget) deduct(container *executor.Container) {
+	b.pids -= int32(container.MaxPids)
+	b.cpuWeight -= containerCPUWeight(container)
+	for name, amount := range rep.ExtendedResourcesFromTags(container.Tags) {
+		if _, ok := b.extended[name]; ok {
+			b.extended[name] -= amount
+		}
+	}
+}
+
+// charge takes the resource from the budget, or returns the reason it does
+// not fit in what is left. Once the cell limits pids, work that does not limit
+// its own pids never fits, since it could use up the whole budget. A nil
+// budget has room for anything.
+func (b *budget) charge(resource *rep.Resource) string {
+	if b == nil {
+		return ""
+	}
+
+	short := []string{}
+	if b.limitsCPUWeight && resource.CPUWeight > b.cpuWeight {
+		short = append(short, "cpu")
+	}
+	if b.limitsPids && (resource.MaxPids == 0 || resource.MaxPids > b.pids) {
+		short = append(short, "pids")
+	}
+	for name, amount := range resource.Extended {
+		if amount > b.extended[name] {
+			short = append(short, name)
+		}
+	}
+	if len(short) > 0 {
+		sort.Strings(short)
+		return "insufficient resources: " + strings.Join(short, ", ")
+	}
+
+	b.pids -= resource.MaxPids
+	b.cpuWeight -= resource.CPUWeight
+	for name, amount := range resource.Extended {
+		b.extended[name] -= amount
+	}
+	return ""
+}
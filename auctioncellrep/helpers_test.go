@@ -1,6 +1,7 @@
 package auctioncellrep_test
 
 import (
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -14,4 +15,43 @@ var _ = Describe("Helpers", func() {
 			Expect(guid).To(HaveLen(28))
 		})
 	})
+
+	Context("PathForRootFS", func() {
+		var stackPathMap rep.StackPathMap
+
+		BeforeEach(func() {
+			stackPathMap = rep.StackPathMap{
+				"cflinuxfs3@1.179": "/path/1.179",
+				"cflinuxfs3@1.181": "/path/1.181",
+				"windows":          "/path/windows",
+			}
+		})
+
+		It("returns the path of an unversioned stack", func() {
+			path, err := auctioncellrep.PathForRootFS("preloaded:windows", stackPathMap)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("/path/windows"))
+		})
+
+		It("returns the path of the best version matching the constraint", func() {
+			path, err := auctioncellrep.PathForRootFS("preloaded:cflinuxfs3?version=<1.180", stackPathMap)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("/path/1.179"))
+
+			path, err = auctioncellrep.PathForRootFS("preloaded:cflinuxfs3", stackPathMap)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("/path/1.181"))
+		})
+
+		It("removes the version from a layered rootfs", func() {
+			path, err := auctioncellrep.PathForRootFS("preloaded+layer:cflinuxfs3?layer=https://example.com/layer.tgz&version=>=1.180", stackPathMap)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("preloaded+layer:/path/1.181?layer=https%3A%2F%2Fexample.com%2Flayer.tgz"))
+		})
+
+		It("returns an error when no version matches", func() {
+			_, err := auctioncellrep.PathForRootFS("preloaded:cflinuxfs3?version=>=2", stackPathMap)
+			Expect(err).To(Equal(auctioncellrep.ErrPreloadedRootFSNotFound))
+		})
+	})
 })
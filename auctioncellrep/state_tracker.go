@@ -0,0 +1,342 @@
+package auctioncellrep
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// DefaultStateRecheckInterval is how often a long-polling request rebuilds
+// the state when the tracker does not cache it.
+const DefaultStateRecheckInterval = time.Second
+
+const resubscribeInterval = time.Second
+
+// usageBuckets is the number of steps the usage of each resource is rounded
+// to before it is fingerprinted, so that the usage measured anew on every
+// build only makes a new version when it changes noticeably.
+const usageBuckets = 20
+
+// VersionedStateClient is implemented by cells that number their states, so
+// that callers can skip fetching a state they have already seen.
+type VersionedStateClient interface {
+	VersionedState(ctx context.Context, logger lager.Logger) (rep.CellState, rep.StateVersion, bool, error)
+	WaitForStateChange(ctx context.Context, logger lager.Logger, version rep.StateVersion, wait time.Duration) (rep.CellState, rep.StateVersion, bool, error)
+}
+
+// StateTracker caches the state of a cell and gives each distinct state a
+// new version. The cached state is rebuilt once it is older than maxAge, or
+// as soon as a container event, an auction or a placement change may have
+// altered it. A zero maxAge rebuilds the state on every call. Calls made
+// while the state is being built wait for that build rather than starting
+// another, unless the state was invalidated since it started.
+type StateTracker struct {
+	AuctionCellClient
+	executorClient executor.Client
+	clock          clock.Clock
+	maxAge         time.Duration
+	logger         lager.Logger
+	epoch          int64
+
+	lock          sync.Mutex
+	number        uint64
+	fingerprint   [sha256.Size]byte
+	state         rep.CellState
+	healthy       bool
+	builtAt       time.Time
+	stale         bool
+	invalidations uint64
+	wake          chan struct{}
+	build         *stateBuild
+}
+
+// stateBuild is a build of the state under way, and its outcome once done is
+// closed.
+type stateBuild struct {
+	done          chan struct{}
+	invalidations uint64
+
+	state   rep.CellState
+	version rep.StateVersion
+	healthy bool
+	err     error
+}
+
+func NewStateTracker(
+	logger lager.Logger,
+	cell AuctionCellClient,
+	executorClient executor.Client,
+	clock clock.Clock,
+	maxAge time.Duration,
+) *StateTracker {
+	return &StateTracker{
+		AuctionCellClient: cell,
+		executorClient:    executorClient,
+		clock:             clock,
+		maxAge:            maxAge,
+		logger:            logger.Session("state-tracker"),
+		epoch:             clock.Now().UnixNano(),
+		stale:             true,
+		wake:              make(chan struct{}),
+	}
+}
+
+func (t *StateTracker) State(ctx context.Context, logger lager.Logger) (rep.CellState, bool, error) {
+	state, _, healthy, err := t.VersionedState(ctx, logger)
+	return state, healthy, err
+}
+
+func (t *StateTracker) VersionedState(ctx context.Context, logger lager.Logger) (rep.CellState, rep.StateVersion, bool, error) {
+	state, version, healthy, _, err := t.versionedState(ctx, logger, t.maxAge)
+	return state, version, healthy, err
+}
+
+// WaitForStateChange returns as soon as the state of the cell no longer has
+// the given version, or with the unchanged state once wait has passed or ctx
+// is done. The state is built with ctx, so the end of the wait does not
+// abandon a build that is under way.
+//
+// Waiting callers recheck a state that has not been invalidated at most once
+// per recheck interval between them, however many of them there are.
+func (t *StateTracker) WaitForStateChange(ctx context.Context, logger lager.Logger, version rep.StateVersion, wait time.Duration) (rep.CellState, rep.StateVersion, bool, error) {
+	recheckInterval := t.maxAge
+	if recheckInterval <= 0 {
+		recheckInterval = DefaultStateRecheckInterval
+	}
+
+	deadline := t.clock.NewTimer(wait)
+	defer deadline.Stop()
+
+	for {
+		state, current, healthy, wake, err := t.versionedState(ctx, logger, recheckInterval)
+		if err != nil || current != version {
+			return state, current, healthy, err
+		}
+
+		timer := t.clock.NewTimer(recheckInterval)
+		select {
+		case <-wake:
+		case <-timer.C():
+		case <-deadline.C():
+			timer.Stop()
+			return state, current, healthy, nil
+		case <-ctx.Done():
+			timer.Stop()
+			return state, current, healthy, nil
+		}
+		timer.Stop()
+	}
+}
+
+// versionedState serves the cached state unless it is stale or older than
+// maxAge, and also returns a channel that is closed the next time the state
+// is invalidated or changes.
+//
+// The state is built without holding the lock, so that a slow executor does
+// not hold up other requests or invalidations. A state that was invalidated
+// while it was being built is cached, but stays stale.
+func (t *StateTracker) versionedState(ctx context.Context, logger lager.Logger, maxAge time.Duration) (rep.CellState, rep.StateVersion, bool, <-chan struct{}, error) {
+	for {
+		t.lock.Lock()
+		wake := t.wake
+		if !t.stale && maxAge > 0 && t.clock.Since(t.builtAt) < maxAge {
+			state, version, healthy := t.state, t.version(), t.healthy
+			t.lock.Unlock()
+			return state, version, healthy, wake, nil
+		}
+
+		b := t.build
+		if b == nil || b.invalidations != t.invalidations {
+			b = &stateBuild{done: make(chan struct{}), invalidations: t.invalidations}
+			t.build = b
+			t.lock.Unlock()
+
+			t.buildState(ctx, logger, b)
+			return b.state, b.version, b.healthy, wake, b.err
+		}
+		t.lock.Unlock()
+
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return rep.CellState{}, rep.StateVersion{}, false, wake, ctx.Err()
+		}
+
+		// the caller that started the build gave up on it, this one has not
+		if (b.err == context.Canceled || b.err == context.DeadlineExceeded) && ctx.Err() == nil {
+			continue
+		}
+		return b.state, b.version, b.healthy, wake, b.err
+	}
+}
+
+// buildState builds the state with the context of the caller that started
+// the build, caches it, and records the outcome in b.
+func (t *StateTracker) buildState(ctx context.Context, logger lager.Logger, b *stateBuild) {
+	defer close(b.done)
+
+	builtAt := t.clock.Now()
+	state, healthy, err := t.AuctionCellClient.State(ctx, logger)
+	var fingerprint [sha256.Size]byte
+	if err == nil {
+		fingerprint, err = stateFingerprint(state, healthy)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.build == b {
+		t.build = nil
+	}
+	if err != nil {
+		b.err = err
+		return
+	}
+
+	// a state that was started later has been cached in the meantime
+	if !builtAt.Before(t.builtAt) {
+		if t.number == 0 || fingerprint != t.fingerprint {
+			t.number++
+			t.fingerprint = fingerprint
+			t.notify()
+		}
+		t.state = state
+		t.healthy = healthy
+		t.builtAt = builtAt
+		t.stale = t.invalidations != b.invalidations
+	}
+
+	b.state, b.version, b.healthy = t.state, t.version(), t.healthy
+}
+
+func (t *StateTracker) version() rep.StateVersion {
+	return rep.StateVersion{Epoch: t.epoch, Number: t.number}
+}
+
+// notify wakes everyone waiting for a change. It must be called with the
+// lock held.
+func (t *StateTracker) notify() {
+	close(t.wake)
+	t.wake = make(chan struct{})
+}
+
+// stateFingerprint ignores the order of the containers, which the executor
+// does not keep, and small changes in the usage of the cell, which is
+// measured anew on every build.
+func stateFingerprint(state rep.CellState, healthy bool) ([sha256.Size]byte, error) {
+	state.LRPs = append([]rep.LRP(nil), state.LRPs...)
+	sort.Slice(state.LRPs, func(i, j int) bool {
+		return state.LRPs[i].Identifier() < state.LRPs[j].Identifier()
+	})
+	state.Tasks = append([]rep.Task(nil), state.Tasks...)
+	sort.Slice(state.Tasks, func(i, j int) bool {
+		return state.Tasks[i].TaskGuid < state.Tasks[j].TaskGuid
+	})
+	state.Usage = bucketedUsage(state.Usage, &state.TotalResources)
+
+	payload, err := json.Marshal(struct {
+		State   rep.CellState
+		Healthy bool
+	}{state, healthy})
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(payload), nil
+}
+
+// bucketedUsage rounds the usage of each resource to a fraction of its total.
+func bucketedUsage(usage *rep.Usage, total *rep.Resources) *rep.Usage {
+	if usage == nil {
+		return nil
+	}
+	return &rep.Usage{
+		MemoryMB: bucket(usage.MemoryMB, total.MemoryMB),
+		DiskMB:   bucket(usage.DiskMB, total.DiskMB),
+		CPU:      math.Round(usage.CPU*usageBuckets) / usageBuckets,
+	}
+}
+
+func bucket(used, total int32) int32 {
+	if total <= 0 {
+		return used
+	}
+	return int32(math.Round(float64(used) * usageBuckets / float64(total)))
+}
+
+// Invalidate makes the next call rebuild the state.
+func (t *StateTracker) Invalidate() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.stale = true
+	t.invalidations++
+	t.notify()
+}
+
+func (t *StateTracker) Perform(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error) {
+	defer t.Invalidate()
+	return t.AuctionCellClient.Perform(ctx, logger, work)
+}
+
+func (t *StateTracker) Reset() error {
+	defer t.Invalidate()
+	return t.AuctionCellClient.Reset()
+}
+
+// UpdatePlacement only invalidates the state; the cell itself has to be
+// updated as well.
+func (t *StateTracker) UpdatePlacement(preloadedStackPathMap rep.StackPathMap, placementTags, optionalPlacementTags []string) {
+	t.Invalidate()
+}
+
+// Run invalidates the state whenever the executor reports a container event.
+func (t *StateTracker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := t.logger.Session("run")
+	close(ready)
+
+	for {
+		events, err := t.executorClient.SubscribeToEvents(logger)
+		if err != nil {
+			logger.Error("failed-subscribing", err)
+			timer := t.clock.NewTimer(resubscribeInterval)
+			select {
+			case <-signals:
+				timer.Stop()
+				return nil
+			case <-timer.C():
+				continue
+			}
+		}
+
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, err := events.Next(); err != nil {
+					return
+				}
+				t.Invalidate()
+			}
+		}()
+
+		select {
+		case <-signals:
+			events.Close()
+			return nil
+		case <-closed:
+			logger.Info("event-stream-closed")
+			events.Close()
+			t.Invalidate()
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package auctioncellrep_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
+	efakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StateTracker", func() {
+	var (
+		cell           *auctioncellrepfakes.FakeAuctionCellClient
+		executorClient *efakes.FakeClient
+		clock          *fakeclock.FakeClock
+		logger         *lagertest.TestLogger
+		maxAge         time.Duration
+		tracker        *auctioncellrep.StateTracker
+	)
+
+	BeforeEach(func() {
+		cell = new(auctioncellrepfakes.FakeAuctionCellClient)
+		cell.StateReturns(rep.CellState{Zone: "z1"}, true, nil)
+		executorClient = new(efakes.FakeClient)
+		clock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("test")
+		maxAge = 0
+	})
+
+	JustBeforeEach(func() {
+		tracker = auctioncellrep.NewStateTracker(logger, cell, executorClient, clock, maxAge)
+	})
+
+	versionedState := func() (rep.CellState, rep.StateVersion) {
+		state, version, healthy, err := tracker.VersionedState(context.Background(), logger)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		ExpectWithOffset(1, healthy).To(BeTrue())
+		return state, version
+	}
+
+	It("keeps the version while the state is unchanged", func() {
+		_, first := versionedState()
+		_, second := versionedState()
+		Expect(second).To(Equal(first))
+		Expect(cell.StateCallCount()).To(Equal(2))
+	})
+
+	It("bumps the version when the state changes", func() {
+		_, first := versionedState()
+		cell.StateReturns(rep.CellState{Zone: "z2"}, true, nil)
+
+		state, second := versionedState()
+		Expect(state.Zone).To(Equal("z2"))
+		Expect(second.Epoch).To(Equal(first.Epoch))
+		Expect(second.Number).To(BeNumerically(">", first.Number))
+	})
+
+	It("bumps the version when the health of the cell changes", func() {
+		_, first := versionedState()
+		cell.StateReturns(rep.CellState{Zone: "z1"}, false, nil)
+
+		_, second, healthy, err := tracker.VersionedState(context.Background(), logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(healthy).To(BeFalse())
+		Expect(second).NotTo(Equal(first))
+	})
+
+	It("keeps the version when only the order of the containers changes", func() {
+		lrp := func(guid string) rep.LRP {
+			return rep.NewLRP(models.NewActualLRPKey(guid, 0, "domain"), rep.NewResource(10, 10, 10), rep.PlacementConstraint{})
+		}
+		task := func(guid string) rep.Task {
+			return rep.NewTask(guid, "domain", rep.NewResource(10, 10, 10), rep.PlacementConstraint{})
+		}
+
+		cell.StateReturns(rep.CellState{LRPs: []rep.LRP{lrp("a"), lrp("b")}, Tasks: []rep.Task{task("c"), task("d")}}, true, nil)
+		_, first := versionedState()
+		cell.StateReturns(rep.CellState{LRPs: []rep.LRP{lrp("b"), lrp("a")}, Tasks: []rep.Task{task("d"), task("c")}}, true, nil)
+
+		state, second := versionedState()
+		Expect(second).To(Equal(first))
+		Expect(state.LRPs[0].ProcessGuid).To(Equal("b"))
+	})
+
+	It("keeps the version when the usage of the cell changes only slightly", func() {
+		total := rep.NewResources(1000, 1000, 10)
+		cell.StateReturns(rep.CellState{Zone: "z1", TotalResources: total, Usage: &rep.Usage{MemoryMB: 10, CPU: 0.1}}, true, nil)
+		_, first := versionedState()
+		cell.StateReturns(rep.CellState{Zone: "z1", TotalResources: total, Usage: &rep.Usage{MemoryMB: 20, CPU: 0.11}}, true, nil)
+
+		state, second := versionedState()
+		Expect(second).To(Equal(first))
+		Expect(state.Usage.MemoryMB).To(BeEquivalentTo(20))
+	})
+
+	It("bumps the version when the usage of the cell changes noticeably", func() {
+		total := rep.NewResources(1000, 1000, 10)
+		cell.StateReturns(rep.CellState{Zone: "z1", TotalResources: total, Usage: &rep.Usage{MemoryMB: 10}}, true, nil)
+		_, first := versionedState()
+		cell.StateReturns(rep.CellState{Zone: "z1", TotalResources: total, Usage: &rep.Usage{MemoryMB: 500}}, true, nil)
+
+		_, second := versionedState()
+		Expect(second.Number).To(BeNumerically(">", first.Number))
+	})
+
+	Context("while the state is being built", func() {
+		var release chan struct{}
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+			cell.StateStub = func(context.Context, lager.Logger) (rep.CellState, bool, error) {
+				<-release
+				return rep.CellState{Zone: "z1"}, true, nil
+			}
+		})
+
+		It("shares the build with the calls made in the meantime", func() {
+			done := make(chan rep.StateVersion, 2)
+			for i := 0; i < 2; i++ {
+				go func() {
+					defer GinkgoRecover()
+					_, version := versionedState()
+					done <- version
+				}()
+			}
+
+			Eventually(cell.StateCallCount).Should(Equal(1))
+			Consistently(cell.StateCallCount).Should(Equal(1))
+			close(release)
+
+			var first, second rep.StateVersion
+			Eventually(done).Should(Receive(&first))
+			Eventually(done).Should(Receive(&second))
+			Expect(second).To(Equal(first))
+			Expect(cell.StateCallCount()).To(Equal(1))
+		})
+
+		It("builds the state again for calls made after it was invalidated", func() {
+			go func() {
+				defer GinkgoRecover()
+				versionedState()
+			}()
+			Eventually(cell.StateCallCount).Should(Equal(1))
+
+			tracker.Invalidate()
+			go func() {
+				defer GinkgoRecover()
+				versionedState()
+			}()
+			Eventually(cell.StateCallCount).Should(Equal(2))
+			close(release)
+		})
+	})
+
+	It("returns the error of the cell", func() {
+		cell.StateReturns(rep.CellState{}, false, errors.New("boom"))
+		_, _, _, err := tracker.VersionedState(context.Background(), logger)
+		Expect(err).To(MatchError("boom"))
+	})
+
+	Context("with a max age", func() {
+		BeforeEach(func() {
+			maxAge = time.Second
+		})
+
+		It("serves the cached state until it expires", func() {
+			versionedState()
+			versionedState()
+			Expect(cell.StateCallCount()).To(Equal(1))
+
+			clock.Increment(time.Second)
+			versionedState()
+			Expect(cell.StateCallCount()).To(Equal(2))
+		})
+
+		It("rebuilds the state after an auction", func() {
+			versionedState()
+			_, err := tracker.Perform(context.Background(), logger, rep.Work{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cell.PerformCallCount()).To(Equal(1))
+
+			versionedState()
+			Expect(cell.StateCallCount()).To(Equal(2))
+		})
+
+		Context("while the state is being built", func() {
+			var (
+				release chan struct{}
+				built   chan struct{}
+			)
+
+			BeforeEach(func() {
+				release = make(chan struct{})
+				built = make(chan struct{})
+				cell.StateStub = func(context.Context, lager.Logger) (rep.CellState, bool, error) {
+					<-release
+					return rep.CellState{Zone: "z1"}, true, nil
+				}
+			})
+
+			JustBeforeEach(func() {
+				go func() {
+					defer GinkgoRecover()
+					defer close(built)
+					versionedState()
+				}()
+				Eventually(cell.StateCallCount).Should(Equal(1))
+			})
+
+			AfterEach(func() {
+				close(release)
+				Eventually(built).Should(BeClosed())
+			})
+
+			It("does not hold up invalidations", func() {
+				invalidated := make(chan struct{})
+				go func() {
+					tracker.Invalidate()
+					close(invalidated)
+				}()
+				Eventually(invalidated).Should(BeClosed())
+			})
+
+			It("rebuilds the state again when it was invalidated during the build", func() {
+				tracker.Invalidate()
+				release <- struct{}{}
+				Eventually(built).Should(BeClosed())
+
+				go func() {
+					defer GinkgoRecover()
+					versionedState()
+				}()
+				Eventually(cell.StateCallCount).Should(Equal(2))
+			})
+		})
+
+		It("rebuilds the state after the placement changes", func() {
+			versionedState()
+			tracker.UpdatePlacement(rep.StackPathMap{}, nil, nil)
+
+			versionedState()
+			Expect(cell.StateCallCount()).To(Equal(2))
+		})
+	})
+
+	Describe("WaitForStateChange", func() {
+		It("returns straight away when the version is out of date", func() {
+			_, current := versionedState()
+			stale := rep.StateVersion{Epoch: current.Epoch, Number: current.Number - 1}
+
+			_, version, _, err := tracker.WaitForStateChange(context.Background(), logger, stale, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(current))
+		})
+
+		It("returns as soon as the state changes", func() {
+			_, current := versionedState()
+
+			type result struct {
+				state   rep.CellState
+				version rep.StateVersion
+			}
+			results := make(chan result)
+			go func() {
+				defer GinkgoRecover()
+				state, version, _, err := tracker.WaitForStateChange(context.Background(), logger, current, time.Minute)
+				Expect(err).NotTo(HaveOccurred())
+				results <- result{state, version}
+			}()
+
+			Consistently(results).ShouldNot(Receive())
+			cell.StateReturns(rep.CellState{Zone: "z2"}, true, nil)
+			tracker.Invalidate()
+
+			var r result
+			Eventually(results).Should(Receive(&r))
+			Expect(r.state.Zone).To(Equal("z2"))
+			Expect(r.version).NotTo(Equal(current))
+		})
+
+		It("rechecks the state periodically", func() {
+			_, current := versionedState()
+
+			done := make(chan rep.StateVersion)
+			go func() {
+				defer GinkgoRecover()
+				_, version, _, err := tracker.WaitForStateChange(context.Background(), logger, current, time.Minute)
+				Expect(err).NotTo(HaveOccurred())
+				done <- version
+			}()
+
+			Eventually(clock.WatcherCount).Should(Equal(2))
+			cell.StateReturns(rep.CellState{Zone: "z2"}, true, nil)
+			clock.Increment(auctioncellrep.DefaultStateRecheckInterval)
+
+			Eventually(done).Should(Receive(Not(Equal(current))))
+		})
+
+		It("returns the unchanged state once the wait has passed", func() {
+			_, current := versionedState()
+
+			done := make(chan rep.StateVersion)
+			go func() {
+				defer GinkgoRecover()
+				_, version, _, err := tracker.WaitForStateChange(context.Background(), logger, current, 500*time.Millisecond)
+				Expect(err).NotTo(HaveOccurred())
+				done <- version
+			}()
+
+			Eventually(clock.WatcherCount).Should(Equal(2))
+			clock.Increment(500 * time.Millisecond)
+
+			Eventually(done).Should(Receive(Equal(current)))
+		})
+
+		It("returns the unchanged state once the context is done", func() {
+			_, current := versionedState()
+			ctx, cancel := context.WithCancel(context.Background())
+
+			done := make(chan rep.StateVersion)
+			go func() {
+				defer GinkgoRecover()
+				_, version, _, err := tracker.WaitForStateChange(ctx, logger, current, time.Minute)
+				Expect(err).NotTo(HaveOccurred())
+				done <- version
+			}()
+
+			Eventually(clock.WatcherCount).Should(Equal(2))
+			cancel()
+
+			Eventually(done).Should(Receive(Equal(current)))
+		})
+
+		It("does not rebuild the state for each waiting call", func() {
+			_, current := versionedState()
+
+			for i := 0; i < 3; i++ {
+				go func() {
+					defer GinkgoRecover()
+					tracker.WaitForStateChange(context.Background(), logger, current, time.Minute)
+				}()
+			}
+
+			Eventually(clock.WatcherCount).Should(Equal(6))
+			Expect(cell.StateCallCount()).To(Equal(1))
+
+			clock.Increment(auctioncellrep.DefaultStateRecheckInterval)
+			Eventually(clock.WatcherCount).Should(Equal(6))
+			Expect(cell.StateCallCount()).To(Equal(2))
+		})
+
+		It("builds the state with the context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, _, _, err := tracker.WaitForStateChange(ctx, logger, rep.StateVersion{}, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cell.StateCallCount()).To(Equal(1))
+			stateCtx, _ := cell.StateArgsForCall(0)
+			Expect(stateCtx).To(Equal(ctx))
+		})
+	})
+
+	Describe("Run", func() {
+		var (
+			events  chan executor.Event
+			process ifrit.Process
+		)
+
+		BeforeEach(func() {
+			maxAge = time.Minute
+			events = make(chan executor.Event, 1)
+
+			source := new(efakes.FakeEventSource)
+			source.NextStub = func() (executor.Event, error) {
+				event, ok := <-events
+				if !ok {
+					return nil, errors.New("closed")
+				}
+				return event, nil
+			}
+			executorClient.SubscribeToEventsReturns(source, nil)
+		})
+
+		JustBeforeEach(func() {
+			process = ifrit.Invoke(tracker)
+		})
+
+		AfterEach(func() {
+			process.Signal(ifrit.Interrupt)
+			Eventually(process.Wait()).Should(Receive())
+			close(events)
+		})
+
+		It("rebuilds the state after a container event", func() {
+			versionedState()
+			Expect(cell.StateCallCount()).To(Equal(1))
+
+			events <- executor.NewContainerCompleteEvent(executor.Container{Guid: "some-guid"})
+
+			Eventually(func() int {
+				versionedState()
+				return cell.StateCallCount()
+			}).Should(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package auctioncellrep
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// UsageSampler aggregates the measured usage of the containers on a cell.
+// CPU usage is derived from the cpu time consumed since the previous sample,
+// so the first sample always reports zero cpu.
+type UsageSampler struct {
+	clock clock.Clock
+	cpus  int
+
+	lock      sync.Mutex
+	sampledAt time.Time
+	cpuTimes  map[string]time.Duration
+}
+
+func NewUsageSampler(clock clock.Clock, cpus int) *UsageSampler {
+	return &UsageSampler{
+		clock: clock,
+		cpus:  cpus,
+	}
+}
+
+func (s *UsageSampler) Sample(logger lager.Logger, client executor.Client) (*rep.Usage, error) {
+	metrics, err := client.GetBulkMetrics(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var memoryBytes, diskBytes uint64
+	cpuTimes := make(map[string]time.Duration, len(metrics))
+	for guid, metric := range metrics {
+		memoryBytes += metric.MemoryUsageInBytes
+		diskBytes += metric.DiskUsageInBytes
+		cpuTimes[guid] = metric.TimeSpentInCPU
+	}
+
+	usage := &rep.Usage{
+		MemoryMB: int32(memoryBytes / bytesPerMB),
+		DiskMB:   int32(diskBytes / bytesPerMB),
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.clock.Now()
+	elapsed := now.Sub(s.sampledAt)
+	if !s.sampledAt.IsZero() && elapsed > 0 && s.cpus > 0 {
+		var cpuTime time.Duration
+		for guid, current := range cpuTimes {
+			if previous, ok := s.cpuTimes[guid]; ok && current > previous {
+				cpuTime += current - previous
+			}
+		}
+		usage.CPU = float64(cpuTime) / float64(elapsed) / float64(s.cpus)
+	}
+
+	s.sampledAt = now
+	s.cpuTimes = cpuTimes
+	return usage, nil
+}
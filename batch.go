@@ -0,0 +1,25 @@
+package rep
+
+import "code.cloudfoundry.org/bbs/models"
+
+// LRPInstanceKeys identifies an LRP instance in a batch stop request.
+type LRPInstanceKeys struct {
+	Key         models.ActualLRPKey         `json:"actual_lrp_key"`
+	InstanceKey models.ActualLRPInstanceKey `json:"actual_lrp_instance_key"`
+}
+
+func NewLRPInstanceKeys(key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) LRPInstanceKeys {
+	return LRPInstanceKeys{Key: key, InstanceKey: instanceKey}
+}
+
+// BatchResult is the outcome of one item of a batch request. Guid is the
+// instance guid of an LRP instance or the guid of a task, and Error is empty
+// if the item succeeded.
+type BatchResult struct {
+	Guid  string `json:"guid"`
+	Error string `json:"error,omitempty"`
+}
+
+func (r BatchResult) Succeeded() bool {
+	return r.Error == ""
+}
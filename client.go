@@ -2,14 +2,18 @@ package rep
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"code.cloudfoundry.org/bbs/models"
@@ -18,25 +22,55 @@ import (
 	"github.com/tedsuo/rata"
 )
 
+// RequestTimeoutHeader carries the time the caller is still willing to wait
+// for a response, as a duration string such as "1.5s".
+const RequestTimeoutHeader = "X-Rep-Request-Timeout"
+
+// StatusCodeError is returned when a cell answers with an unexpected status
+// code.
+type StatusCodeError struct {
+	StatusCode int
+}
+
+func (e *StatusCodeError) Error() string {
+	return fmt.Sprintf("http error: status code %d (%s)", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
 //go:generate counterfeiter -o repfakes/fake_client_factory.go . ClientFactory
 
 type ClientFactory interface {
 	CreateClient(address, url string) (Client, error)
 }
 
+//go:generate counterfeiter -o repfakes/fake_context_client_factory.go . ContextClientFactory
+
+// ContextClientFactory is implemented by the factories this package provides.
+// It creates clients as ContextClients, so that callers can use the calls
+// that take a context without asserting the type of the client.
+type ContextClientFactory interface {
+	ClientFactory
+	CreateContextClient(address, url string) (ContextClient, error)
+}
+
 // capture the behavior described in the comment of this story
 // https://www.pivotaltracker.com/story/show/130664747/comments/152863773
 type TLSConfig struct {
 	RequireTLS                    bool
 	CertFile, KeyFile, CaCertFile string
 	ClientCacheSize               int // the tls client cache size, 0 means use golang default value
+
+	// Reloader, if set, is used instead of the cert files so that new
+	// connections pick up rotated certificates.
+	Reloader *CertificateReloader
 }
 
-// return true if all the certs files are set in the struct, i.e. not ""
+// return true if all the certs files are set in the struct, i.e. not "", or
+// a reloader provides them
 func (config *TLSConfig) hasCreds() bool {
-	return config.CaCertFile != "" &&
-		config.KeyFile != "" &&
-		config.CertFile != ""
+	return config.Reloader != nil ||
+		config.CaCertFile != "" &&
+			config.KeyFile != "" &&
+			config.CertFile != ""
 }
 
 // pick either the old address or the new rep_url depending on the announced
@@ -85,6 +119,18 @@ func (tlsConfig *TLSConfig) modifyTransport(client *http.Client) error {
 	}
 
 	if transport, ok := client.Transport.(*http.Transport); ok {
+		if tlsConfig.Reloader != nil {
+			dialContext := transport.DialContext
+			if dial := transport.Dial; dialContext == nil && dial != nil {
+				dialContext = func(_ context.Context, network, addr string) (net.Conn, error) {
+					return dial(network, addr)
+				}
+			}
+			sessionCache := tls.NewLRUClientSessionCache(tlsConfig.ClientCacheSize)
+			transport.DialTLSContext = tlsConfig.Reloader.DialTLS(dialContext, sessionCache, transport.TLSHandshakeTimeout)
+			return nil
+		}
+
 		config, err := cfhttp.NewTLSConfig(tlsConfig.CertFile, tlsConfig.KeyFile, tlsConfig.CaCertFile)
 		if err != nil {
 			return err
@@ -103,7 +149,7 @@ type clientFactory struct {
 	tlsConfig   *TLSConfig
 }
 
-func NewClientFactory(httpClient, stateClient *http.Client, tlsConfig *TLSConfig) (ClientFactory, error) {
+func NewClientFactory(httpClient, stateClient *http.Client, tlsConfig *TLSConfig) (ContextClientFactory, error) {
 	if tlsConfig == nil {
 		// zero values tls config
 		tlsConfig = &TLSConfig{}
@@ -125,6 +171,10 @@ func NewClientFactory(httpClient, stateClient *http.Client, tlsConfig *TLSConfig
 }
 
 func (factory *clientFactory) CreateClient(address, url string) (Client, error) {
+	return factory.CreateContextClient(address, url)
+}
+
+func (factory *clientFactory) CreateContextClient(address, url string) (ContextClient, error) {
 	urlToUse, err := factory.tlsConfig.pickURL(address, url)
 	if err != nil {
 		return nil, err
@@ -144,6 +194,29 @@ type Client interface {
 	StateClientTimeout() time.Duration
 }
 
+//go:generate counterfeiter -o repfakes/fake_context_client.go . ContextClient
+
+// ContextClient is implemented by the clients this package creates. It adds
+// calls that take a context, the batch calls and the container inventory to
+// Client, and is kept apart from it so that other implementations of Client
+// do not have to provide them.
+type ContextClient interface {
+	Client
+	StateWithContext(ctx context.Context, logger lager.Logger) (CellState, error)
+	StateSince(ctx context.Context, logger lager.Logger, since StateVersion, wait time.Duration) (CellState, StateVersion, bool, error)
+	PerformWithContext(ctx context.Context, logger lager.Logger, work Work) (Work, error)
+	StopLRPInstanceWithContext(ctx context.Context, logger lager.Logger, key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) error
+	CancelTaskWithContext(ctx context.Context, logger lager.Logger, taskGuid string) error
+	StopLRPInstances(logger lager.Logger, instances []LRPInstanceKeys) ([]BatchResult, error)
+	StopLRPInstancesWithContext(ctx context.Context, logger lager.Logger, instances []LRPInstanceKeys) ([]BatchResult, error)
+	CancelTasks(logger lager.Logger, taskGuids []string) ([]BatchResult, error)
+	CancelTasksWithContext(ctx context.Context, logger lager.Logger, taskGuids []string) ([]BatchResult, error)
+	Containers(logger lager.Logger, filter ContainerFilter) ([]ContainerInfo, error)
+	ContainersWithContext(ctx context.Context, logger lager.Logger, filter ContainerFilter) ([]ContainerInfo, error)
+	Container(logger lager.Logger, guid string) (ContainerInfo, error)
+	ContainerWithContext(ctx context.Context, logger lager.Logger, guid string) (ContainerInfo, error)
+}
+
 //go:generate counterfeiter -o repfakes/fake_sim_client.go . SimClient
 
 type SimClient interface {
@@ -156,9 +229,10 @@ type client struct {
 	stateClient      *http.Client
 	address          string
 	requestGenerator *rata.RequestGenerator
+	gzipRequests     int32
 }
 
-func newClient(httpClient, stateClient *http.Client, address string) Client {
+func newClient(httpClient, stateClient *http.Client, address string) ContextClient {
 	return &client{
 		client:           httpClient,
 		stateClient:      stateClient,
@@ -175,11 +249,45 @@ func (c *client) StateClientTimeout() time.Duration {
 	return c.stateClient.Timeout
 }
 
+// newRequest creates a request that is cancelled along with ctx, and that
+// tells the cell how long the caller is willing to wait if ctx has a
+// deadline.
+func (c *client) newRequest(ctx context.Context, name string, params rata.Params, body io.Reader) (*http.Request, error) {
+	req, err := c.requestGenerator.CreateRequest(name, params, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set(RequestTimeoutHeader, deadline.Sub(time.Now()).String())
+	}
+	return req.WithContext(ctx), nil
+}
+
+// readBody reads the body of a state or perform response, and remembers
+// whether the cell accepts gzipped requests.
+func (c *client) readBody(resp *http.Response) ([]byte, error) {
+	if AcceptsGzip(resp.Header.Get("Accept-Encoding")) {
+		atomic.StoreInt32(&c.gzipRequests, 1)
+	}
+
+	body, err := decodedBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(body)
+}
+
 func (c *client) State(logger lager.Logger) (CellState, error) {
-	req, err := c.requestGenerator.CreateRequest(StateRoute, nil, nil)
+	return c.StateWithContext(context.Background(), logger)
+}
+
+func (c *client) StateWithContext(ctx context.Context, logger lager.Logger) (CellState, error) {
+	req, err := c.newRequest(ctx, StateRoute, nil, nil)
 	if err != nil {
 		return CellState{}, err
 	}
+	req.Header.Set("Accept-Encoding", GzipEncoding)
 
 	resp, err := c.stateClient.Do(req)
 	if err != nil {
@@ -188,11 +296,11 @@ func (c *client) State(logger lager.Logger) (CellState, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return CellState{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return CellState{}, &StatusCodeError{StatusCode: resp.StatusCode}
 	}
 
 	var state CellState
-	bs, err := ioutil.ReadAll(resp.Body)
+	bs, err := c.readBody(resp)
 	if err != nil {
 		return CellState{}, err
 	}
@@ -204,16 +312,88 @@ func (c *client) State(logger lager.Logger) (CellState, error) {
 	return state, nil
 }
 
+// StateSince returns the state of the cell along with its version, and
+// whether it differs from the since version. A zero since version always
+// fetches the state. A positive wait asks the cell to hold the request until
+// the state changes; it should be shorter than the timeout of the state
+// client.
+func (c *client) StateSince(ctx context.Context, logger lager.Logger, since StateVersion, wait time.Duration) (CellState, StateVersion, bool, error) {
+	req, err := c.newRequest(ctx, StateRoute, nil, nil)
+	if err != nil {
+		return CellState{}, StateVersion{}, false, err
+	}
+	req.Header.Set("Accept-Encoding", GzipEncoding)
+
+	if !since.IsZero() {
+		req.Header.Set("If-None-Match", since.ETag())
+		if wait > 0 {
+			query := req.URL.Query()
+			query.Set(StateWaitParam, wait.String())
+			req.URL.RawQuery = query.Encode()
+		}
+	}
+
+	resp, err := c.stateClient.Do(req)
+	if err != nil {
+		return CellState{}, StateVersion{}, false, err
+	}
+	defer resp.Body.Close()
+
+	var version StateVersion
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		version, err = ParseStateETag(etag)
+		if err != nil {
+			return CellState{}, StateVersion{}, false, err
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return CellState{}, version, false, nil
+	case http.StatusOK:
+	default:
+		return CellState{}, StateVersion{}, false, &StatusCodeError{StatusCode: resp.StatusCode}
+	}
+
+	var state CellState
+	bs, err := c.readBody(resp)
+	if err != nil {
+		return CellState{}, StateVersion{}, false, err
+	}
+	err = json.Unmarshal(bs, &state)
+	if err != nil {
+		return CellState{}, StateVersion{}, false, err
+	}
+
+	return state, version, true, nil
+}
+
 func (c *client) Perform(logger lager.Logger, work Work) (Work, error) {
+	return c.PerformWithContext(context.Background(), logger, work)
+}
+
+func (c *client) PerformWithContext(ctx context.Context, logger lager.Logger, work Work) (Work, error) {
 	body, err := json.Marshal(work)
 	if err != nil {
 		return Work{}, err
 	}
 
-	req, err := c.requestGenerator.CreateRequest(PerformRoute, nil, bytes.NewReader(body))
+	gzipped := atomic.LoadInt32(&c.gzipRequests) == 1
+	if gzipped {
+		body, err = gzipBytes(body)
+		if err != nil {
+			return Work{}, err
+		}
+	}
+
+	req, err := c.newRequest(ctx, PerformRoute, nil, bytes.NewReader(body))
 	if err != nil {
 		return Work{}, err
 	}
+	req.Header.Set("Accept-Encoding", GzipEncoding)
+	if gzipped {
+		req.Header.Set("Content-Encoding", GzipEncoding)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -222,11 +402,15 @@ func (c *client) Perform(logger lager.Logger, work Work) (Work, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return Work{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return Work{}, &StatusCodeError{StatusCode: resp.StatusCode}
 	}
 
 	var failedWork Work
-	err = json.NewDecoder(resp.Body).Decode(&failedWork)
+	bs, err := c.readBody(resp)
+	if err != nil {
+		return Work{}, err
+	}
+	err = json.Unmarshal(bs, &failedWork)
 	if err != nil {
 		return Work{}, err
 	}
@@ -247,7 +431,7 @@ func (c *client) Reset() error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return &StatusCodeError{StatusCode: resp.StatusCode}
 	}
 
 	return nil
@@ -257,6 +441,15 @@ func (c *client) StopLRPInstance(
 	logger lager.Logger,
 	key models.ActualLRPKey,
 	instanceKey models.ActualLRPInstanceKey,
+) error {
+	return c.StopLRPInstanceWithContext(context.Background(), logger, key, instanceKey)
+}
+
+func (c *client) StopLRPInstanceWithContext(
+	ctx context.Context,
+	logger lager.Logger,
+	key models.ActualLRPKey,
+	instanceKey models.ActualLRPInstanceKey,
 ) error {
 	start := time.Now()
 	logger = logger.Session("stop-lrp", lager.Data{"process-guid": key.ProcessGuid,
@@ -266,7 +459,7 @@ func (c *client) StopLRPInstance(
 	})
 	logger.Info("starting")
 
-	req, err := c.requestGenerator.CreateRequest(StopLRPInstanceRoute, stopParamsFromLRP(key, instanceKey), nil)
+	req, err := c.newRequest(ctx, StopLRPInstanceRoute, stopParamsFromLRP(key, instanceKey), nil)
 	if err != nil {
 		logger.Error("connection-failed", err)
 		return err
@@ -282,7 +475,7 @@ func (c *client) StopLRPInstance(
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusAccepted {
-		err := fmt.Errorf("http error: status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+		err := &StatusCodeError{StatusCode: resp.StatusCode}
 		logger.Error("failed-with-status", err, lager.Data{"status-code": resp.StatusCode, "msg": http.StatusText(resp.StatusCode)})
 		return err
 	}
@@ -292,11 +485,15 @@ func (c *client) StopLRPInstance(
 }
 
 func (c *client) CancelTask(logger lager.Logger, taskGuid string) error {
+	return c.CancelTaskWithContext(context.Background(), logger, taskGuid)
+}
+
+func (c *client) CancelTaskWithContext(ctx context.Context, logger lager.Logger, taskGuid string) error {
 	start := time.Now()
 	logger = logger.Session("cancel-task", lager.Data{"task-guid": taskGuid})
 	logger.Info("starting")
 
-	req, err := c.requestGenerator.CreateRequest(CancelTaskRoute, rata.Params{"task_guid": taskGuid}, nil)
+	req, err := c.newRequest(ctx, CancelTaskRoute, rata.Params{"task_guid": taskGuid}, nil)
 	if err != nil {
 		logger.Error("connection-failed", err)
 		return err
@@ -311,7 +508,7 @@ func (c *client) CancelTask(logger lager.Logger, taskGuid string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusAccepted {
-		err := fmt.Errorf("http error: status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+		err := &StatusCodeError{StatusCode: resp.StatusCode}
 		logger.Error("failed-with-status", err, lager.Data{"status-code": resp.StatusCode, "msg": http.StatusText(resp.StatusCode)})
 		return err
 	}
@@ -320,6 +517,129 @@ func (c *client) CancelTask(logger lager.Logger, taskGuid string) error {
 	return nil
 }
 
+func (c *client) StopLRPInstances(logger lager.Logger, instances []LRPInstanceKeys) ([]BatchResult, error) {
+	return c.StopLRPInstancesWithContext(context.Background(), logger, instances)
+}
+
+func (c *client) StopLRPInstancesWithContext(ctx context.Context, logger lager.Logger, instances []LRPInstanceKeys) ([]BatchResult, error) {
+	logger = logger.Session("stop-lrps", lager.Data{"num-instances": len(instances)})
+	return c.batch(ctx, logger, StopLRPInstancesRoute, instances)
+}
+
+func (c *client) CancelTasks(logger lager.Logger, taskGuids []string) ([]BatchResult, error) {
+	return c.CancelTasksWithContext(context.Background(), logger, taskGuids)
+}
+
+func (c *client) CancelTasksWithContext(ctx context.Context, logger lager.Logger, taskGuids []string) ([]BatchResult, error) {
+	logger = logger.Session("cancel-tasks", lager.Data{"num-tasks": len(taskGuids)})
+	return c.batch(ctx, logger, CancelTasksRoute, taskGuids)
+}
+
+func (c *client) Containers(logger lager.Logger, filter ContainerFilter) ([]ContainerInfo, error) {
+	return c.ContainersWithContext(context.Background(), logger, filter)
+}
+
+func (c *client) ContainersWithContext(ctx context.Context, logger lager.Logger, filter ContainerFilter) ([]ContainerInfo, error) {
+	logger = logger.Session("list-containers", lager.Data{"filter": filter})
+
+	req, err := c.newRequest(ctx, ContainersRoute, nil, nil)
+	if err != nil {
+		logger.Error("connection-failed", err)
+		return nil, err
+	}
+	req.URL.RawQuery = filter.Query().Encode()
+
+	var infos []ContainerInfo
+	err = c.getJSON(logger, req, &infos)
+	return infos, err
+}
+
+func (c *client) Container(logger lager.Logger, guid string) (ContainerInfo, error) {
+	return c.ContainerWithContext(context.Background(), logger, guid)
+}
+
+func (c *client) ContainerWithContext(ctx context.Context, logger lager.Logger, guid string) (ContainerInfo, error) {
+	logger = logger.Session("get-container", lager.Data{"guid": guid})
+
+	req, err := c.newRequest(ctx, ContainerRoute, rata.Params{"guid": guid}, nil)
+	if err != nil {
+		logger.Error("connection-failed", err)
+		return ContainerInfo{}, err
+	}
+
+	var info ContainerInfo
+	err = c.getJSON(logger, req, &info)
+	return info, err
+}
+
+func (c *client) getJSON(logger lager.Logger, req *http.Request, v interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Error("request-failed", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrContainerNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := &StatusCodeError{StatusCode: resp.StatusCode}
+		logger.Error("failed-with-status", err, lager.Data{"status-code": resp.StatusCode, "msg": http.StatusText(resp.StatusCode)})
+		return err
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		logger.Error("failed-to-decode", err)
+		return err
+	}
+	return nil
+}
+
+func (c *client) batch(ctx context.Context, logger lager.Logger, route string, items interface{}) ([]BatchResult, error) {
+	start := time.Now()
+	logger.Info("starting")
+
+	body, err := json.Marshal(items)
+	if err != nil {
+		logger.Error("failed-to-marshal", err)
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, route, nil, bytes.NewReader(body))
+	if err != nil {
+		logger.Error("connection-failed", err)
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Error("request-failed", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := &StatusCodeError{StatusCode: resp.StatusCode}
+		logger.Error("failed-with-status", err, lager.Data{"status-code": resp.StatusCode, "msg": http.StatusText(resp.StatusCode)})
+		return nil, err
+	}
+
+	var results []BatchResult
+	err = json.NewDecoder(resp.Body).Decode(&results)
+	if err != nil {
+		logger.Error("failed-to-decode-results", err)
+		return nil, err
+	}
+
+	logger.Info("completed", lager.Data{"duration": time.Since(start)})
+	return results, nil
+}
+
 func stopParamsFromLRP(
 	key models.ActualLRPKey,
 	instanceKey models.ActualLRPInstanceKey,
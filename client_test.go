@@ -1,6 +1,10 @@
 package rep_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
 	"net/http"
 	"os"
 	"path"
@@ -74,12 +78,12 @@ var _ = Describe("ClientFactory", func() {
 
 var _ = Describe("Client", func() {
 	var fakeServer *ghttp.Server
-	var client rep.Client
+	var client rep.ContextClient
 
 	BeforeEach(func() {
 		fakeServer = ghttp.NewServer()
 		var err error
-		client, err = factory.CreateClient(fakeServer.URL(), "")
+		client, err = factory.CreateContextClient(fakeServer.URL(), "")
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -114,6 +118,119 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("compression", func() {
+		var logger *lagertest.TestLogger
+
+		gzipped := func(v interface{}) []byte {
+			var buf bytes.Buffer
+			writer := gzip.NewWriter(&buf)
+			Expect(json.NewEncoder(writer).Encode(v)).To(Succeed())
+			Expect(writer.Close()).To(Succeed())
+			return buf.Bytes()
+		}
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+		})
+
+		It("decompresses a gzipped state", func() {
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("Accept-Encoding", rep.GzipEncoding),
+					ghttp.RespondWith(http.StatusOK, gzipped(rep.CellState{Zone: "z1"}), http.Header{"Content-Encoding": []string{rep.GzipEncoding}}),
+				),
+			)
+
+			state, err := client.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Zone).To(Equal("z1"))
+		})
+
+		It("only gzips work once the cell has said it accepts it", func() {
+			work := rep.Work{Tasks: []rep.Task{rep.NewTask("some-task", "domain", rep.NewResource(1, 2, 3), rep.PlacementConstraint{})}}
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/work"),
+					func(_ http.ResponseWriter, req *http.Request) {
+						Expect(req.Header.Get("Content-Encoding")).To(BeEmpty())
+					},
+					ghttp.VerifyJSONRepresenting(work),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, rep.Work{}, http.Header{"Accept-Encoding": []string{rep.GzipEncoding}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/work"),
+					ghttp.VerifyHeaderKV("Content-Encoding", rep.GzipEncoding),
+					func(_ http.ResponseWriter, req *http.Request) {
+						reader, err := gzip.NewReader(req.Body)
+						Expect(err).NotTo(HaveOccurred())
+						var received rep.Work
+						Expect(json.NewDecoder(reader).Decode(&received)).To(Succeed())
+						Expect(received).To(Equal(work))
+					},
+					ghttp.RespondWithJSONEncoded(http.StatusOK, rep.Work{}),
+				),
+			)
+
+			_, err := client.Perform(logger, work)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.Perform(logger, work)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeServer.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Describe("StateSince", func() {
+		var (
+			logger  *lagertest.TestLogger
+			version rep.StateVersion
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			version = rep.StateVersion{Epoch: 1, Number: 3}
+		})
+
+		It("fetches the state and its version", func() {
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/state"),
+					func(_ http.ResponseWriter, req *http.Request) {
+						Expect(req.Header.Get("If-None-Match")).To(BeEmpty())
+					},
+					ghttp.RespondWithJSONEncoded(http.StatusOK, rep.CellState{Zone: "z1"}, http.Header{"ETag": []string{version.ETag()}}),
+				),
+			)
+
+			state, current, changed, err := client.StateSince(context.Background(), logger, rep.StateVersion{}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(state.Zone).To(Equal("z1"))
+			Expect(current).To(Equal(version))
+		})
+
+		It("reports an unchanged state", func() {
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/state", "wait=30s"),
+					ghttp.VerifyHeaderKV("If-None-Match", version.ETag()),
+					ghttp.RespondWith(http.StatusNotModified, nil, http.Header{"ETag": []string{version.ETag()}}),
+				),
+			)
+
+			_, current, changed, err := client.StateSince(context.Background(), logger, version, 30*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeFalse())
+			Expect(current).To(Equal(version))
+		})
+
+		It("fails on an unexpected status", func() {
+			fakeServer.AppendHandlers(ghttp.RespondWith(http.StatusInternalServerError, nil))
+
+			_, _, _, err := client.StateSince(context.Background(), logger, version, 0)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("StopLRPInstance", func() {
 		const cellAddr = "cell.example.com"
 		var (
@@ -312,4 +429,171 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("context variants", func() {
+		var logger *lagertest.TestLogger
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+		})
+
+		It("sends the time remaining before the deadline", func() {
+			var timeout time.Duration
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/work"),
+					func(w http.ResponseWriter, r *http.Request) {
+						var err error
+						timeout, err = time.ParseDuration(r.Header.Get(rep.RequestTimeoutHeader))
+						Expect(err).NotTo(HaveOccurred())
+					},
+					ghttp.RespondWithJSONEncoded(http.StatusOK, rep.Work{}),
+				),
+			)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_, err := client.PerformWithContext(ctx, logger, rep.Work{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeout).To(BeNumerically(">", 50*time.Second))
+			Expect(timeout).To(BeNumerically("<=", time.Minute))
+		})
+
+		It("does not send a timeout without a deadline", func() {
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/state"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.Header).NotTo(HaveKey(rep.RequestTimeoutHeader))
+					},
+					ghttp.RespondWithJSONEncoded(http.StatusOK, rep.CellState{}),
+				),
+			)
+
+			_, err := client.StateWithContext(context.Background(), logger)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("gives up when the context is cancelled", func() {
+			done := make(chan struct{})
+			defer close(done)
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v1/tasks/some-task-guid/cancel"),
+					func(w http.ResponseWriter, r *http.Request) {
+						<-done
+					},
+				),
+			)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			err := client.CancelTaskWithContext(ctx, logger, "some-task-guid")
+			Expect(err).To(HaveOccurred())
+			Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+		})
+	})
+
+	Describe("batches", func() {
+		var logger *lagertest.TestLogger
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+		})
+
+		It("stops many lrp instances in one request", func() {
+			instances := []rep.LRPInstanceKeys{
+				rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg-1", 0, "domain"), models.NewActualLRPInstanceKey("ig-1", "cell")),
+				rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg-2", 0, "domain"), models.NewActualLRPInstanceKey("ig-2", "cell")),
+			}
+			expected := []rep.BatchResult{{Guid: "ig-1"}, {Guid: "ig-2", Error: "boom"}}
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v1/lrps/stop"),
+					ghttp.VerifyJSONRepresenting(instances),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, expected),
+				),
+			)
+
+			results, err := client.StopLRPInstances(logger, instances)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal(expected))
+		})
+
+		It("cancels many tasks in one request", func() {
+			expected := []rep.BatchResult{{Guid: "task-1"}, {Guid: "task-2"}}
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/v1/tasks/cancel"),
+					ghttp.VerifyJSONRepresenting([]string{"task-1", "task-2"}),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, expected),
+				),
+			)
+
+			results, err := client.CancelTasks(logger, []string{"task-1", "task-2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal(expected))
+		})
+
+		It("returns an error when the request fails", func() {
+			fakeServer.AppendHandlers(ghttp.RespondWith(http.StatusInternalServerError, ""))
+
+			_, err := client.CancelTasks(logger, []string{"task-1"})
+			Expect(err).To(HaveOccurred())
+			Expect(logger.Buffer()).To(gbytes.Say("cancel-tasks.failed-with-status"))
+		})
+	})
+
+	Describe("containers", func() {
+		var logger *lagertest.TestLogger
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+		})
+
+		It("lists the containers matching a filter", func() {
+			expected := []rep.ContainerInfo{{Guid: "container-guid", Lifecycle: rep.TaskLifecycle}}
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/containers", "domain=cf-tasks&lifecycle=task"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, expected),
+				),
+			)
+
+			infos, err := client.Containers(logger, rep.ContainerFilter{Lifecycle: rep.TaskLifecycle, Domain: "cf-tasks"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(infos).To(Equal(expected))
+		})
+
+		It("gets a single container", func() {
+			expected := rep.ContainerInfo{Guid: "container-guid"}
+			fakeServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/containers/container-guid"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, expected),
+				),
+			)
+
+			info, err := client.Container(logger, "container-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).To(Equal(expected))
+		})
+
+		It("reports a missing container", func() {
+			fakeServer.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, nil))
+
+			_, err := client.Container(logger, "missing-guid")
+			Expect(err).To(Equal(rep.ErrContainerNotFound))
+		})
+
+		It("fails on an unexpected status", func() {
+			fakeServer.AppendHandlers(ghttp.RespondWith(http.StatusInternalServerError, nil))
+
+			_, err := client.Containers(logger, rep.ContainerFilter{})
+			Expect(err).To(HaveOccurred())
+			Expect(logger.Buffer()).To(gbytes.Say("list-containers.failed-with-status"))
+		})
+	})
 })
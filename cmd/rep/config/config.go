@@ -14,6 +14,7 @@ import (
 	loggregator_v2 "code.cloudfoundry.org/go-loggregator/compatibility"
 	"code.cloudfoundry.org/lager/lagerflags"
 	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/rep"
 )
 
 type StackMap map[string]string
@@ -58,6 +59,15 @@ func (m StackMap) MarshalJSON() (b []byte, err error) {
 	return data, nil
 }
 
+// RootFSPatterns restricts the rootfses a scheme accepts, keyed by scheme.
+// A scheme with patterns does not need to be listed in supported_providers.
+// The cell advertises the patterns, so that auctioneers only place allowed
+// rootfses on it, and rejects any other rootfs it is asked to run. Auctioneers
+// that predate the pattern provider cannot decode it; for them,
+// hide_rootfs_patterns advertises the scheme as arbitrary instead, and the
+// rootfses are only filtered by the cell, once they have been placed on it.
+type RootFSPatterns map[string]rep.PatternRootFSProvider
+
 type RepConfig struct {
 	AdvertiseDomain           string                `json:"advertise_domain,omitempty"`
 	BBSAddress                string                `json:"bbs_address"`
@@ -68,29 +78,45 @@ type RepConfig struct {
 	BBSMaxIdleConnsPerHost    int                   `json:"bbs_max_idle_conns_per_host,omitempty"`
 	CaCertFile                string                `json:"ca_cert_file"`
 	CellID                    string                `json:"cell_id"`
+	CertReloadInterval        durationjson.Duration `json:"cert_reload_interval,omitempty"`
 	CommunicationTimeout      durationjson.Duration `json:"communication_timeout,omitempty"`
 	ConsulCACert              string                `json:"consul_ca_cert"`
 	ConsulClientCert          string                `json:"consul_client_cert"`
 	ConsulClientKey           string                `json:"consul_client_key"`
 	ConsulCluster             string                `json:"consul_cluster"`
+	CPUWeightCapacity         int                   `json:"cpu_weight_capacity,omitempty"`
+	DiskOvercommitRatio       float64               `json:"disk_overcommit_ratio,omitempty"`
 	DropsondePort             int                   `json:"dropsonde_port,omitempty"`
 	EnableLegacyAPIServer     bool                  `json:"enable_legacy_api_endpoints"`
 	EvacuationPollingInterval durationjson.Duration `json:"evacuation_polling_interval,omitempty"`
 	EvacuationTimeout         durationjson.Duration `json:"evacuation_timeout,omitempty"`
+	ExtendedResources         map[string]int32      `json:"extended_resources,omitempty"`
+	HealthCheckTimeout        durationjson.Duration `json:"health_check_timeout,omitempty"`
+	HideRootFSPatterns        bool                  `json:"hide_rootfs_patterns,omitempty"`
+	Labels                    map[string]string     `json:"labels,omitempty"`
 	ListenAddr                string                `json:"listen_addr,omitempty"`
 	ListenAddrAdmin           string                `json:"listen_addr_admin"`
 	ListenAddrSecurable       string                `json:"listen_addr_securable,omitempty"`
 	LockRetryInterval         durationjson.Duration `json:"lock_retry_interval,omitempty"`
 	LockTTL                   durationjson.Duration `json:"lock_ttl,omitempty"`
+	MaxPidsCapacity           int                   `json:"max_pids_capacity,omitempty"`
+	MemoryOvercommitRatio     float64               `json:"memory_overcommit_ratio,omitempty"`
 	OptionalPlacementTags     []string              `json:"optional_placement_tags"`
 	PlacementTags             []string              `json:"placement_tags"`
 	PollingInterval           durationjson.Duration `json:"polling_interval,omitempty"`
 	PreloadedRootFS           StackMap              `json:"preloaded_root_fs"`
+	ReloadInterval            durationjson.Duration `json:"reload_interval,omitempty"`
+	ReportContainerUsage      bool                  `json:"report_container_usage,omitempty"`
 	RequireTLS                bool                  `json:"require_tls"`
+	ReservedDiskMB            int                   `json:"reserved_disk_mb,omitempty"`
+	ReservedMemoryMB          int                   `json:"reserved_memory_mb,omitempty"`
+	RootFSPatterns            RootFSPatterns        `json:"rootfs_patterns,omitempty"`
 	ServerCertFile            string                `json:"server_cert_file"`
 	ServerKeyFile             string                `json:"server_key_file"`
 	SessionName               string                `json:"session_name,omitempty"`
+	StateMaxAge               durationjson.Duration `json:"state_max_age,omitempty"`
 	SupportedProviders        []string              `json:"supported_providers"`
+	Topology                  []string              `json:"topology,omitempty"`
 	Zone                      string                `json:"zone"`
 	LoggregatorConfig         loggregator_v2.Config `json:"loggregator"`
 	debugserver.DebugServerConfig
@@ -104,12 +130,14 @@ func defaultConfig() RepConfig {
 		AdvertiseDomain:           "cell.service.cf.internal",
 		BBSClientSessionCacheSize: 0,
 		BBSMaxIdleConnsPerHost:    0,
+		CertReloadInterval:        durationjson.Duration(time.Minute),
 		CommunicationTimeout:      durationjson.Duration(10 * time.Second),
 		DropsondePort:             3457,
 		EnableLegacyAPIServer:     true,
 		EvacuationPollingInterval: durationjson.Duration(10 * time.Second),
 		EvacuationTimeout:         durationjson.Duration(10 * time.Minute),
 		ExecutorConfig:            executorinit.DefaultConfiguration,
+		HealthCheckTimeout:        durationjson.Duration(5 * time.Second),
 		LagerConfig:               lagerflags.DefaultLagerConfig(),
 		ListenAddr:                "0.0.0.0:1800",
 		ListenAddrSecurable:       "0.0.0.0:1801",
@@ -12,6 +12,7 @@ import (
 	loggregator_v2 "code.cloudfoundry.org/go-loggregator/compatibility"
 	"code.cloudfoundry.org/lager/lagerflags"
 	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/cmd/rep/config"
 
 	. "github.com/onsi/ginkgo"
@@ -33,6 +34,7 @@ var _ = Describe("RepConfig", func() {
 			"ca_cert_file": "/tmp/ca_cert",
 			"cache_path": "/tmp/cache",
 			"cell_id" : "cell_z1/10",
+			"cert_reload_interval": "45s",
 			"communication_timeout": "11s",
 			"consul_ca_cert": "/tmp/consul_ca_cert",
 			"consul_client_cert": "/tmp/consul_client_cert",
@@ -43,16 +45,19 @@ var _ = Describe("RepConfig", func() {
 			"container_metrics_report_interval": "16s",
 			"container_owner_name": "vcap",
 			"container_reap_interval": "11s",
+			"cpu_weight_capacity": 800,
 			"create_work_pool_size": 15,
 			"debug_address": "5.5.5.5:9090",
 			"delete_work_pool_size": 10,
 			"disk_mb": "20000",
+			"disk_overcommit_ratio": 1.5,
 			"dropsonde_port": 8082,
 			"enable_declarative_healthcheck": true,
 			"declarative_healthcheck_path": "/var/vcap/packages/healthcheck",
 			"enable_legacy_api_endpoints": true,
 			"evacuation_polling_interval" : "13s",
 			"evacuation_timeout" : "12s",
+			"extended_resources": {"licence-seats": 4, "ssd-slots": 2},
 			"export_network_env_vars": false,
 			"garden_addr": "100.0.0.1",
 			"garden_healthcheck_command_retry_pause": "15s",
@@ -65,10 +70,13 @@ var _ = Describe("RepConfig", func() {
 			"garden_healthcheck_process_user": "vcap_health",
 			"garden_healthcheck_timeout": "14s",
 			"garden_network": "test-network",
+			"health_check_timeout": "3s",
+			"hide_rootfs_patterns": true,
 			"healthcheck_container_owner_name": "vcap_health",
 			"healthcheck_work_pool_size": 10,
 			"healthy_monitoring_interval": "5s",
 			"healthy_monitoring_interval": "5s",
+			"labels": {"disk": "ssd", "rack": "r1"},
 			"listen_addr": "0.0.0.0:8080",
 			"listen_addr_admin": "0.0.0.1:8081",
 			"listen_addr_securable": "0.0.0.0:8081",
@@ -93,7 +101,9 @@ var _ = Describe("RepConfig", func() {
 			},
 			"max_cache_size_in_bytes": 101,
 			"max_concurrent_downloads": 11,
+			"max_pids_capacity": 100000,
 			"memory_mb": "1000",
+			"memory_overcommit_ratio": 2,
 			"metrics_work_pool_size": 5,
 			"optional_placement_tags": ["otag1", "otag2"],
 			"path_to_ca_certs_for_downloads": "/tmp/ca-certs",
@@ -103,13 +113,25 @@ var _ = Describe("RepConfig", func() {
 			"post_setup_user": "post_setup_user",
 			"preloaded_root_fs": ["test:value", "test2:value2"],
 			"read_work_pool_size": 15,
+			"reload_interval": "30s",
+			"report_container_usage": true,
 			"require_tls": true,
+			"reserved_disk_mb": 1024,
 			"reserved_expiration_time": "10s",
+			"reserved_memory_mb": 512,
+			"rootfs_patterns": {
+				"docker": {
+					"allow": {"hosts": ["registry.internal"], "repositories": ["team/*"]},
+					"deny": {"regexps": ["#latest$"]}
+				}
+			},
 			"server_cert_file": "/tmp/server_cert",
 			"server_key_file": "/tmp/server_key",
 			"session_name": "test",
 			"skip_cert_verify": true,
+			"state_max_age": "2s",
 			"supported_providers": ["provider1", "provider2"],
+			"topology": ["us-east", "us-east-1a", "rack-3"],
 			"temp_dir": "/tmp/test",
 			"trusted_system_certificates_path": "/tmp/trusted",
 			"unhealthy_monitoring_interval": "10s",
@@ -141,6 +163,12 @@ var _ = Describe("RepConfig", func() {
 		repConfig, err := config.NewRepConfig(configFilePath)
 		Expect(err).NotTo(HaveOccurred())
 
+		dockerPatterns, err := rep.NewPatternRootFSProvider(
+			rep.RootFSPatterns{Hosts: []string{"registry.internal"}, Repositories: []string{"team/*"}},
+			rep.RootFSPatterns{Regexps: []string{"#latest$"}},
+		)
+		Expect(err).NotTo(HaveOccurred())
+
 		Expect(repConfig).To(Equal(config.RepConfig{
 			AdvertiseDomain:           "test-domain",
 			BBSAddress:                "1.1.1.1:9091",
@@ -151,6 +179,7 @@ var _ = Describe("RepConfig", func() {
 			BBSMaxIdleConnsPerHost:    10,
 			CaCertFile:                "/tmp/ca_cert",
 			CellID:                    "cell_z1/10",
+			CertReloadInterval:        durationjson.Duration(45 * time.Second),
 			ClientLocketConfig: locket.ClientLocketConfig{
 				LocketAddress:        "0.0.0.0:909090909",
 				LocketCACertFile:     "locket-ca-cert",
@@ -162,13 +191,16 @@ var _ = Describe("RepConfig", func() {
 			ConsulClientCert:     "/tmp/consul_client_cert",
 			ConsulClientKey:      "/tmp/consul_client_key",
 			ConsulCluster:        "test cluster",
+			CPUWeightCapacity:    800,
 			DebugServerConfig: debugserver.DebugServerConfig{
 				DebugAddress: "5.5.5.5:9090",
 			},
+			DiskOvercommitRatio:       1.5,
 			DropsondePort:             8082,
 			EnableLegacyAPIServer:     true,
 			EvacuationPollingInterval: durationjson.Duration(13 * time.Second),
 			EvacuationTimeout:         durationjson.Duration(12 * time.Second),
+			ExtendedResources:         map[string]int32{"licence-seats": 4, "ssd-slots": 2},
 			ExecutorConfig: executorinit.ExecutorConfig{
 				CachePath:                      "/tmp/cache",
 				ContainerInodeLimit:            1000,
@@ -211,23 +243,35 @@ var _ = Describe("RepConfig", func() {
 				UnhealthyMonitoringInterval:   10000000000,
 				VolmanDriverPaths:             "/tmp/volman1:/tmp/volman2",
 			},
+			HealthCheckTimeout: durationjson.Duration(3 * time.Second),
+			HideRootFSPatterns: true,
 			LagerConfig: lagerflags.LagerConfig{
 				LogLevel: lagerflags.DEBUG,
 			},
+			Labels:                map[string]string{"disk": "ssd", "rack": "r1"},
 			ListenAddr:            "0.0.0.0:8080",
 			ListenAddrAdmin:       "0.0.0.1:8081",
 			ListenAddrSecurable:   "0.0.0.0:8081",
 			LockRetryInterval:     durationjson.Duration(5 * time.Second),
 			LockTTL:               durationjson.Duration(5 * time.Second),
+			MaxPidsCapacity:       100000,
+			MemoryOvercommitRatio: 2,
 			OptionalPlacementTags: []string{"otag1", "otag2"},
 			PlacementTags:         []string{"tag1", "tag2"},
 			PollingInterval:       durationjson.Duration(10 * time.Second),
 			PreloadedRootFS:       map[string]string{"test": "value", "test2": "value2"},
+			ReloadInterval:        durationjson.Duration(30 * time.Second),
+			ReportContainerUsage:  true,
 			RequireTLS:            true,
+			ReservedDiskMB:        1024,
+			ReservedMemoryMB:      512,
+			RootFSPatterns:        config.RootFSPatterns{"docker": dockerPatterns},
 			ServerCertFile:        "/tmp/server_cert",
 			ServerKeyFile:         "/tmp/server_key",
 			SessionName:           "test",
+			StateMaxAge:           durationjson.Duration(2 * time.Second),
 			SupportedProviders:    []string{"provider1", "provider2"},
+			Topology:              []string{"us-east", "us-east-1a", "rack-3"},
 			Zone:                  "test-zone",
 			LoggregatorConfig: loggregator_v2.Config{
 				UseV2API:      true,
@@ -261,6 +305,17 @@ var _ = Describe("RepConfig", func() {
 			Expect(err).To(HaveOccurred())
 		})
 
+		Context("because a rootfs pattern is not a valid regexp", func() {
+			BeforeEach(func() {
+				configData = `{"rootfs_patterns": {"docker": {"allow": {"regexps": ["("]}}}}`
+			})
+
+			It("returns an error", func() {
+				_, err := config.NewRepConfig(configFilePath)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
 		Context("because the communication_timeout is not valid", func() {
 			BeforeEach(func() {
 				configData = `{"communication_timeout": 4234342342}`
@@ -292,6 +347,8 @@ var _ = Describe("RepConfig", func() {
 				PollingInterval:           durationjson.Duration(30 * time.Second),
 				DropsondePort:             3457,
 				CommunicationTimeout:      durationjson.Duration(10 * time.Second),
+				CertReloadInterval:        durationjson.Duration(time.Minute),
+				HealthCheckTimeout:        durationjson.Duration(5 * time.Second),
 				EvacuationPollingInterval: durationjson.Duration(10 * time.Second),
 				AdvertiseDomain:           "cell.service.cf.internal",
 				EnableLegacyAPIServer:     true,
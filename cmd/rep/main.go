@@ -1,18 +1,20 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"net"
 	"net/url"
 	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"code.cloudfoundry.org/bbs"
-	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/cfhttp"
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/consuladapter"
@@ -25,7 +27,6 @@ import (
 	"code.cloudfoundry.org/lager/lagerflags"
 	"code.cloudfoundry.org/localip"
 	"code.cloudfoundry.org/locket"
-	"code.cloudfoundry.org/locket/lock"
 	locketmodels "code.cloudfoundry.org/locket/models"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/rep"
@@ -36,7 +37,9 @@ import (
 	"code.cloudfoundry.org/rep/generator"
 	"code.cloudfoundry.org/rep/handlers"
 	"code.cloudfoundry.org/rep/harmonizer"
+	"code.cloudfoundry.org/rep/health"
 	"code.cloudfoundry.org/rep/maintain"
+	"code.cloudfoundry.org/rep/reloader"
 	"github.com/cloudfoundry/dropsonde"
 	"github.com/hashicorp/consul/api"
 	"github.com/nu7hatch/gouuid"
@@ -102,6 +105,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	resourceCeiling, err := overcommitExecutorCapacity(&repConfig)
+	if err != nil {
+		logger.Error("invalid-overcommit-configuration", err)
+		os.Exit(1)
+	}
+
 	metronClient, err := initializeMetron(logger, repConfig)
 	if err != nil {
 		logger.Error("failed-to-initialize-metron-client", err)
@@ -118,31 +127,45 @@ func main() {
 	consulClient := initializeConsulClient(logger, repConfig)
 
 	serviceClient := maintain.NewCellPresenceClient(consulClient, clock)
+	locketClient := initializeLocketClient(logger, repConfig)
 
-	evacuatable, evacuationReporter, evacuationNotifier := evacuation_context.New()
+	// the presence is owned by the same guid for the life of the process, so
+	// that it can be updated in place rather than released and reacquired
+	presenceGuid, err := uuid.NewV4()
+	if err != nil {
+		logger.Fatal("failed-to-generate-guid", err)
+	}
+	presenceOwner := presenceGuid.String()
+
+	evacuatable, evacuationReporter, evacuationNotifier, resumable := evacuation_context.New()
+	cordonable, cordonReporter := evacuation_context.NewCordon()
 
 	// only one outstanding operation per container is necessary
 	queue := operationq.NewSlidingQueue(1)
 
+	handoffs := evacuation_context.NewHandoffCounter()
 	evacuator := evacuation.NewEvacuator(
 		logger,
 		clock,
 		executorClient,
 		evacuationNotifier,
+		resumable,
 		repConfig.CellID,
 		time.Duration(repConfig.EvacuationTimeout),
 		time.Duration(repConfig.EvacuationPollingInterval),
+		handoffs,
 	)
 
 	bbsClient := initializeBBSClient(logger, repConfig)
-	httpServer, address := initializeServer(bbsClient, executorClient, evacuatable, evacuationReporter, logger, repConfig, false)
-	httpsServer, _ := initializeServer(bbsClient, executorClient, evacuatable, evacuationReporter, logger, repConfig, true)
+	auctionCellRep := initializeAuctionCellRep(executorClient, evacuationReporter, cordonReporter, resourceCeiling, repConfig)
+	stateTracker := auctioncellrep.NewStateTracker(logger, auctionCellRep, executorClient, clock, time.Duration(repConfig.StateMaxAge))
 	opGenerator := generator.New(
 		repConfig.CellID,
 		bbsClient,
 		executorClient,
 		evacuationReporter,
 		uint64(time.Duration(repConfig.EvacuationTimeout).Seconds()),
+		handoffs,
 	)
 	cleanup := evacuation.NewEvacuationCleanup(logger, repConfig.CellID, bbsClient, executorClient, clock, metronClient)
 
@@ -167,14 +190,38 @@ func main() {
 		queue,
 		metronClient,
 	)
+	eventConsumer := harmonizer.NewEventConsumer(logger, opGenerator, queue)
+
+	healthChecker := initializeHealthChecker(bbsClient, executorClient, serviceClient, locketClient, evacuationReporter, cordonReporter, eventConsumer, bulker, clock, repConfig, serverAddress(logger, repConfig, false), presenceOwner)
+	httpServer, address := initializeServer(bbsClient, executorClient, evacuatable, evacuator, evacuator, cordonable, healthChecker, stateTracker, logger, repConfig, false)
+	httpsServer, _ := initializeServer(bbsClient, executorClient, evacuatable, evacuator, evacuator, cordonable, healthChecker, stateTracker, logger, repConfig, true)
+
+	presence := initializeCellPresence(address, serviceClient, locketClient, executorClient, logger, repConfig, presenceOwner, true)
+
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	placementReloader := reloader.New(
+		logger,
+		clock,
+		*configFilePath,
+		time.Duration(repConfig.ReloadInterval),
+		reloadSignals,
+		placement(repConfig),
+		loadPlacement,
+		auctionCellRep,
+		stateTracker,
+		presence,
+	)
 
 	members := grouper.Members{
-		{"presence", initializeCellPresence(address, serviceClient, executorClient, logger, repConfig, preloadedRootFSes, true)},
+		{"presence", presence},
+		{"reloader", placementReloader},
+		{"state-tracker", stateTracker},
 		{"http_server", httpServer},
 		{"https_server", httpsServer},
 		{"evacuation-cleanup", cleanup},
 		{"bulker", bulker},
-		{"event-consumer", harmonizer.NewEventConsumer(logger, opGenerator, queue)},
+		{"event-consumer", eventConsumer},
 		{"evacuator", evacuator},
 		{"registration-runner", registrationRunner},
 	}
@@ -213,12 +260,13 @@ func initializeDropsonde(logger lager.Logger, dropsondePort int) {
 func initializeCellPresence(
 	address string,
 	serviceClient maintain.CellPresenceClient,
+	locketClient locketmodels.LocketClient,
 	executorClient executor.Client,
 	logger lager.Logger,
 	repConfig config.RepConfig,
-	preloadedRootFSes []string,
+	owner string,
 	secure bool,
-) ifrit.Runner {
+) maintain.PresenceRunner {
 	var repUrl string
 	port := strings.Split(repConfig.ListenAddrSecurable, ":")[1]
 	if secure && repConfig.RequireTLS {
@@ -227,82 +275,135 @@ func initializeCellPresence(
 		repUrl = fmt.Sprintf("http://%s:%s", repURL(repConfig.CellID, repConfig.AdvertiseDomain), port)
 	}
 
-	if repConfig.LocketAddress != "" {
-		locketClient, err := locket.NewClient(logger, repConfig.ClientLocketConfig)
-		if err != nil {
-			logger.Fatal("failed-to-construct-locket-client", err)
-		}
+	config := maintain.Config{
+		CellID:                repConfig.CellID,
+		RepAddress:            address,
+		RepUrl:                repUrl,
+		Zone:                  repConfig.Zone,
+		RetryInterval:         time.Duration(repConfig.LockRetryInterval),
+		RootFSProviders:       repConfig.SupportedProviders,
+		PreloadedRootFSes:     rep.StackPathMap(repConfig.PreloadedRootFS).Stacks(),
+		PlacementTags:         repConfig.PlacementTags,
+		OptionalPlacementTags: repConfig.OptionalPlacementTags,
+		Labels:                repConfig.Labels,
+		Topology:              repConfig.Topology,
+	}
 
-		guid, err := uuid.NewV4()
-		if err != nil {
-			logger.Fatal("failed-to-generate-guid", err)
-		}
+	if locketClient != nil {
+		config.RetryInterval = locket.RetryInterval
+		return maintain.NewLocketPresence(
+			logger,
+			config,
+			executorClient,
+			locketClient,
+			owner,
+			time.Duration(repConfig.LockTTL),
+			clock.NewClock(),
+		)
+	}
 
-		resources, err := executorClient.TotalResources(logger)
-		if err != nil {
-			logger.Fatal("failed-to-get-total-resources", err)
-		}
-		cellCapacity := models.NewCellCapacity(int32(resources.MemoryMB), int32(resources.DiskMB), int32(resources.Containers))
-		cellPresence := models.NewCellPresence(repConfig.CellID, address, repUrl,
-			repConfig.Zone, cellCapacity, repConfig.SupportedProviders,
-			preloadedRootFSes, repConfig.PlacementTags, repConfig.OptionalPlacementTags)
+	return maintain.New(
+		logger,
+		config,
+		executorClient,
+		serviceClient,
+		time.Duration(repConfig.LockTTL),
+		clock.NewClock(),
+	)
+}
+
+// initializeLocketClient returns the client the presence is maintained with,
+// or nil when the rep maintains its presence in consul.
+func initializeLocketClient(logger lager.Logger, repConfig config.RepConfig) locketmodels.LocketClient {
+	if repConfig.LocketAddress == "" {
+		return nil
+	}
+
+	locketClient, err := locket.NewClient(logger, repConfig.ClientLocketConfig)
+	if err != nil {
+		logger.Fatal("failed-to-construct-locket-client", err)
+	}
+	return locketClient
+}
+
+func overcommit(repConfig config.RepConfig) rep.Overcommit {
+	return rep.Overcommit{
+		MemoryRatio:      repConfig.MemoryOvercommitRatio,
+		DiskRatio:        repConfig.DiskOvercommitRatio,
+		ReservedMemoryMB: int32(repConfig.ReservedMemoryMB),
+		ReservedDiskMB:   int32(repConfig.ReservedDiskMB),
+	}
+}
+
+// overcommitExecutorCapacity configures the executor to allocate against the
+// overcommitted capacity, so that the executor accepts the work the cell
+// advertises and refuses anything beyond it. It returns the physical limit on
+// a single container, or nil when nothing is overcommitted. The physical
+// capacity of an overcommitted resource must be configured, not "auto", and
+// a ratio, when set, must be at least 1.
+func overcommitExecutorCapacity(repConfig *config.RepConfig) (*rep.Resources, error) {
+	o := overcommit(*repConfig)
+	if o == (rep.Overcommit{}) {
+		return nil, nil
+	}
+	if o.MemoryRatio != 0 && o.MemoryRatio < 1 {
+		return nil, fmt.Errorf("memory_overcommit_ratio must be at least 1: %v", o.MemoryRatio)
+	}
+	if o.DiskRatio != 0 && o.DiskRatio < 1 {
+		return nil, fmt.Errorf("disk_overcommit_ratio must be at least 1: %v", o.DiskRatio)
+	}
 
-		payload, err := json.Marshal(cellPresence)
+	ceiling := rep.Resources{}
+	if o.MemoryRatio != 0 || o.ReservedMemoryMB != 0 {
+		memoryMB, err := strconv.Atoi(repConfig.MemoryMB)
 		if err != nil {
-			logger.Fatal("failed-to-encode-cell-presence", err)
+			return nil, fmt.Errorf("memory_mb must be a number to overcommit memory: %q", repConfig.MemoryMB)
 		}
-
-		lockPayload := &locketmodels.Resource{
-			Key:      repConfig.CellID,
-			Owner:    guid.String(),
-			Value:    string(payload),
-			TypeCode: locketmodels.PRESENCE,
-			Type:     locketmodels.PresenceType,
+		physical := rep.Resources{MemoryMB: int32(memoryMB)}
+		ceiling.MemoryMB = o.Ceiling(physical).MemoryMB
+		repConfig.MemoryMB = strconv.Itoa(int(o.Total(physical).MemoryMB))
+	}
+	if o.DiskRatio != 0 || o.ReservedDiskMB != 0 {
+		diskMB, err := strconv.Atoi(repConfig.DiskMB)
+		if err != nil {
+			return nil, fmt.Errorf("disk_mb must be a number to overcommit disk: %q", repConfig.DiskMB)
 		}
+		physical := rep.Resources{DiskMB: int32(diskMB)}
+		ceiling.DiskMB = o.Ceiling(physical).DiskMB
+		repConfig.DiskMB = strconv.Itoa(int(o.Total(physical).DiskMB))
+	}
+	return &ceiling, nil
+}
 
-		logger.Debug("presence-payload", lager.Data{"payload": lockPayload})
-		return lock.NewPresenceRunner(
-			logger,
-			locketClient,
-			lockPayload,
-			int64(time.Duration(repConfig.LockTTL)/time.Second),
-			clock.NewClock(),
-			locket.RetryInterval,
-		)
-	} else {
-		config := maintain.Config{
-			CellID:                repConfig.CellID,
-			RepAddress:            address,
-			RepUrl:                repUrl,
-			Zone:                  repConfig.Zone,
-			RetryInterval:         time.Duration(repConfig.LockRetryInterval),
-			RootFSProviders:       repConfig.SupportedProviders,
-			PreloadedRootFSes:     preloadedRootFSes,
-			PlacementTags:         repConfig.PlacementTags,
-			OptionalPlacementTags: repConfig.OptionalPlacementTags,
-		}
+func placement(repConfig config.RepConfig) reloader.Placement {
+	return reloader.Placement{
+		PreloadedRootFS:       rep.StackPathMap(repConfig.PreloadedRootFS),
+		PlacementTags:         repConfig.PlacementTags,
+		OptionalPlacementTags: repConfig.OptionalPlacementTags,
+	}
+}
 
-		return maintain.New(
-			logger,
-			config,
-			executorClient,
-			serviceClient,
-			time.Duration(repConfig.LockTTL),
-			clock.NewClock(),
-		)
+func loadPlacement() (reloader.Placement, error) {
+	repConfig, err := config.NewRepConfig(*configFilePath)
+	if err != nil {
+		return reloader.Placement{}, err
 	}
+	return placement(repConfig), nil
 }
 
-func initializeServer(
-	bbsClient bbs.InternalClient,
+func initializeAuctionCellRep(
 	executorClient executor.Client,
-	evacuatable evacuation_context.Evacuatable,
 	evacuationReporter evacuation_context.EvacuationReporter,
-	logger lager.Logger,
+	cordonReporter evacuation_context.CordonReporter,
+	resourceCeiling *rep.Resources,
 	repConfig config.RepConfig,
-	secure bool,
-) (ifrit.Runner, string) {
-	auctionCellRep := auctioncellrep.New(
+) *auctioncellrep.AuctionCellRep {
+	var usageSampler *auctioncellrep.UsageSampler
+	if repConfig.ReportContainerUsage {
+		usageSampler = auctioncellrep.NewUsageSampler(clock.NewClock(), runtime.NumCPU())
+	}
+
+	return auctioncellrep.New(
 		repConfig.CellID,
 		rep.StackPathMap(repConfig.PreloadedRootFS),
 		repConfig.SupportedProviders,
@@ -310,11 +411,35 @@ func initializeServer(
 		auctioncellrep.GenerateGuid,
 		executorClient,
 		evacuationReporter,
+		cordonReporter,
 		repConfig.PlacementTags,
 		repConfig.OptionalPlacementTags,
+		int32(repConfig.MaxPidsCapacity),
+		int32(repConfig.CPUWeightCapacity),
+		repConfig.Labels,
+		resourceCeiling,
+		repConfig.ExtendedResources,
+		usageSampler,
+		repConfig.Topology,
+		repConfig.RootFSPatterns,
+		!repConfig.HideRootFSPatterns,
 	)
+}
 
-	handlers := getHandlers(logger, auctionCellRep, executorClient, evacuatable, repConfig.EnableLegacyAPIServer, secure)
+func initializeServer(
+	bbsClient bbs.InternalClient,
+	executorClient executor.Client,
+	evacuatable evacuation_context.Evacuatable,
+	evacuationStatusReporter evacuation_context.EvacuationStatusReporter,
+	evacuationAborter evacuation_context.EvacuationAborter,
+	cordonable evacuation_context.Cordonable,
+	healthReporter health.Reporter,
+	auctionCellRep auctioncellrep.AuctionCellClient,
+	logger lager.Logger,
+	repConfig config.RepConfig,
+	secure bool,
+) (ifrit.Runner, string) {
+	handlers := getHandlers(logger, auctionCellRep, executorClient, evacuatable, evacuationStatusReporter, evacuationAborter, cordonable, healthReporter, repConfig.EnableLegacyAPIServer, secure)
 	routes := getRoutes(repConfig.EnableLegacyAPIServer, secure)
 	router, err := rata.NewRouter(routes, handlers)
 
@@ -322,6 +447,31 @@ func initializeServer(
 		logger.Fatal("failed-to-construct-router", err)
 	}
 
+	listenAddress := repConfig.ListenAddr
+	if secure {
+		listenAddress = repConfig.ListenAddrSecurable
+	}
+	address := serverAddress(logger, repConfig, secure)
+
+	if secure && repConfig.RequireTLS {
+		reloader, err := rep.NewCertificateReloader(
+			logger,
+			clock.NewClock(),
+			repConfig.ServerCertFile,
+			repConfig.ServerKeyFile,
+			repConfig.CaCertFile,
+			time.Duration(repConfig.CertReloadInterval),
+		)
+		if err != nil {
+			logger.Fatal("tls-configuration-failed", err)
+		}
+		return http_server.NewTLSServer(listenAddress, router, reloader.ServerTLSConfig()), address
+	}
+
+	return http_server.New(listenAddress, router), address
+}
+
+func serverAddress(logger lager.Logger, repConfig config.RepConfig, secure bool) string {
 	ip, err := localip.LocalIP()
 	if err != nil {
 		logger.Fatal("failed-to-fetch-ip", err)
@@ -332,18 +482,11 @@ func initializeServer(
 		listenAddress = repConfig.ListenAddrSecurable
 	}
 	port := strings.Split(listenAddress, ":")[1]
-	address := fmt.Sprintf("http://%s:%s", ip, port)
 
 	if secure && repConfig.RequireTLS {
-		tlsConfig, err := cfhttp.NewTLSConfig(repConfig.ServerCertFile, repConfig.ServerKeyFile, repConfig.CaCertFile)
-		if err != nil {
-			logger.Fatal("tls-configuration-failed", err)
-		}
-		address = fmt.Sprintf("https://%s:%s", ip, port)
-		return http_server.NewTLSServer(listenAddress, router, tlsConfig), address
+		return fmt.Sprintf("https://%s:%s", ip, port)
 	}
-
-	return http_server.New(listenAddress, router), address
+	return fmt.Sprintf("http://%s:%s", ip, port)
 }
 
 func getHandlers(
@@ -351,14 +494,18 @@ func getHandlers(
 	auctionCellRep auctioncellrep.AuctionCellClient,
 	executorClient executor.Client,
 	evacuatable evacuation_context.Evacuatable,
+	evacuationStatusReporter evacuation_context.EvacuationStatusReporter,
+	evacuationAborter evacuation_context.EvacuationAborter,
+	cordonable evacuation_context.Cordonable,
+	healthReporter health.Reporter,
 	enableLegacyAPIServer bool,
 	isSecureServer bool,
 ) rata.Handlers {
 
 	if enableLegacyAPIServer && !isSecureServer {
-		return handlers.NewLegacy(auctionCellRep, executorClient, evacuatable, logger)
+		return handlers.NewLegacy(auctionCellRep, executorClient, evacuatable, evacuationStatusReporter, evacuationAborter, cordonable, healthReporter, logger)
 	}
-	return handlers.New(auctionCellRep, executorClient, evacuatable, logger, isSecureServer)
+	return handlers.New(auctionCellRep, executorClient, evacuatable, evacuationStatusReporter, evacuationAborter, cordonable, healthReporter, logger, isSecureServer)
 }
 
 func getRoutes(enableLegacyAPIServer, isSecureServer bool) rata.Routes {
@@ -368,6 +515,47 @@ func getRoutes(enableLegacyAPIServer, isSecureServer bool) rata.Routes {
 	return rep.NewRoutes(isSecureServer)
 }
 
+// initializeHealthChecker builds the checks behind the health and ready
+// routes. Only the executor decides whether the rep is alive; the cell is
+// ready for work once every dependency is healthy and it is neither
+// evacuating nor cordoned.
+func initializeHealthChecker(
+	bbsClient bbs.InternalClient,
+	executorClient executor.Client,
+	serviceClient maintain.CellPresenceClient,
+	locketClient locketmodels.LocketClient,
+	evacuationReporter evacuation_context.EvacuationReporter,
+	cordonReporter evacuation_context.CordonReporter,
+	eventConsumer *harmonizer.EventConsumer,
+	bulker *harmonizer.Bulker,
+	clock clock.Clock,
+	repConfig config.RepConfig,
+	address string,
+	presenceOwner string,
+) *health.Checker {
+	presenceCheck := health.ConsulPresenceCheck(serviceClient, repConfig.CellID, address)
+	if locketClient != nil {
+		// the check uses the client the presence is maintained with, so that
+		// it reports on the connection the rep relies on
+		presenceCheck = health.LocketPresenceCheck(locketClient, repConfig.CellID, presenceOwner, time.Duration(repConfig.HealthCheckTimeout))
+	}
+
+	// the bulker may be a poll interval late before it is considered stuck
+	bulkSyncMaxAge := 2 * time.Duration(repConfig.PollingInterval)
+
+	return health.NewChecker(
+		clock,
+		time.Duration(repConfig.HealthCheckTimeout),
+		health.Component{Name: "executor", Check: health.ExecutorCheck(executorClient), Liveness: true},
+		health.Component{Name: "bbs", Check: health.BBSCheck(bbsClient)},
+		health.Component{Name: "presence", Check: presenceCheck},
+		health.Component{Name: "event-stream", Check: health.EventStreamCheck(eventConsumer)},
+		health.Component{Name: "bulk-sync", Check: health.BulkSyncCheck(clock, bulker, bulkSyncMaxAge), LastSuccess: bulker.LastSync},
+		health.Component{Name: "evacuation", Check: health.EvacuationCheck(evacuationReporter)},
+		health.Component{Name: "cordon", Check: health.CordonCheck(cordonReporter)},
+	)
+}
+
 func initializeBBSClient(
 	logger lager.Logger,
 	repConfig config.RepConfig,
@@ -34,6 +34,7 @@ import (
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/cmd/rep/config"
 	"code.cloudfoundry.org/rep/cmd/rep/testrunner"
+	"code.cloudfoundry.org/rep/health"
 
 	"github.com/hashicorp/consul/api"
 	. "github.com/onsi/ginkgo"
@@ -259,6 +260,20 @@ dYbCU/DMZjsv+Pt9flhj7ELLo+WKHyI767hJSq9A7IT3GzFt8iGiEAt1qj2yS0DX
 			})
 		})
 
+		Context("when an overcommit ratio is below 1", func() {
+			BeforeEach(func() {
+				repConfig.MemoryMB = "1024"
+				repConfig.MemoryOvercommitRatio = 0.5
+				runner = testrunner.New(representativePath, repConfig)
+				runner.StartCheck = ""
+			})
+
+			It("refuses to start", func() {
+				Eventually(runner.Session.Buffer()).Should(gbytes.Say("invalid-overcommit-configuration"))
+				Eventually(runner.Session.ExitCode).Should(Equal(1))
+			})
+		})
+
 		Context("when starting", func() {
 			var deleteChan chan struct{}
 			BeforeEach(func() {
@@ -433,6 +448,35 @@ dYbCU/DMZjsv+Pt9flhj7ELLo+WKHyI767hJSq9A7IT3GzFt8iGiEAt1qj2yS0DX
 					})
 				})
 			})
+
+			Context("when overcommit is configured", func() {
+				BeforeEach(func() {
+					repConfig.MemoryMB = "128"
+					repConfig.DiskMB = "1040"
+					repConfig.MemoryOvercommitRatio = 2
+					repConfig.DiskOvercommitRatio = 1.5
+					repConfig.ReservedMemoryMB = 28
+					repConfig.ReservedDiskMB = 24
+
+					runner = testrunner.New(representativePath, repConfig)
+				})
+
+				It("advertises the overcommitted capacity", func() {
+					state, err := client.State(logger)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(state.TotalResources).To(Equal(rep.Resources{
+						MemoryMB:   200,
+						DiskMB:     1524,
+						Containers: 3,
+					}))
+					Expect(state.ResourceCeiling).To(Equal(&rep.Resources{MemoryMB: 100, DiskMB: 1016}))
+
+					cells, err := bbsClient.Cells(logger)
+					Expect(err).NotTo(HaveOccurred())
+					cellPresence := models.NewCellSetFromList(cells)[cellID]
+					Expect(*cellPresence.Capacity).To(Equal(models.NewCellCapacity(200, 1524, 3)))
+				})
+			})
 		})
 
 		Describe("polling the BBS for tasks to reap", func() {
@@ -521,6 +565,35 @@ dYbCU/DMZjsv+Pt9flhj7ELLo+WKHyI767hJSq9A7IT3GzFt8iGiEAt1qj2yS0DX
 			})
 		})
 
+		Describe("when a Health request comes in", func() {
+			getReport := func(path string) (int, health.Report) {
+				resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", serverPort, path))
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+
+				var report health.Report
+				Expect(json.NewDecoder(resp.Body).Decode(&report)).To(Succeed())
+				return resp.StatusCode, report
+			}
+
+			It("responds with 200 OK while the executor is healthy", func() {
+				Eventually(func() int {
+					code, _ := getReport("/health")
+					return code
+				}).Should(Equal(http.StatusOK))
+			})
+
+			It("reports each component of the rep", func() {
+				_, report := getReport("/ready")
+
+				names := []string{}
+				for _, component := range report.Components {
+					names = append(names, component.Name)
+				}
+				Expect(names).To(ConsistOf("executor", "bbs", "presence", "event-stream", "bulk-sync", "evacuation", "cordon"))
+			})
+		})
+
 		Describe("ServiceRegistration", func() {
 			It("registers itself with consul", func() {
 				consulClient := consulRunner.NewClient()
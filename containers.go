@@ -0,0 +1,106 @@
+package rep
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"code.cloudfoundry.org/executor"
+)
+
+// Query parameters of the containers route.
+const (
+	ContainerLifecycleParam   = "lifecycle"
+	ContainerDomainParam      = "domain"
+	ContainerProcessGuidParam = "process_guid"
+	ContainerStateParam       = "state"
+)
+
+var ErrContainerNotFound = errors.New("container not found")
+
+// ContainerInfo describes a container on the cell in more detail than the
+// CellState, for operators inspecting the cell. AllocatedAt, in nanoseconds
+// since the epoch, is the only timestamp the executor keeps for a container;
+// when it was created or started running is not recorded anywhere the rep can
+// read it.
+type ContainerInfo struct {
+	Guid         string                      `json:"guid"`
+	State        executor.State              `json:"state"`
+	Lifecycle    string                      `json:"lifecycle,omitempty"`
+	Domain       string                      `json:"domain,omitempty"`
+	ProcessGuid  string                      `json:"process_guid,omitempty"`
+	InstanceGuid string                      `json:"instance_guid,omitempty"`
+	Index        *int32                      `json:"index,omitempty"`
+	Tags         executor.Tags               `json:"tags,omitempty"`
+	MemoryMB     int                         `json:"memory_mb"`
+	DiskMB       int                         `json:"disk_mb"`
+	MaxPids      int                         `json:"max_pids"`
+	RootFSPath   string                      `json:"rootfs,omitempty"`
+	RunResult    executor.ContainerRunResult `json:"run_result"`
+	AllocatedAt  int64                       `json:"allocated_at"`
+}
+
+func NewContainerInfo(container executor.Container) ContainerInfo {
+	info := ContainerInfo{
+		Guid:        container.Guid,
+		State:       container.State,
+		Tags:        container.Tags,
+		MemoryMB:    container.MemoryMB,
+		DiskMB:      container.DiskMB,
+		MaxPids:     container.MaxPids,
+		RootFSPath:  container.RootFSPath,
+		RunResult:   container.RunResult,
+		AllocatedAt: container.AllocatedAt,
+	}
+
+	if container.Tags != nil {
+		info.Lifecycle = container.Tags[LifecycleTag]
+		info.Domain = container.Tags[DomainTag]
+		info.ProcessGuid = container.Tags[ProcessGuidTag]
+		info.InstanceGuid = container.Tags[InstanceGuidTag]
+		if index, err := strconv.Atoi(container.Tags[ProcessIndexTag]); err == nil {
+			index32 := int32(index)
+			info.Index = &index32
+		}
+	}
+
+	return info
+}
+
+// ContainerFilter selects containers by the fields that are set.
+type ContainerFilter struct {
+	Lifecycle   string
+	Domain      string
+	ProcessGuid string
+	State       executor.State
+}
+
+func NewContainerFilter(query url.Values) ContainerFilter {
+	return ContainerFilter{
+		Lifecycle:   query.Get(ContainerLifecycleParam),
+		Domain:      query.Get(ContainerDomainParam),
+		ProcessGuid: query.Get(ContainerProcessGuidParam),
+		State:       executor.State(query.Get(ContainerStateParam)),
+	}
+}
+
+func (f ContainerFilter) Query() url.Values {
+	query := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	set(ContainerLifecycleParam, f.Lifecycle)
+	set(ContainerDomainParam, f.Domain)
+	set(ContainerProcessGuidParam, f.ProcessGuid)
+	set(ContainerStateParam, string(f.State))
+	return query
+}
+
+func (f ContainerFilter) Matches(info ContainerInfo) bool {
+	return (f.Lifecycle == "" || f.Lifecycle == info.Lifecycle) &&
+		(f.Domain == "" || f.Domain == info.Domain) &&
+		(f.ProcessGuid == "" || f.ProcessGuid == info.ProcessGuid) &&
+		(f.State == "" || f.State == info.State)
+}
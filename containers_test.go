@@ -0,0 +1,80 @@
+package rep_test
+
+import (
+	"net/url"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Containers", func() {
+	var container executor.Container
+
+	BeforeEach(func() {
+		container = executor.Container{
+			Guid:        "container-guid",
+			State:       executor.StateRunning,
+			AllocatedAt: 1234,
+			Tags: executor.Tags{
+				rep.LifecycleTag:    rep.LRPLifecycle,
+				rep.DomainTag:       "cf-apps",
+				rep.ProcessGuidTag:  "process-guid",
+				rep.InstanceGuidTag: "instance-guid",
+				rep.ProcessIndexTag: "2",
+			},
+		}
+		container.MemoryMB = 128
+		container.DiskMB = 256
+		container.RootFSPath = "/path/to/rootfs"
+	})
+
+	Describe("NewContainerInfo", func() {
+		It("extracts the lifecycle details from the tags", func() {
+			info := rep.NewContainerInfo(container)
+			Expect(info.Guid).To(Equal("container-guid"))
+			Expect(info.State).To(Equal(executor.StateRunning))
+			Expect(info.Lifecycle).To(Equal(rep.LRPLifecycle))
+			Expect(info.Domain).To(Equal("cf-apps"))
+			Expect(info.ProcessGuid).To(Equal("process-guid"))
+			Expect(info.InstanceGuid).To(Equal("instance-guid"))
+			Expect(*info.Index).To(BeEquivalentTo(2))
+			Expect(info.MemoryMB).To(Equal(128))
+			Expect(info.DiskMB).To(Equal(256))
+			Expect(info.RootFSPath).To(Equal("/path/to/rootfs"))
+			Expect(info.AllocatedAt).To(BeEquivalentTo(1234))
+		})
+
+		It("copes with containers without tags", func() {
+			container.Tags = nil
+			info := rep.NewContainerInfo(container)
+			Expect(info.Lifecycle).To(BeEmpty())
+			Expect(info.Index).To(BeNil())
+		})
+	})
+
+	Describe("ContainerFilter", func() {
+		It("matches everything when empty", func() {
+			Expect(rep.ContainerFilter{}.Matches(rep.NewContainerInfo(container))).To(BeTrue())
+		})
+
+		It("requires every field that is set to match", func() {
+			info := rep.NewContainerInfo(container)
+			Expect(rep.ContainerFilter{Domain: "cf-apps", State: executor.StateRunning}.Matches(info)).To(BeTrue())
+			Expect(rep.ContainerFilter{Domain: "cf-apps", State: executor.StateCompleted}.Matches(info)).To(BeFalse())
+			Expect(rep.ContainerFilter{ProcessGuid: "other-guid"}.Matches(info)).To(BeFalse())
+			Expect(rep.ContainerFilter{Lifecycle: rep.TaskLifecycle}.Matches(info)).To(BeFalse())
+		})
+
+		It("round trips through a query", func() {
+			filter := rep.ContainerFilter{Lifecycle: rep.LRPLifecycle, ProcessGuid: "process-guid"}
+			Expect(filter.Query()).To(Equal(url.Values{
+				rep.ContainerLifecycleParam:   {rep.LRPLifecycle},
+				rep.ContainerProcessGuidParam: {"process-guid"},
+			}))
+			Expect(rep.NewContainerFilter(filter.Query())).To(Equal(filter))
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/executor"
@@ -21,6 +22,9 @@ const (
 	ProcessGuidTag  = "process-guid"
 	InstanceGuidTag = "instance-guid"
 	ProcessIndexTag = "process-index"
+	CPUWeightTag    = "cpu-weight"
+
+	ExtendedResourceTagPrefix = "extended-resource-"
 )
 
 var (
@@ -28,6 +32,34 @@ var (
 	ErrInvalidProcessIndex  = errors.New("container does not have a valid process index")
 )
 
+// AddExtendedResourceTags records the extended resources allocated to a
+// container in its tags.
+func AddExtendedResourceTags(tags executor.Tags, extended map[string]int32) {
+	for name, amount := range extended {
+		if amount > 0 {
+			tags[ExtendedResourceTagPrefix+name] = strconv.Itoa(int(amount))
+		}
+	}
+}
+
+func ExtendedResourcesFromTags(tags executor.Tags) map[string]int32 {
+	var extended map[string]int32
+	for tag, value := range tags {
+		if !strings.HasPrefix(tag, ExtendedResourceTagPrefix) {
+			continue
+		}
+		amount, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		if extended == nil {
+			extended = map[string]int32{}
+		}
+		extended[strings.TrimPrefix(tag, ExtendedResourceTagPrefix)] += int32(amount)
+	}
+	return extended
+}
+
 func ActualLRPKeyFromTags(tags executor.Tags) (*models.ActualLRPKey, error) {
 	if tags == nil {
 		return &models.ActualLRPKey{}, ErrContainerMissingTags
@@ -239,6 +239,26 @@ var _ = Describe("Resources", func() {
 		})
 	})
 
+	Describe("ExtendedResourcesFromTags", func() {
+		It("round trips the extended resources recorded by AddExtendedResourceTags", func() {
+			tags := executor.Tags{rep.LifecycleTag: rep.LRPLifecycle}
+			rep.AddExtendedResourceTags(tags, map[string]int32{"ssd-slots": 1, "licence-seats": 2, "unused": 0})
+
+			Expect(tags).To(HaveKeyWithValue(rep.ExtendedResourceTagPrefix+"ssd-slots", "1"))
+			Expect(tags).NotTo(HaveKey(rep.ExtendedResourceTagPrefix + "unused"))
+			Expect(rep.ExtendedResourcesFromTags(tags)).To(Equal(map[string]int32{"ssd-slots": 1, "licence-seats": 2}))
+		})
+
+		It("returns nil when the container has no extended resources", func() {
+			Expect(rep.ExtendedResourcesFromTags(executor.Tags{rep.LifecycleTag: rep.LRPLifecycle})).To(BeNil())
+		})
+
+		It("ignores malformed amounts", func() {
+			tags := executor.Tags{rep.ExtendedResourceTagPrefix + "ssd-slots": "lots"}
+			Expect(rep.ExtendedResourcesFromTags(tags)).To(BeNil())
+		})
+	})
+
 	Describe("StackPathMap", func() {
 		It("deserializes a valid input", func() {
 			stackMapPayload := []byte(`{
@@ -0,0 +1,59 @@
+package rep
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GzipEncoding is the content coding the state and perform routes accept
+// for requests and responses. Cells advertise it with an Accept-Encoding
+// header on their responses.
+const GzipEncoding = "gzip"
+
+// AcceptsGzip reports whether the value of an Accept-Encoding header allows
+// gzip.
+func AcceptsGzip(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(coding, ";")
+		if name := strings.TrimSpace(params[0]); name != GzipEncoding && name != "*" {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// decodedBody returns the body of a response, decompressing it if the cell
+// gzipped it.
+func decodedBody(resp *http.Response) (io.ReadCloser, error) {
+	if resp.Header.Get("Content-Encoding") != GzipEncoding {
+		return resp.Body, nil
+	}
+	return gzip.NewReader(resp.Body)
+}
+
+func gzipBytes(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
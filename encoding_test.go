@@ -0,0 +1,292 @@
+package rep_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	executorfakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/rep/health/healthfakes"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const benchmarkContainers = 250
+
+// largeCellState builds the state of a cell running the given number of
+// containers, four in five of them lrps.
+func largeCellState(containers int) rep.CellState {
+	lrps := []rep.LRP{}
+	tasks := []rep.Task{}
+	for i := 0; i < containers; i++ {
+		if i%5 == 4 {
+			tasks = append(tasks, *BuildTask(fmt.Sprintf("task-guid-%d", i), "domain", "", 256, 1024, 0, nil))
+			continue
+		}
+		lrps = append(lrps, *BuildLRP(fmt.Sprintf("process-guid-%d", i), "cf-apps", i%3, "", 512, 1024, 1024))
+	}
+
+	return rep.NewCellState(
+		rep.RootFSProviders{"preloaded": rep.NewFixedSetRootFSProvider("cflinuxfs2"), "docker": rep.ArbitraryRootFSProvider{}},
+		rep.NewResources(32768, 65536, 0),
+		rep.NewResources(262144, 524288, 256),
+		lrps,
+		tasks,
+		"z1",
+		3,
+		false,
+		[]string{"nfs"},
+		[]string{"tag"},
+		nil,
+	)
+}
+
+func largeWork(containers int) rep.Work {
+	state := largeCellState(containers)
+	return rep.Work{LRPs: state.LRPs, Tasks: state.Tasks}
+}
+
+// encodingServer serves the secure routes of a cell with the rep's own
+// handlers. The cell reports state, and fails all of the work it is given so
+// that perform responses are as large as their requests.
+type encodingServer struct {
+	*httptest.Server
+	cellClient *auctioncellrepfakes.FakeAuctionCellClient
+
+	lock             sync.Mutex
+	contentEncodings []string
+}
+
+func newEncodingServer(state rep.CellState) (*encodingServer, error) {
+	cellClient := &auctioncellrepfakes.FakeAuctionCellClient{}
+	cellClient.StateReturns(state, true, nil)
+	cellClient.PerformStub = func(_ context.Context, _ lager.Logger, work rep.Work) (rep.Work, error) {
+		return work, nil
+	}
+
+	router, err := rata.NewRouter(rep.RoutesSecure, handlers.New(
+		cellClient,
+		&executorfakes.FakeClient{},
+		&fake_evacuation_context.FakeEvacuatable{},
+		&fake_evacuation_context.FakeEvacuationStatusReporter{},
+		&fake_evacuation_context.FakeEvacuationAborter{},
+		&fake_evacuation_context.FakeCordonable{},
+		&healthfakes.FakeReporter{},
+		lager.NewLogger("encoding"),
+		true,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	server := &encodingServer{cellClient: cellClient}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.lock.Lock()
+		server.contentEncodings = append(server.contentEncodings, r.Header.Get("Content-Encoding"))
+		server.lock.Unlock()
+		router.ServeHTTP(w, r)
+	}))
+	return server, nil
+}
+
+func (s *encodingServer) ContentEncodings() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.contentEncodings...)
+}
+
+// fetch sends an uncompressed request straight to the server and returns the
+// response as it came over the wire, without any decoding.
+func (s *encodingServer) fetch(route string, body []byte, acceptGzip bool) (*http.Response, []byte, error) {
+	req, err := rata.NewRequestGenerator(s.URL, rep.RoutesSecure).CreateRequest(route, nil, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	if acceptGzip {
+		req.Header.Set("Accept-Encoding", rep.GzipEncoding)
+	}
+
+	transport := &http.Transport{DisableCompression: true}
+	defer transport.CloseIdleConnections()
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	payload, err := ioutil.ReadAll(resp.Body)
+	return resp, payload, err
+}
+
+func newEncodingClient(server *encodingServer) (rep.Client, error) {
+	factory, err := rep.NewClientFactory(&http.Client{}, &http.Client{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return factory.CreateClient(server.URL, "")
+}
+
+var _ = Describe("Encoding", func() {
+	Describe("AcceptsGzip", func() {
+		It("accepts gzip among other codings", func() {
+			Expect(rep.AcceptsGzip("gzip")).To(BeTrue())
+			Expect(rep.AcceptsGzip("deflate, gzip;q=0.5")).To(BeTrue())
+			Expect(rep.AcceptsGzip("*")).To(BeTrue())
+		})
+
+		It("refuses gzip when it is missing or ruled out", func() {
+			Expect(rep.AcceptsGzip("")).To(BeFalse())
+			Expect(rep.AcceptsGzip("identity")).To(BeFalse())
+			Expect(rep.AcceptsGzip("gzip;q=0")).To(BeFalse())
+		})
+	})
+
+	Context("on a cell with 250 containers", func() {
+		var (
+			logger *lagertest.TestLogger
+			state  rep.CellState
+			work   rep.Work
+			server *encodingServer
+			client rep.Client
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			state = largeCellState(benchmarkContainers)
+			work = largeWork(benchmarkContainers)
+
+			var err error
+			server, err = newEncodingServer(state)
+			Expect(err).NotTo(HaveOccurred())
+			client, err = newEncodingClient(server)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("shrinks the state and failed work the cell serves to callers that accept gzip", func() {
+			payload, err := json.Marshal(work)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, route := range []string{rep.StateRoute, rep.PerformRoute} {
+				plainResp, plain, err := server.fetch(route, payload, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plainResp.Header.Get("Content-Encoding")).To(BeEmpty())
+
+				compressedResp, compressed, err := server.fetch(route, payload, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(compressedResp.Header.Get("Content-Encoding")).To(Equal(rep.GzipEncoding))
+
+				Expect(len(compressed)).To(BeNumerically("<", len(plain)/4), route)
+			}
+		})
+
+		It("round trips the state through the client", func() {
+			decoded, err := client.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded.LRPs).To(Equal(state.LRPs))
+			Expect(decoded.Tasks).To(Equal(state.Tasks))
+		})
+
+		It("round trips gzipped work through the client once the cell has accepted it", func() {
+			for i := 0; i < 2; i++ {
+				failedWork, err := client.Perform(logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(Equal(work.LRPs))
+				Expect(failedWork.Tasks).To(Equal(work.Tasks))
+
+				_, _, performed := server.cellClient.PerformArgsForCall(i)
+				Expect(performed.LRPs).To(Equal(work.LRPs))
+				Expect(performed.Tasks).To(Equal(work.Tasks))
+			}
+
+			Expect(server.ContentEncodings()).To(Equal([]string{"", rep.GzipEncoding}))
+		})
+	})
+})
+
+// benchmarkEncodings compares fetching a payload from the cell as plain json
+// with fetching it through the client, which negotiates gzip.
+func benchmarkEncodings(b *testing.B, route string, body []byte, viaClient func(rep.Client) error) {
+	server, err := newEncodingServer(largeCellState(benchmarkContainers))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer server.Close()
+
+	client, err := newEncodingClient(server)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	_, plain, err := server.fetch(route, body, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	_, compressed, err := server.fetch(route, body, true)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Logf("json: %d bytes, json+gzip: %d bytes", len(plain), len(compressed))
+
+	b.Run("json", func(b *testing.B) {
+		b.SetBytes(int64(len(plain)))
+		for i := 0; i < b.N; i++ {
+			if _, _, err := server.fetch(route, body, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// the first call teaches the client that the cell accepts gzip
+	if err := viaClient(client); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("gzip", func(b *testing.B) {
+		b.SetBytes(int64(len(compressed)))
+		for i := 0; i < b.N; i++ {
+			if err := viaClient(client); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkCellStateEncoding(b *testing.B) {
+	logger := lager.NewLogger("benchmark")
+	benchmarkEncodings(b, rep.StateRoute, nil, func(client rep.Client) error {
+		_, err := client.State(logger)
+		return err
+	})
+}
+
+func BenchmarkWorkEncoding(b *testing.B) {
+	logger := lager.NewLogger("benchmark")
+	work := largeWork(benchmarkContainers)
+	body, err := json.Marshal(work)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkEncodings(b, rep.PerformRoute, body, func(client rep.Client) error {
+		_, err := client.Perform(logger, work)
+		return err
+	})
+}
@@ -2,11 +2,13 @@ package evacuation
 
 import (
 	"os"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 )
 
@@ -15,9 +17,16 @@ type Evacuator struct {
 	clock              clock.Clock
 	executorClient     executor.Client
 	evacuationNotifier evacuation_context.EvacuationNotifier
+	resumable          evacuation_context.Resumable
 	cellID             string
 	evacuationTimeout  time.Duration
 	pollingInterval    time.Duration
+	handoffs           *evacuation_context.HandoffCounter
+
+	lock      sync.Mutex
+	startedAt time.Time
+	complete  bool
+	finished  bool
 }
 
 func NewEvacuator(
@@ -25,18 +34,22 @@ func NewEvacuator(
 	clock clock.Clock,
 	executorClient executor.Client,
 	evacuationNotifier evacuation_context.EvacuationNotifier,
+	resumable evacuation_context.Resumable,
 	cellID string,
 	evacuationTimeout time.Duration,
 	pollingInterval time.Duration,
+	handoffs *evacuation_context.HandoffCounter,
 ) *Evacuator {
 	return &Evacuator{
 		logger:             logger,
 		clock:              clock,
 		executorClient:     executorClient,
 		evacuationNotifier: evacuationNotifier,
+		resumable:          resumable,
 		cellID:             cellID,
 		evacuationTimeout:  evacuationTimeout,
 		pollingInterval:    pollingInterval,
+		handoffs:           handoffs,
 	}
 }
 
@@ -48,37 +61,106 @@ func (e *Evacuator) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	evacuationNotify := e.evacuationNotifier.EvacuateNotify()
 	close(ready)
 
-	select {
-	case signal := <-signals:
-		logger.Info("signaled", lager.Data{"signal": signal.String()})
-		return nil
-	case <-evacuationNotify:
-		evacuationNotify = nil
-		logger.Info("notified-of-evacuation")
+	for {
+		select {
+		case signal := <-signals:
+			logger.Info("signaled", lager.Data{"signal": signal.String()})
+			return nil
+		case <-evacuationNotify:
+			logger.Info("notified-of-evacuation")
+		}
+
+		resumeNotify := e.evacuationNotifier.ResumeNotify()
+
+		e.lock.Lock()
+		e.startedAt = e.clock.Now()
+		e.complete = false
+		e.lock.Unlock()
+
+		if !e.runEvacuation(logger, signals, resumeNotify) {
+			return nil
+		}
+
+		logger.Info("evacuation-aborted")
+		e.lock.Lock()
+		e.startedAt = time.Time{}
+		e.complete = false
+		e.lock.Unlock()
+
+		evacuationNotify = e.evacuationNotifier.EvacuateNotify()
 	}
+}
 
+// runEvacuation waits for the cell to be evacuated, for the evacuation to
+// time out or for it to be aborted, and reports whether it was aborted.
+func (e *Evacuator) runEvacuation(logger lager.Logger, signals <-chan os.Signal, resumeNotify <-chan struct{}) bool {
 	timer := e.clock.NewTimer(e.evacuationTimeout)
 	defer timer.Stop()
 
 	doneCh := make(chan struct{})
-	go e.evacuate(logger, doneCh)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go e.evacuate(logger, doneCh, stopCh)
 
 	select {
 	case <-doneCh:
+		if !e.finish(resumeNotify) {
+			return true
+		}
 		logger.Info("evacuation-complete")
-		return nil
+		return false
 	case <-timer.C():
+		if !e.finish(resumeNotify) {
+			return true
+		}
 		logger.Error("failed-to-evacuate-before-timeout", nil)
-		return nil
+		return false
+	case <-resumeNotify:
+		return true
 	case signal := <-signals:
 		logger.Info("signaled", lager.Data{"signal": signal.String()})
-		return nil
+		return false
+	}
+}
+
+// finish marks the evacuation as finished, after which it can no longer be
+// aborted, unless it has just been aborted.
+func (e *Evacuator) finish(resumeNotify <-chan struct{}) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	select {
+	case <-resumeNotify:
+		return false
+	default:
+		e.finished = true
+		return true
+	}
+}
+
+// AbortEvacuation returns the cell to normal operation, as long as the
+// evacuation has neither completed nor timed out.
+func (e *Evacuator) AbortEvacuation(logger lager.Logger) error {
+	logger = logger.Session("abort-evacuation")
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.finished {
+		logger.Info("evacuation-already-finished")
+		return evacuation_context.ErrEvacuationFinished
 	}
 
+	if !e.resumable.Resume() {
+		logger.Info("not-evacuating")
+		return evacuation_context.ErrNotEvacuating
+	}
+
+	logger.Info("aborted")
 	return nil
 }
 
-func (e *Evacuator) evacuate(logger lager.Logger, doneCh chan<- struct{}) {
+func (e *Evacuator) evacuate(logger lager.Logger, doneCh chan<- struct{}, stopCh <-chan struct{}) {
 	logger = logger.Session("evacuating")
 	logger.Info("started")
 
@@ -91,10 +173,19 @@ func (e *Evacuator) evacuate(logger lager.Logger, doneCh chan<- struct{}) {
 		if !evacuated {
 			logger.Info("evacuation-incomplete", lager.Data{"polling-interval": e.pollingInterval})
 			timer.Reset(e.pollingInterval)
-			<-timer.C()
+			select {
+			case <-timer.C():
+			case <-stopCh:
+				logger.Info("stopped")
+				return
+			}
 			continue
 		}
 
+		e.lock.Lock()
+		e.complete = true
+		e.lock.Unlock()
+
 		close(doneCh)
 		logger.Info("succeeded")
 
@@ -111,3 +202,46 @@ func (e *Evacuator) allContainersEvacuated(logger lager.Logger) bool {
 
 	return len(containers) == 0
 }
+
+// EvacuationStatus reports when the evacuation started, when it times out
+// and which containers are still left on the cell.
+func (e *Evacuator) EvacuationStatus(logger lager.Logger) evacuation_context.EvacuationStatus {
+	logger = logger.Session("evacuation-status")
+
+	e.lock.Lock()
+	startedAt := e.startedAt
+	complete := e.complete
+	e.lock.Unlock()
+
+	status := evacuation_context.EvacuationStatus{
+		Active:         !startedAt.IsZero(),
+		Complete:       complete,
+		RemainingLRPs:  map[string]int{},
+		RemainingTasks: map[string]int{},
+		HandedOffLRPs:  e.handoffs.Handoffs(),
+	}
+
+	if status.Active {
+		deadline := startedAt.Add(e.evacuationTimeout)
+		status.StartedAt = &startedAt
+		status.Deadline = &deadline
+	}
+
+	containers, err := e.executorClient.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		status.Error = err.Error()
+		return status
+	}
+
+	for _, container := range containers {
+		switch container.Tags[rep.LifecycleTag] {
+		case rep.LRPLifecycle:
+			status.RemainingLRPs[string(container.State)]++
+		case rep.TaskLifecycle:
+			status.RemainingTasks[string(container.State)]++
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,44 @@
+package evacuation_context
+
+import "sync"
+
+//go:generate counterfeiter -o fake_evacuation_context/fake_cordonable.go . Cordonable
+type Cordonable interface {
+	Cordon()
+	Uncordon()
+}
+
+//go:generate counterfeiter -o fake_evacuation_context/fake_cordon_reporter.go . CordonReporter
+type CordonReporter interface {
+	Cordoned() bool
+}
+
+// cordonContext is the reversible counterpart of evacuationContext: a
+// cordoned cell takes no new work, but its containers keep running.
+type cordonContext struct {
+	cordoned bool
+	mu       sync.RWMutex
+}
+
+func NewCordon() (Cordonable, CordonReporter) {
+	cordonContext := &cordonContext{}
+	return cordonContext, cordonContext
+}
+
+func (c *cordonContext) Cordon() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cordoned = true
+}
+
+func (c *cordonContext) Uncordon() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cordoned = false
+}
+
+func (c *cordonContext) Cordoned() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cordoned
+}
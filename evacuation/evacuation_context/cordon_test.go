@@ -0,0 +1,34 @@
+package evacuation_context_test
+
+import (
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cordon", func() {
+	var (
+		cordonable     evacuation_context.Cordonable
+		cordonReporter evacuation_context.CordonReporter
+	)
+
+	BeforeEach(func() {
+		cordonable, cordonReporter = evacuation_context.NewCordon()
+	})
+
+	It("is not cordoned to begin with", func() {
+		Expect(cordonReporter.Cordoned()).To(BeFalse())
+	})
+
+	It("reports the cell as cordoned after Cordon", func() {
+		cordonable.Cordon()
+		Expect(cordonReporter.Cordoned()).To(BeTrue())
+	})
+
+	It("can be uncordoned again", func() {
+		cordonable.Cordon()
+		cordonable.Uncordon()
+		Expect(cordonReporter.Cordoned()).To(BeFalse())
+	})
+})
@@ -1,12 +1,28 @@
 package evacuation_context
 
-import "sync"
+import (
+	"errors"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+)
+
+var (
+	ErrNotEvacuating      = errors.New("cell is not evacuating")
+	ErrEvacuationFinished = errors.New("evacuation has already finished")
+)
 
 //go:generate counterfeiter -o fake_evacuation_context/fake_evacuatable.go . Evacuatable
 type Evacuatable interface {
 	Evacuate()
 }
 
+//go:generate counterfeiter -o fake_evacuation_context/fake_resumable.go . Resumable
+type Resumable interface {
+	// Resume ends the evacuation in progress and reports whether there was one.
+	Resume() bool
+}
+
 //go:generate counterfeiter -o fake_evacuation_context/fake_evacuation_reporter.go . EvacuationReporter
 type EvacuationReporter interface {
 	Evacuating() bool
@@ -15,19 +31,33 @@ type EvacuationReporter interface {
 //go:generate counterfeiter -o fake_evacuation_context/fake_evacuation_notifier.go . EvacuationNotifier
 type EvacuationNotifier interface {
 	EvacuateNotify() <-chan struct{}
+	// ResumeNotify returns a channel that is closed once the evacuation in
+	// progress is aborted. It is already closed when the cell is not
+	// evacuating.
+	ResumeNotify() <-chan struct{}
+}
+
+//go:generate counterfeiter -o fake_evacuation_context/fake_evacuation_aborter.go . EvacuationAborter
+type EvacuationAborter interface {
+	AbortEvacuation(logger lager.Logger) error
 }
 
 type evacuationContext struct {
 	evacuated chan struct{}
+	resumed   chan struct{}
 	mu        sync.Mutex
 }
 
-func New() (Evacuatable, EvacuationReporter, EvacuationNotifier) {
+func New() (Evacuatable, EvacuationReporter, EvacuationNotifier, Resumable) {
+	resumed := make(chan struct{})
+	close(resumed)
+
 	evacuationContext := &evacuationContext{
 		evacuated: make(chan struct{}),
+		resumed:   resumed,
 	}
 
-	return evacuationContext, evacuationContext, evacuationContext
+	return evacuationContext, evacuationContext, evacuationContext, evacuationContext
 }
 
 func (e *evacuationContext) Evacuate() {
@@ -37,11 +67,29 @@ func (e *evacuationContext) Evacuate() {
 	select {
 	case <-e.evacuated:
 	default:
+		e.resumed = make(chan struct{})
 		close(e.evacuated)
 	}
 }
 
+func (e *evacuationContext) Resume() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	select {
+	case <-e.evacuated:
+		e.evacuated = make(chan struct{})
+		close(e.resumed)
+		return true
+	default:
+		return false
+	}
+}
+
 func (e *evacuationContext) Evacuating() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	select {
 	case <-e.evacuated:
 		return true
@@ -51,5 +99,13 @@ func (e *evacuationContext) Evacuating() bool {
 }
 
 func (e *evacuationContext) EvacuateNotify() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.evacuated
 }
+
+func (e *evacuationContext) ResumeNotify() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.resumed
+}
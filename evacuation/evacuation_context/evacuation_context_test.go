@@ -15,10 +15,11 @@ var _ = Describe("EvacuationContext", func() {
 		evacuatable        evacuation_context.Evacuatable
 		evacuationReporter evacuation_context.EvacuationReporter
 		evacuationNotifier evacuation_context.EvacuationNotifier
+		resumable          evacuation_context.Resumable
 	)
 
 	BeforeEach(func() {
-		evacuatable, evacuationReporter, evacuationNotifier = evacuation_context.New()
+		evacuatable, evacuationReporter, evacuationNotifier, resumable = evacuation_context.New()
 	})
 
 	Describe("Evacuatable", func() {
@@ -64,4 +65,53 @@ var _ = Describe("EvacuationContext", func() {
 			})
 		})
 	})
+
+	Describe("Resumable", func() {
+		Context("when the cell is not evacuating", func() {
+			It("does nothing", func() {
+				Expect(resumable.Resume()).To(BeFalse())
+				Expect(evacuationReporter.Evacuating()).To(BeFalse())
+			})
+
+			It("provides a closed resume channel", func() {
+				Expect(evacuationNotifier.ResumeNotify()).To(BeClosed())
+			})
+		})
+
+		Context("when the cell is evacuating", func() {
+			var evacuateNotify, resumeNotify <-chan struct{}
+
+			BeforeEach(func() {
+				evacuatable.Evacuate()
+				evacuateNotify = evacuationNotifier.EvacuateNotify()
+				resumeNotify = evacuationNotifier.ResumeNotify()
+			})
+
+			It("ends the evacuation", func() {
+				Expect(resumeNotify).NotTo(BeClosed())
+				Expect(resumable.Resume()).To(BeTrue())
+				Expect(evacuationReporter.Evacuating()).To(BeFalse())
+				Expect(resumeNotify).To(BeClosed())
+			})
+
+			It("provides a new evacuation channel", func() {
+				resumable.Resume()
+				Expect(evacuateNotify).To(BeClosed())
+				Expect(evacuationNotifier.EvacuateNotify()).NotTo(BeClosed())
+			})
+
+			It("lets the cell evacuate again", func() {
+				resumable.Resume()
+				evacuatable.Evacuate()
+				Expect(evacuationReporter.Evacuating()).To(BeTrue())
+				Expect(evacuationNotifier.EvacuateNotify()).To(BeClosed())
+				Expect(evacuationNotifier.ResumeNotify()).NotTo(BeClosed())
+			})
+
+			It("only resumes once", func() {
+				Expect(resumable.Resume()).To(BeTrue())
+				Expect(resumable.Resume()).To(BeFalse())
+			})
+		})
+	})
 })
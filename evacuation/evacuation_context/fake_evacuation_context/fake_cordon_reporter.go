@@ -0,0 +1,84 @@
+// This file was generated by counterfeiter
+package fake_evacuation_context
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+type FakeCordonReporter struct {
+	CordonedStub        func() bool
+	cordonedMutex       sync.RWMutex
+	cordonedArgsForCall []struct{}
+	cordonedReturns     struct {
+		result1 bool
+	}
+	cordonedReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCordonReporter) Cordoned() bool {
+	fake.cordonedMutex.Lock()
+	ret, specificReturn := fake.cordonedReturnsOnCall[len(fake.cordonedArgsForCall)]
+	fake.cordonedArgsForCall = append(fake.cordonedArgsForCall, struct{}{})
+	fake.recordInvocation("Cordoned", []interface{}{})
+	fake.cordonedMutex.Unlock()
+	if fake.CordonedStub != nil {
+		return fake.CordonedStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.cordonedReturns.result1
+}
+
+func (fake *FakeCordonReporter) CordonedCallCount() int {
+	fake.cordonedMutex.RLock()
+	defer fake.cordonedMutex.RUnlock()
+	return len(fake.cordonedArgsForCall)
+}
+
+func (fake *FakeCordonReporter) CordonedReturns(result1 bool) {
+	fake.CordonedStub = nil
+	fake.cordonedReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeCordonReporter) CordonedReturnsOnCall(i int, result1 bool) {
+	fake.CordonedStub = nil
+	if fake.cordonedReturnsOnCall == nil {
+		fake.cordonedReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.cordonedReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeCordonReporter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cordonedMutex.RLock()
+	defer fake.cordonedMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeCordonReporter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ evacuation_context.CordonReporter = new(FakeCordonReporter)
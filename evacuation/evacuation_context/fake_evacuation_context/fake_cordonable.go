@@ -0,0 +1,75 @@
+// This file was generated by counterfeiter
+package fake_evacuation_context
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+type FakeCordonable struct {
+	CordonStub          func()
+	cordonMutex         sync.RWMutex
+	cordonArgsForCall   []struct{}
+	UncordonStub        func()
+	uncordonMutex       sync.RWMutex
+	uncordonArgsForCall []struct{}
+	invocations         map[string][][]interface{}
+	invocationsMutex    sync.RWMutex
+}
+
+func (fake *FakeCordonable) Cordon() {
+	fake.cordonMutex.Lock()
+	fake.cordonArgsForCall = append(fake.cordonArgsForCall, struct{}{})
+	fake.recordInvocation("Cordon", []interface{}{})
+	fake.cordonMutex.Unlock()
+	if fake.CordonStub != nil {
+		fake.CordonStub()
+	}
+}
+
+func (fake *FakeCordonable) CordonCallCount() int {
+	fake.cordonMutex.RLock()
+	defer fake.cordonMutex.RUnlock()
+	return len(fake.cordonArgsForCall)
+}
+
+func (fake *FakeCordonable) Uncordon() {
+	fake.uncordonMutex.Lock()
+	fake.uncordonArgsForCall = append(fake.uncordonArgsForCall, struct{}{})
+	fake.recordInvocation("Uncordon", []interface{}{})
+	fake.uncordonMutex.Unlock()
+	if fake.UncordonStub != nil {
+		fake.UncordonStub()
+	}
+}
+
+func (fake *FakeCordonable) UncordonCallCount() int {
+	fake.uncordonMutex.RLock()
+	defer fake.uncordonMutex.RUnlock()
+	return len(fake.uncordonArgsForCall)
+}
+
+func (fake *FakeCordonable) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cordonMutex.RLock()
+	defer fake.cordonMutex.RUnlock()
+	fake.uncordonMutex.RLock()
+	defer fake.uncordonMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeCordonable) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ evacuation_context.Cordonable = new(FakeCordonable)
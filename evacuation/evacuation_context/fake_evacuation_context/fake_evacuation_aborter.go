@@ -0,0 +1,95 @@
+// This file was generated by counterfeiter
+package fake_evacuation_context
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+type FakeEvacuationAborter struct {
+	AbortEvacuationStub        func(logger lager.Logger) error
+	abortEvacuationMutex       sync.RWMutex
+	abortEvacuationArgsForCall []struct {
+		logger lager.Logger
+	}
+	abortEvacuationReturns struct {
+		result1 error
+	}
+	abortEvacuationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeEvacuationAborter) AbortEvacuation(logger lager.Logger) error {
+	fake.abortEvacuationMutex.Lock()
+	ret, specificReturn := fake.abortEvacuationReturnsOnCall[len(fake.abortEvacuationArgsForCall)]
+	fake.abortEvacuationArgsForCall = append(fake.abortEvacuationArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("AbortEvacuation", []interface{}{logger})
+	fake.abortEvacuationMutex.Unlock()
+	if fake.AbortEvacuationStub != nil {
+		return fake.AbortEvacuationStub(logger)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.abortEvacuationReturns.result1
+}
+
+func (fake *FakeEvacuationAborter) AbortEvacuationCallCount() int {
+	fake.abortEvacuationMutex.RLock()
+	defer fake.abortEvacuationMutex.RUnlock()
+	return len(fake.abortEvacuationArgsForCall)
+}
+
+func (fake *FakeEvacuationAborter) AbortEvacuationArgsForCall(i int) lager.Logger {
+	fake.abortEvacuationMutex.RLock()
+	defer fake.abortEvacuationMutex.RUnlock()
+	return fake.abortEvacuationArgsForCall[i].logger
+}
+
+func (fake *FakeEvacuationAborter) AbortEvacuationReturns(result1 error) {
+	fake.AbortEvacuationStub = nil
+	fake.abortEvacuationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEvacuationAborter) AbortEvacuationReturnsOnCall(i int, result1 error) {
+	fake.AbortEvacuationStub = nil
+	if fake.abortEvacuationReturnsOnCall == nil {
+		fake.abortEvacuationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.abortEvacuationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEvacuationAborter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.abortEvacuationMutex.RLock()
+	defer fake.abortEvacuationMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeEvacuationAborter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ evacuation_context.EvacuationAborter = new(FakeEvacuationAborter)
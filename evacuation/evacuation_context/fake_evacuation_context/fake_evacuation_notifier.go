@@ -17,6 +17,15 @@ type FakeEvacuationNotifier struct {
 	evacuateNotifyReturnsOnCall map[int]struct {
 		result1 <-chan struct{}
 	}
+	ResumeNotifyStub        func() <-chan struct{}
+	resumeNotifyMutex       sync.RWMutex
+	resumeNotifyArgsForCall []struct{}
+	resumeNotifyReturns     struct {
+		result1 <-chan struct{}
+	}
+	resumeNotifyReturnsOnCall map[int]struct {
+		result1 <-chan struct{}
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -61,11 +70,53 @@ func (fake *FakeEvacuationNotifier) EvacuateNotifyReturnsOnCall(i int, result1 <
 	}{result1}
 }
 
+func (fake *FakeEvacuationNotifier) ResumeNotify() <-chan struct{} {
+	fake.resumeNotifyMutex.Lock()
+	ret, specificReturn := fake.resumeNotifyReturnsOnCall[len(fake.resumeNotifyArgsForCall)]
+	fake.resumeNotifyArgsForCall = append(fake.resumeNotifyArgsForCall, struct{}{})
+	fake.recordInvocation("ResumeNotify", []interface{}{})
+	fake.resumeNotifyMutex.Unlock()
+	if fake.ResumeNotifyStub != nil {
+		return fake.ResumeNotifyStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.resumeNotifyReturns.result1
+}
+
+func (fake *FakeEvacuationNotifier) ResumeNotifyCallCount() int {
+	fake.resumeNotifyMutex.RLock()
+	defer fake.resumeNotifyMutex.RUnlock()
+	return len(fake.resumeNotifyArgsForCall)
+}
+
+func (fake *FakeEvacuationNotifier) ResumeNotifyReturns(result1 <-chan struct{}) {
+	fake.ResumeNotifyStub = nil
+	fake.resumeNotifyReturns = struct {
+		result1 <-chan struct{}
+	}{result1}
+}
+
+func (fake *FakeEvacuationNotifier) ResumeNotifyReturnsOnCall(i int, result1 <-chan struct{}) {
+	fake.ResumeNotifyStub = nil
+	if fake.resumeNotifyReturnsOnCall == nil {
+		fake.resumeNotifyReturnsOnCall = make(map[int]struct {
+			result1 <-chan struct{}
+		})
+	}
+	fake.resumeNotifyReturnsOnCall[i] = struct {
+		result1 <-chan struct{}
+	}{result1}
+}
+
 func (fake *FakeEvacuationNotifier) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.evacuateNotifyMutex.RLock()
 	defer fake.evacuateNotifyMutex.RUnlock()
+	fake.resumeNotifyMutex.RLock()
+	defer fake.resumeNotifyMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -0,0 +1,95 @@
+// This file was generated by counterfeiter
+package fake_evacuation_context
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+type FakeEvacuationStatusReporter struct {
+	EvacuationStatusStub        func(logger lager.Logger) evacuation_context.EvacuationStatus
+	evacuationStatusMutex       sync.RWMutex
+	evacuationStatusArgsForCall []struct {
+		logger lager.Logger
+	}
+	evacuationStatusReturns struct {
+		result1 evacuation_context.EvacuationStatus
+	}
+	evacuationStatusReturnsOnCall map[int]struct {
+		result1 evacuation_context.EvacuationStatus
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeEvacuationStatusReporter) EvacuationStatus(logger lager.Logger) evacuation_context.EvacuationStatus {
+	fake.evacuationStatusMutex.Lock()
+	ret, specificReturn := fake.evacuationStatusReturnsOnCall[len(fake.evacuationStatusArgsForCall)]
+	fake.evacuationStatusArgsForCall = append(fake.evacuationStatusArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("EvacuationStatus", []interface{}{logger})
+	fake.evacuationStatusMutex.Unlock()
+	if fake.EvacuationStatusStub != nil {
+		return fake.EvacuationStatusStub(logger)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.evacuationStatusReturns.result1
+}
+
+func (fake *FakeEvacuationStatusReporter) EvacuationStatusCallCount() int {
+	fake.evacuationStatusMutex.RLock()
+	defer fake.evacuationStatusMutex.RUnlock()
+	return len(fake.evacuationStatusArgsForCall)
+}
+
+func (fake *FakeEvacuationStatusReporter) EvacuationStatusArgsForCall(i int) lager.Logger {
+	fake.evacuationStatusMutex.RLock()
+	defer fake.evacuationStatusMutex.RUnlock()
+	return fake.evacuationStatusArgsForCall[i].logger
+}
+
+func (fake *FakeEvacuationStatusReporter) EvacuationStatusReturns(result1 evacuation_context.EvacuationStatus) {
+	fake.EvacuationStatusStub = nil
+	fake.evacuationStatusReturns = struct {
+		result1 evacuation_context.EvacuationStatus
+	}{result1}
+}
+
+func (fake *FakeEvacuationStatusReporter) EvacuationStatusReturnsOnCall(i int, result1 evacuation_context.EvacuationStatus) {
+	fake.EvacuationStatusStub = nil
+	if fake.evacuationStatusReturnsOnCall == nil {
+		fake.evacuationStatusReturnsOnCall = make(map[int]struct {
+			result1 evacuation_context.EvacuationStatus
+		})
+	}
+	fake.evacuationStatusReturnsOnCall[i] = struct {
+		result1 evacuation_context.EvacuationStatus
+	}{result1}
+}
+
+func (fake *FakeEvacuationStatusReporter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.evacuationStatusMutex.RLock()
+	defer fake.evacuationStatusMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeEvacuationStatusReporter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ evacuation_context.EvacuationStatusReporter = new(FakeEvacuationStatusReporter)
@@ -0,0 +1,73 @@
+package evacuation_context
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter -o fake_evacuation_context/fake_evacuation_status_reporter.go . EvacuationStatusReporter
+type EvacuationStatusReporter interface {
+	EvacuationStatus(logger lager.Logger) EvacuationStatus
+}
+
+// EvacuationStatus describes the progress of an evacuation. The remaining
+// containers are counted by executor state.
+type EvacuationStatus struct {
+	Active         bool           `json:"active"`
+	Complete       bool           `json:"complete"`
+	StartedAt      *time.Time     `json:"started_at,omitempty"`
+	Deadline       *time.Time     `json:"deadline,omitempty"`
+	RemainingLRPs  map[string]int `json:"remaining_lrps"`
+	RemainingTasks map[string]int `json:"remaining_tasks"`
+	HandedOffLRPs  int            `json:"handed_off_lrps"`
+	Error          string         `json:"error,omitempty"`
+}
+
+type HandoffRecorder interface {
+	RecordHandoff(containerGuid string)
+	HandedOff(containerGuid string) bool
+	ForgetHandoff(containerGuid string)
+}
+
+// HandoffCounter counts the running LRPs that the BBS has agreed to start
+// elsewhere while the cell evacuates. The same container is evacuated on
+// every pass of the bulker until it goes away, so each one is only counted
+// once. A container is forgotten again if the cell takes its LRP back after
+// the evacuation is aborted.
+type HandoffCounter struct {
+	lock       sync.Mutex
+	containers map[string]struct{}
+}
+
+func NewHandoffCounter() *HandoffCounter {
+	return &HandoffCounter{
+		containers: map[string]struct{}{},
+	}
+}
+
+func (c *HandoffCounter) RecordHandoff(containerGuid string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.containers[containerGuid] = struct{}{}
+}
+
+func (c *HandoffCounter) HandedOff(containerGuid string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.containers[containerGuid]
+	return ok
+}
+
+func (c *HandoffCounter) ForgetHandoff(containerGuid string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.containers, containerGuid)
+}
+
+func (c *HandoffCounter) Handoffs() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.containers)
+}
@@ -0,0 +1,40 @@
+package evacuation_context_test
+
+import (
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HandoffCounter", func() {
+	var counter *evacuation_context.HandoffCounter
+
+	BeforeEach(func() {
+		counter = evacuation_context.NewHandoffCounter()
+	})
+
+	It("starts at zero", func() {
+		Expect(counter.Handoffs()).To(BeZero())
+	})
+
+	It("counts each container once", func() {
+		counter.RecordHandoff("guid-1")
+		counter.RecordHandoff("guid-2")
+		counter.RecordHandoff("guid-1")
+		Expect(counter.Handoffs()).To(Equal(2))
+	})
+
+	It("remembers which containers were handed off", func() {
+		counter.RecordHandoff("guid-1")
+		Expect(counter.HandedOff("guid-1")).To(BeTrue())
+		Expect(counter.HandedOff("guid-2")).To(BeFalse())
+	})
+
+	It("forgets a container that is taken back", func() {
+		counter.RecordHandoff("guid-1")
+		counter.ForgetHandoff("guid-1")
+		Expect(counter.HandedOff("guid-1")).To(BeFalse())
+		Expect(counter.Handoffs()).To(BeZero())
+	})
+})
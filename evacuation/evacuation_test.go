@@ -32,6 +32,9 @@ var _ = Describe("Evacuation", func() {
 		executorClient     *fakes.FakeClient
 		evacuatable        evacuation_context.Evacuatable
 		evacuationNotifier evacuation_context.EvacuationNotifier
+		evacuationReporter evacuation_context.EvacuationReporter
+		resumable          evacuation_context.Resumable
+		handoffs           *evacuation_context.HandoffCounter
 
 		evacuator *evacuation.Evacuator
 		process   ifrit.Process
@@ -48,16 +51,19 @@ var _ = Describe("Evacuation", func() {
 		fakeClock = fakeclock.NewFakeClock(time.Now())
 		executorClient = &fakes.FakeClient{}
 
-		evacuatable, _, evacuationNotifier = evacuation_context.New()
+		evacuatable, evacuationReporter, evacuationNotifier, resumable = evacuation_context.New()
+		handoffs = evacuation_context.NewHandoffCounter()
 
 		evacuator = evacuation.NewEvacuator(
 			logger,
 			fakeClock,
 			executorClient,
 			evacuationNotifier,
+			resumable,
 			cellID,
 			evacuationTimeout,
 			pollingInterval,
+			handoffs,
 		)
 
 		process = ifrit.Invoke(evacuator)
@@ -168,4 +174,162 @@ var _ = Describe("Evacuation", func() {
 			})
 		})
 	})
+
+	Describe("EvacuationStatus", func() {
+		BeforeEach(func() {
+			executorClient.ListContainersReturns(append(containers,
+				executor.Container{Guid: "guid-3", State: executor.StateCreated, Tags: LRPTags},
+				executor.Container{Guid: "guid-4", State: executor.StateRunning, Tags: LRPTags},
+			), nil)
+		})
+
+		AfterEach(func() {
+			process.Signal(os.Interrupt)
+			Eventually(errChan).Should(Receive())
+		})
+
+		Context("before evacuating", func() {
+			It("reports that evacuation is not active", func() {
+				status := evacuator.EvacuationStatus(logger)
+				Expect(status.Active).To(BeFalse())
+				Expect(status.Complete).To(BeFalse())
+				Expect(status.StartedAt).To(BeNil())
+				Expect(status.Deadline).To(BeNil())
+			})
+
+			It("reports the containers on the cell", func() {
+				status := evacuator.EvacuationStatus(logger)
+				Expect(status.RemainingLRPs).To(Equal(map[string]int{
+					string(executor.StateRunning): 2,
+					string(executor.StateCreated): 1,
+				}))
+				Expect(status.RemainingTasks).To(Equal(map[string]int{
+					string(executor.StateRunning): 1,
+				}))
+			})
+		})
+
+		Context("during evacuation", func() {
+			var startedAt time.Time
+
+			BeforeEach(func() {
+				startedAt = fakeClock.Now()
+				evacuatable.Evacuate()
+				Eventually(func() bool {
+					return evacuator.EvacuationStatus(logger).Active
+				}).Should(BeTrue())
+			})
+
+			It("reports when the evacuation started and when it times out", func() {
+				status := evacuator.EvacuationStatus(logger)
+				Expect(*status.StartedAt).To(BeTemporally("==", startedAt))
+				Expect(*status.Deadline).To(BeTemporally("==", startedAt.Add(evacuationTimeout)))
+				Expect(status.Complete).To(BeFalse())
+			})
+
+			It("reports how many LRPs have been handed off", func() {
+				handoffs.RecordHandoff("guid-2")
+				Expect(evacuator.EvacuationStatus(logger).HandedOffLRPs).To(Equal(1))
+			})
+
+			It("reports when all the containers are gone", func() {
+				executorClient.ListContainersReturns([]executor.Container{}, nil)
+				fakeClock.WaitForNWatchersAndIncrement(pollingInterval, 2)
+
+				Eventually(func() bool {
+					return evacuator.EvacuationStatus(logger).Complete
+				}).Should(BeTrue())
+			})
+		})
+
+		Context("when the containers cannot be listed", func() {
+			BeforeEach(func() {
+				executorClient.ListContainersReturns(nil, errors.New("whoops"))
+			})
+
+			It("reports the error", func() {
+				status := evacuator.EvacuationStatus(logger)
+				Expect(status.Error).To(Equal("whoops"))
+				Expect(status.RemainingLRPs).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("AbortEvacuation", func() {
+		BeforeEach(func() {
+			executorClient.ListContainersReturns(containers, nil)
+		})
+
+		AfterEach(func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive())
+		})
+
+		Context("before evacuating", func() {
+			It("fails", func() {
+				Expect(evacuator.AbortEvacuation(logger)).To(Equal(evacuation_context.ErrNotEvacuating))
+			})
+		})
+
+		Context("during evacuation", func() {
+			BeforeEach(func() {
+				evacuatable.Evacuate()
+				Eventually(fakeClock.WatcherCount).Should(Equal(2))
+			})
+
+			It("returns the cell to normal operation without exiting", func() {
+				Expect(evacuator.AbortEvacuation(logger)).To(Succeed())
+				Expect(evacuationReporter.Evacuating()).To(BeFalse())
+
+				Eventually(fakeClock.WatcherCount).Should(Equal(0))
+				Consistently(errChan).ShouldNot(Receive())
+			})
+
+			It("no longer reports an active evacuation", func() {
+				Expect(evacuator.AbortEvacuation(logger)).To(Succeed())
+				Eventually(func() bool {
+					return evacuator.EvacuationStatus(logger).Active
+				}).Should(BeFalse())
+			})
+
+			It("does not time out once aborted", func() {
+				Expect(evacuator.AbortEvacuation(logger)).To(Succeed())
+				Eventually(fakeClock.WatcherCount).Should(Equal(0))
+
+				fakeClock.Increment(evacuationTimeout + time.Second)
+				Consistently(errChan).ShouldNot(Receive())
+			})
+
+			It("evacuates again when asked to", func() {
+				Expect(evacuator.AbortEvacuation(logger)).To(Succeed())
+				Eventually(fakeClock.WatcherCount).Should(Equal(0))
+				calls := executorClient.ListContainersCallCount()
+
+				evacuatable.Evacuate()
+				Eventually(executorClient.ListContainersCallCount).Should(BeNumerically(">", calls))
+
+				executorClient.ListContainersReturns([]executor.Container{}, nil)
+				fakeClock.WaitForNWatchersAndIncrement(pollingInterval, 2)
+				Eventually(errChan).Should(Receive(BeNil()))
+			})
+
+			It("can only be aborted once", func() {
+				Expect(evacuator.AbortEvacuation(logger)).To(Succeed())
+				Expect(evacuator.AbortEvacuation(logger)).To(Equal(evacuation_context.ErrNotEvacuating))
+			})
+		})
+
+		Context("once the evacuation has timed out", func() {
+			BeforeEach(func() {
+				evacuatable.Evacuate()
+				fakeClock.WaitForNWatchersAndIncrement(evacuationTimeout, 2)
+				Eventually(errChan).Should(Receive(BeNil()))
+			})
+
+			It("fails", func() {
+				Expect(evacuator.AbortEvacuation(logger)).To(Equal(evacuation_context.ErrEvacuationFinished))
+				Expect(evacuationReporter.Evacuating()).To(BeTrue())
+			})
+		})
+	})
 })
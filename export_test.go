@@ -0,0 +1,9 @@
+package rep
+
+// DeregisterScoreStrategy removes a strategy that a test registered, so that
+// it does not leak into the registry seen by later specs.
+func DeregisterScoreStrategy(name string) {
+	scoreStrategiesLock.Lock()
+	defer scoreStrategiesLock.Unlock()
+	delete(scoreStrategies, name)
+}
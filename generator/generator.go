@@ -40,9 +40,10 @@ func New(
 	executorClient executor.Client,
 	evacuationReporter evacuation_context.EvacuationReporter,
 	evacuationTTLInSeconds uint64,
+	handoffRecorder evacuation_context.HandoffRecorder,
 ) Generator {
 	containerDelegate := internal.NewContainerDelegate(executorClient)
-	lrpProcessor := internal.NewLRPProcessor(bbs, containerDelegate, cellID, evacuationReporter, evacuationTTLInSeconds)
+	lrpProcessor := internal.NewLRPProcessor(bbs, containerDelegate, cellID, evacuationReporter, evacuationTTLInSeconds, handoffRecorder)
 	taskProcessor := internal.NewTaskProcessor(bbs, containerDelegate, cellID)
 
 	return &generator{
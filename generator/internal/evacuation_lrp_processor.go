@@ -5,6 +5,7 @@ import (
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 )
 
 type evacuationLRPProcessor struct {
@@ -12,14 +13,16 @@ type evacuationLRPProcessor struct {
 	containerDelegate      ContainerDelegate
 	cellID                 string
 	evacuationTTLInSeconds uint64
+	handoffRecorder        evacuation_context.HandoffRecorder
 }
 
-func newEvacuationLRPProcessor(bbsClient bbs.InternalClient, containerDelegate ContainerDelegate, cellID string, evacuationTTLInSeconds uint64) LRPProcessor {
+func newEvacuationLRPProcessor(bbsClient bbs.InternalClient, containerDelegate ContainerDelegate, cellID string, evacuationTTLInSeconds uint64, handoffRecorder evacuation_context.HandoffRecorder) LRPProcessor {
 	return &evacuationLRPProcessor{
 		bbsClient:              bbsClient,
 		containerDelegate:      containerDelegate,
 		cellID:                 cellID,
 		evacuationTTLInSeconds: evacuationTTLInSeconds,
+		handoffRecorder:        handoffRecorder,
 	}
 }
 
@@ -88,6 +91,10 @@ func (p *evacuationLRPProcessor) processRunningContainer(logger lager.Logger, lr
 
 	logger.Info("bbs-evacuate-running-actual-lrp", lager.Data{"net_info": netInfo})
 	keepContainer, err := p.bbsClient.EvacuateRunningActualLRP(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey, netInfo, p.evacuationTTLInSeconds)
+	if err == nil {
+		p.handoffRecorder.RecordHandoff(lrpContainer.Container.Guid)
+	}
+
 	if keepContainer == false {
 		p.containerDelegate.DeleteContainer(logger, lrpContainer.Container.Guid)
 	} else if err != nil {
@@ -10,6 +10,7 @@ import (
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/generator/internal"
 	"code.cloudfoundry.org/rep/generator/internal/fake_internal"
@@ -31,6 +32,7 @@ var _ = Describe("EvacuationLrpProcessor", func() {
 			fakeBBS                *fake_bbs.FakeInternalClient
 			fakeContainerDelegate  *fake_internal.FakeContainerDelegate
 			fakeEvacuationReporter *fake_evacuation_context.FakeEvacuationReporter
+			handoffs               *evacuation_context.HandoffCounter
 
 			lrpProcessor internal.LRPProcessor
 
@@ -53,7 +55,9 @@ var _ = Describe("EvacuationLrpProcessor", func() {
 			fakeEvacuationReporter = &fake_evacuation_context.FakeEvacuationReporter{}
 			fakeEvacuationReporter.EvacuatingReturns(true)
 
-			lrpProcessor = internal.NewLRPProcessor(fakeBBS, fakeContainerDelegate, localCellID, fakeEvacuationReporter, evacuationTTL)
+			handoffs = evacuation_context.NewHandoffCounter()
+
+			lrpProcessor = internal.NewLRPProcessor(fakeBBS, fakeContainerDelegate, localCellID, fakeEvacuationReporter, evacuationTTL, handoffs)
 
 			processGuid = "process-guid"
 			desiredLRP = models.DesiredLRP{
@@ -275,6 +279,16 @@ var _ = Describe("EvacuationLrpProcessor", func() {
 				It("does not delete the container", func() {
 					Expect(fakeContainerDelegate.DeleteContainerCallCount()).To(Equal(0))
 				})
+
+				It("records the handoff", func() {
+					Expect(handoffs.Handoffs()).To(Equal(1))
+				})
+
+				It("records the handoff of a container only once", func() {
+					lrpProcessor.Process(logger, container)
+					Expect(fakeBBS.EvacuateRunningActualLRPCallCount()).To(Equal(2))
+					Expect(handoffs.Handoffs()).To(Equal(1))
+				})
 			})
 
 			Context("when the evacuation returns that it failed to evacuate the LRP", func() {
@@ -287,6 +301,10 @@ var _ = Describe("EvacuationLrpProcessor", func() {
 					_, actualContainerGuid := fakeContainerDelegate.DeleteContainerArgsForCall(0)
 					Expect(actualContainerGuid).To(Equal(container.Guid))
 				})
+
+				It("does not record a handoff", func() {
+					Expect(handoffs.Handoffs()).To(BeZero())
+				})
 			})
 
 			Context("when the evacuation returns some other error", func() {
@@ -297,6 +315,10 @@ var _ = Describe("EvacuationLrpProcessor", func() {
 				It("does not delete the container", func() {
 					Expect(fakeContainerDelegate.DeleteContainerCallCount()).To(Equal(0))
 				})
+
+				It("does not record a handoff", func() {
+					Expect(handoffs.Handoffs()).To(BeZero())
+				})
 			})
 		})
 
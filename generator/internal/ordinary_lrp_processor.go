@@ -6,23 +6,30 @@ import (
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 )
 
 type ordinaryLRPProcessor struct {
-	bbsClient         bbs.InternalClient
-	containerDelegate ContainerDelegate
-	cellID            string
+	bbsClient              bbs.InternalClient
+	containerDelegate      ContainerDelegate
+	cellID                 string
+	evacuationTTLInSeconds uint64
+	handoffRecorder        evacuation_context.HandoffRecorder
 }
 
 func newOrdinaryLRPProcessor(
 	bbsClient bbs.InternalClient,
 	containerDelegate ContainerDelegate,
 	cellID string,
+	evacuationTTLInSeconds uint64,
+	handoffRecorder evacuation_context.HandoffRecorder,
 ) LRPProcessor {
 	return &ordinaryLRPProcessor{
-		bbsClient:         bbsClient,
-		containerDelegate: containerDelegate,
-		cellID:            cellID,
+		bbsClient:              bbsClient,
+		containerDelegate:      containerDelegate,
+		cellID:                 cellID,
+		evacuationTTLInSeconds: evacuationTTLInSeconds,
+		handoffRecorder:        handoffRecorder,
 	}
 }
 
@@ -114,11 +121,50 @@ func (p *ordinaryLRPProcessor) processRunningContainer(logger lager.Logger, lrpC
 	logger.Info("bbs-start-actual-lrp", lager.Data{"net_info": netInfo})
 	err = p.bbsClient.StartActualLRP(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey, netInfo)
 	bbsErr := models.ConvertError(err)
+
+	if p.handoffRecorder.HandedOff(lrpContainer.Guid) {
+		p.reconcileHandoff(logger, lrpContainer, netInfo, err)
+		return
+	}
+
 	if bbsErr != nil && bbsErr.Type == models.Error_ActualLRPCannotBeStarted {
 		p.containerDelegate.StopContainer(logger, lrpContainer.Guid)
 	}
 }
 
+// reconcileHandoff deals with a running container whose LRP was handed off
+// during an evacuation that has since been aborted. If the cell managed to
+// take the LRP back, the evacuating record the BBS still holds for it is
+// removed. If another cell has already claimed the LRP, the handoff is seen
+// through as it would have been during the evacuation, so the container keeps
+// running until the replacement does.
+func (p *ordinaryLRPProcessor) reconcileHandoff(logger lager.Logger, lrpContainer *lrpContainer, netInfo *models.ActualLRPNetInfo, startErr error) {
+	logger = logger.Session("reconcile-handoff")
+
+	if startErr == nil {
+		err := p.bbsClient.RemoveEvacuatingActualLRP(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey)
+		if err != nil {
+			logger.Error("failed-to-remove-evacuating-actual-lrp", err)
+			return
+		}
+		logger.Info("took-back-actual-lrp")
+		p.handoffRecorder.ForgetHandoff(lrpContainer.Guid)
+		return
+	}
+
+	bbsErr := models.ConvertError(startErr)
+	if bbsErr.Type != models.Error_ActualLRPCannotBeStarted {
+		return
+	}
+
+	keepContainer, err := p.bbsClient.EvacuateRunningActualLRP(logger, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey, netInfo, p.evacuationTTLInSeconds)
+	if keepContainer == false {
+		p.containerDelegate.DeleteContainer(logger, lrpContainer.Guid)
+	} else if err != nil {
+		logger.Error("failed-to-evacuate-running-actual-lrp", err)
+	}
+}
+
 func (p *ordinaryLRPProcessor) processCompletedContainer(logger lager.Logger, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-completed-container")
 
@@ -11,6 +11,7 @@ import (
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/generator/internal"
 	"code.cloudfoundry.org/rep/generator/internal/fake_internal"
@@ -29,6 +30,7 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 		bbsClient          *fake_bbs.FakeInternalClient
 		containerDelegate  *fake_internal.FakeContainerDelegate
 		evacuationReporter *fake_evacuation_context.FakeEvacuationReporter
+		handoffs           *evacuation_context.HandoffCounter
 	)
 
 	BeforeEach(func() {
@@ -36,7 +38,8 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 		containerDelegate = new(fake_internal.FakeContainerDelegate)
 		evacuationReporter = &fake_evacuation_context.FakeEvacuationReporter{}
 		evacuationReporter.EvacuatingReturns(false)
-		processor = internal.NewLRPProcessor(bbsClient, containerDelegate, expectedCellID, evacuationReporter, 124)
+		handoffs = evacuation_context.NewHandoffCounter()
+		processor = internal.NewLRPProcessor(bbsClient, containerDelegate, expectedCellID, evacuationReporter, 124, handoffs)
 		logger = lagertest.NewTestLogger("test")
 	})
 
@@ -257,6 +260,73 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 							Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
 						})
 					})
+
+					Context("when the lrp was handed off during an aborted evacuation", func() {
+						BeforeEach(func() {
+							handoffs.RecordHandoff(container.Guid)
+						})
+
+						Context("and the cell takes the lrp back", func() {
+							It("removes the evacuating lrp", func() {
+								Expect(bbsClient.RemoveEvacuatingActualLRPCallCount()).To(Equal(1))
+								_, lrpKey, instanceKey := bbsClient.RemoveEvacuatingActualLRPArgsForCall(0)
+								Expect(*lrpKey).To(Equal(expectedLrpKey))
+								Expect(*instanceKey).To(Equal(expectedInstanceKey))
+							})
+
+							It("forgets the handoff", func() {
+								Expect(handoffs.HandedOff(container.Guid)).To(BeFalse())
+							})
+
+							Context("when removing the evacuating lrp fails", func() {
+								BeforeEach(func() {
+									bbsClient.RemoveEvacuatingActualLRPReturns(errors.New("boom"))
+								})
+
+								It("tries again next time", func() {
+									Expect(handoffs.HandedOff(container.Guid)).To(BeTrue())
+								})
+							})
+						})
+
+						Context("and another cell has claimed the lrp", func() {
+							BeforeEach(func() {
+								bbsClient.StartActualLRPReturns(models.NewError(models.Error_ActualLRPCannotBeStarted, "foobar").ToError())
+							})
+
+							It("sees the handoff through", func() {
+								Expect(bbsClient.EvacuateRunningActualLRPCallCount()).To(Equal(1))
+								_, lrpKey, instanceKey, netInfo, ttl := bbsClient.EvacuateRunningActualLRPArgsForCall(0)
+								Expect(*lrpKey).To(Equal(expectedLrpKey))
+								Expect(*instanceKey).To(Equal(expectedInstanceKey))
+								Expect(*netInfo).To(Equal(expectedNetInfo))
+								Expect(ttl).To(BeEquivalentTo(124))
+								Expect(containerDelegate.StopContainerCallCount()).To(Equal(0))
+							})
+
+							Context("while the replacement is not running yet", func() {
+								BeforeEach(func() {
+									bbsClient.EvacuateRunningActualLRPReturns(true, nil)
+								})
+
+								It("keeps the container", func() {
+									Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+								})
+							})
+
+							Context("once the replacement is running", func() {
+								BeforeEach(func() {
+									bbsClient.EvacuateRunningActualLRPReturns(false, nil)
+								})
+
+								It("deletes the container", func() {
+									Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+									_, containerGuid := containerDelegate.DeleteContainerArgsForCall(0)
+									Expect(containerGuid).To(Equal(container.Guid))
+								})
+							})
+						})
+					})
 				})
 
 				Context("and the container is COMPLETED", func() {
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+type AbortEvacuationHandler struct {
+	aborter evacuation_context.EvacuationAborter
+	cell    auctioncellrep.AuctionCellClient
+}
+
+// Abort Evacuation Handler serves an admin route that returns an evacuating
+// cell to normal operation, for instance after an accidental drain
+func NewAbortEvacuationHandler(
+	aborter evacuation_context.EvacuationAborter,
+	cell auctioncellrep.AuctionCellClient,
+) *AbortEvacuationHandler {
+	return &AbortEvacuationHandler{
+		aborter: aborter,
+		cell:    cell,
+	}
+}
+
+func (h *AbortEvacuationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("handling-abort-evacuation")
+
+	status := http.StatusOK
+	response := map[string]interface{}{"aborted": true}
+
+	err := h.aborter.AbortEvacuation(logger)
+	switch err {
+	case nil:
+		if invalidator, ok := h.cell.(stateInvalidator); ok {
+			invalidator.Invalidate()
+		}
+	case evacuation_context.ErrNotEvacuating, evacuation_context.ErrEvacuationFinished:
+		status = http.StatusConflict
+		response = map[string]interface{}{"aborted": false, "error": err.Error()}
+	default:
+		logger.Error("failed-to-abort-evacuation", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(response)
+	if err != nil {
+		logger.Error("failed-to-marshal-response-payload", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(jsonBytes)))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonBytes)
+}
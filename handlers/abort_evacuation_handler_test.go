@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AbortEvacuationHandler", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeAborter      *fake_evacuation_context.FakeEvacuationAborter
+		handler          *handlers.AbortEvacuationHandler
+		responseRecorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeAborter = new(fake_evacuation_context.FakeEvacuationAborter)
+		handler = handlers.NewAbortEvacuationHandler(fakeAborter, new(auctioncellrepfakes.FakeAuctionCellClient))
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		request, err := http.NewRequest("POST", "/evacuation/abort", nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(responseRecorder, request, logger)
+	})
+
+	response := func() map[string]interface{} {
+		var values map[string]interface{}
+		ExpectWithOffset(1, json.Unmarshal(responseRecorder.Body.Bytes(), &values)).To(Succeed())
+		return values
+	}
+
+	It("aborts the evacuation", func() {
+		Expect(fakeAborter.AbortEvacuationCallCount()).To(Equal(1))
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(response()).To(Equal(map[string]interface{}{"aborted": true}))
+	})
+
+	Context("when the cell is not evacuating", func() {
+		BeforeEach(func() {
+			fakeAborter.AbortEvacuationReturns(evacuation_context.ErrNotEvacuating)
+		})
+
+		It("responds with 409 CONFLICT", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusConflict))
+			Expect(response()).To(HaveKeyWithValue("error", evacuation_context.ErrNotEvacuating.Error()))
+		})
+	})
+
+	Context("when the evacuation has already finished", func() {
+		BeforeEach(func() {
+			fakeAborter.AbortEvacuationReturns(evacuation_context.ErrEvacuationFinished)
+		})
+
+		It("responds with 409 CONFLICT", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusConflict))
+			Expect(response()).To(HaveKeyWithValue("aborted", false))
+		})
+	})
+
+	Context("when aborting fails unexpectedly", func() {
+		BeforeEach(func() {
+			fakeAborter.AbortEvacuationReturns(errors.New("boom"))
+		})
+
+		It("responds with 500 INTERNAL SERVER ERROR", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// BatchWorkPoolSize bounds the number of concurrent executor calls made for
+// all batch requests together.
+const BatchWorkPoolSize = 10
+
+// MaxBatchSize is the largest number of items a batch request may carry.
+const MaxBatchSize = 1000
+
+// WorkPool bounds the number of executor calls that are made at once. The
+// batch handlers share one, so that concurrent batches do not multiply the
+// load on the executor.
+type WorkPool chan struct{}
+
+func NewWorkPool(size int) WorkPool {
+	if size < 1 {
+		size = 1
+	}
+	return make(WorkPool, size)
+}
+
+type StopLRPInstancesHandler struct {
+	client executor.Client
+	pool   WorkPool
+}
+
+func NewStopLRPInstancesHandler(client executor.Client, pool WorkPool) *StopLRPInstancesHandler {
+	return &StopLRPInstancesHandler{
+		client: client,
+		pool:   pool,
+	}
+}
+
+func (h StopLRPInstancesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("handling-stop-lrp-instances")
+
+	var instances []rep.LRPInstanceKeys
+	err := json.NewDecoder(r.Body).Decode(&instances)
+	if err != nil {
+		logger.Error("failed-to-unmarshal", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if len(instances) > MaxBatchSize {
+		logger.Error("batch-too-large", errors.New("too many instances in request"), lager.Data{"num-instances": len(instances)})
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, instance := range instances {
+		if instance.Key.ProcessGuid == "" {
+			logger.Error("missing-process-guid", errors.New("process_guid missing from request"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if instance.InstanceKey.InstanceGuid == "" {
+			logger.Error("missing-instance-guid", errors.New("instance_guid missing from request"), lager.Data{"process-guid": instance.Key.ProcessGuid})
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	if callerGaveUp(w, r, logger) {
+		return
+	}
+
+	logger.Info("stopping", lager.Data{"num-instances": len(instances)})
+	results := make([]rep.BatchResult, len(instances))
+	h.pool.forEach(len(instances), func(i int) {
+		key, instanceKey := instances[i].Key, instances[i].InstanceKey
+		results[i].Guid = instanceKey.InstanceGuid
+
+		// the instances left once the caller gives up are not stopped
+		if err := r.Context().Err(); err != nil {
+			results[i].Error = err.Error()
+			return
+		}
+
+		err := h.client.StopContainer(logger, rep.LRPContainerGuid(key.ProcessGuid, instanceKey.InstanceGuid))
+		if err != nil {
+			logger.Error("failed-to-stop-container", err, lager.Data{"process-guid": key.ProcessGuid, "instance-guid": instanceKey.InstanceGuid})
+			results[i].Error = err.Error()
+		}
+	})
+
+	json.NewEncoder(w).Encode(results)
+}
+
+type CancelTasksHandler struct {
+	executorClient executor.Client
+	pool           WorkPool
+}
+
+func NewCancelTasksHandler(executorClient executor.Client, pool WorkPool) *CancelTasksHandler {
+	return &CancelTasksHandler{
+		executorClient: executorClient,
+		pool:           pool,
+	}
+}
+
+// ServeHTTP deletes the containers of the tasks and reports the result of
+// each. A container that is already gone counts as deleted.
+func (h CancelTasksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("cancel-tasks")
+
+	var taskGuids []string
+	err := json.NewDecoder(r.Body).Decode(&taskGuids)
+	if err != nil {
+		logger.Error("failed-to-unmarshal", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if len(taskGuids) > MaxBatchSize {
+		logger.Error("batch-too-large", errors.New("too many tasks in request"), lager.Data{"num-tasks": len(taskGuids)})
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, taskGuid := range taskGuids {
+		if taskGuid == "" {
+			logger.Error("missing-task-guid", errors.New("task_guid missing from request"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	if callerGaveUp(w, r, logger) {
+		return
+	}
+
+	logger.Info("deleting-containers", lager.Data{"num-tasks": len(taskGuids)})
+	results := make([]rep.BatchResult, len(taskGuids))
+	h.pool.forEach(len(taskGuids), func(i int) {
+		results[i].Guid = taskGuids[i]
+
+		// the tasks left once the caller gives up are not cancelled
+		if err := r.Context().Err(); err != nil {
+			results[i].Error = err.Error()
+			return
+		}
+
+		err := h.executorClient.DeleteContainer(logger, taskGuids[i])
+		if err == executor.ErrContainerNotFound {
+			logger.Info("container-not-found", lager.Data{"task-guid": taskGuids[i]})
+			return
+		}
+
+		if err != nil {
+			logger.Error("failed-deleting-container", err, lager.Data{"task-guid": taskGuids[i]})
+			results[i].Error = err.Error()
+		}
+	})
+	logger.Info("finished-deleting-containers")
+
+	json.NewEncoder(w).Encode(results)
+}
+
+// forEach calls work for each index below n, running no more calls at a time
+// than the pool allows.
+func (pool WorkPool) forEach(n int, work func(int)) {
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		pool <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-pool
+				wg.Done()
+			}()
+			work(i)
+		}(i)
+	}
+	wg.Wait()
+}
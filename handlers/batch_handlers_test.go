@@ -0,0 +1,260 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	executorfakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Batch handlers", func() {
+	var (
+		fakeClient *executorfakes.FakeClient
+		pool       handlers.WorkPool
+		resp       *httptest.ResponseRecorder
+		logger     *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		fakeClient = &executorfakes.FakeClient{}
+		pool = handlers.NewWorkPool(2)
+		logger = lagertest.NewTestLogger("test")
+		resp = httptest.NewRecorder()
+	})
+
+	results := func() []rep.BatchResult {
+		var results []rep.BatchResult
+		ExpectWithOffset(1, json.NewDecoder(resp.Body).Decode(&results)).To(Succeed())
+		return results
+	}
+
+	Describe("StopLRPInstancesHandler", func() {
+		serve := func(body string) {
+			req, err := http.NewRequest("POST", "", bytes.NewBufferString(body))
+			Expect(err).NotTo(HaveOccurred())
+			handlers.NewStopLRPInstancesHandler(fakeClient, pool).ServeHTTP(resp, req, logger)
+		}
+
+		It("stops each instance and reports the result of each", func() {
+			fakeClient.StopContainerStub = func(_ lager.Logger, guid string) error {
+				if guid == rep.LRPContainerGuid("pg-2", "ig-2") {
+					return errors.New("boom")
+				}
+				return nil
+			}
+
+			serve(JSONFor([]rep.LRPInstanceKeys{
+				rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg-1", 0, "domain"), models.NewActualLRPInstanceKey("ig-1", "cell")),
+				rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg-2", 1, "domain"), models.NewActualLRPInstanceKey("ig-2", "cell")),
+			}))
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(results()).To(Equal([]rep.BatchResult{
+				{Guid: "ig-1"},
+				{Guid: "ig-2", Error: "boom"},
+			}))
+			Expect(fakeClient.StopContainerCallCount()).To(Equal(2))
+		})
+
+		It("bounds the number of concurrent stops", func() {
+			var lock sync.Mutex
+			running, maxRunning := 0, 0
+			fakeClient.StopContainerStub = func(lager.Logger, string) error {
+				lock.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				lock.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				lock.Lock()
+				running--
+				lock.Unlock()
+				return nil
+			}
+
+			instances := []rep.LRPInstanceKeys{}
+			for i := 0; i < 10; i++ {
+				instances = append(instances, rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg", int32(i), "domain"), models.NewActualLRPInstanceKey("ig", "cell")))
+			}
+			serve(JSONFor(instances))
+
+			Expect(fakeClient.StopContainerCallCount()).To(Equal(10))
+			Expect(maxRunning).To(BeNumerically("<=", 2))
+		})
+
+		It("rejects an invalid body", func() {
+			serve("{")
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeClient.StopContainerCallCount()).To(BeZero())
+		})
+
+		It("rejects a batch that is too large", func() {
+			instances := make([]rep.LRPInstanceKeys, handlers.MaxBatchSize+1)
+			for i := range instances {
+				instances[i] = rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg", int32(i), "domain"), models.NewActualLRPInstanceKey("ig", "cell"))
+			}
+			serve(JSONFor(instances))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeClient.StopContainerCallCount()).To(BeZero())
+		})
+
+		It("does not stop instances once the caller has given up", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req, err := http.NewRequest("POST", "", bytes.NewBufferString(JSONFor([]rep.LRPInstanceKeys{
+				rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg-1", 0, "domain"), models.NewActualLRPInstanceKey("ig-1", "cell")),
+			})))
+			Expect(err).NotTo(HaveOccurred())
+			handlers.NewStopLRPInstancesHandler(fakeClient, pool).ServeHTTP(resp, req.WithContext(ctx), logger)
+
+			Expect(resp.Code).To(Equal(http.StatusGatewayTimeout))
+			Expect(fakeClient.StopContainerCallCount()).To(BeZero())
+		})
+
+		It("rejects an instance without a process guid", func() {
+			serve(JSONFor([]rep.LRPInstanceKeys{
+				rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg-1", 0, "domain"), models.NewActualLRPInstanceKey("ig-1", "cell")),
+				rep.NewLRPInstanceKeys(models.NewActualLRPKey("", 1, "domain"), models.NewActualLRPInstanceKey("ig-2", "cell")),
+			}))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeClient.StopContainerCallCount()).To(BeZero())
+		})
+
+		It("rejects an instance without an instance guid", func() {
+			serve(JSONFor([]rep.LRPInstanceKeys{
+				rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg-1", 0, "domain"), models.NewActualLRPInstanceKey("", "cell")),
+			}))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeClient.StopContainerCallCount()).To(BeZero())
+		})
+	})
+
+	Describe("CancelTasksHandler", func() {
+		serve := func(body string) {
+			req, err := http.NewRequest("POST", "", bytes.NewBufferString(body))
+			Expect(err).NotTo(HaveOccurred())
+			handlers.NewCancelTasksHandler(fakeClient, pool).ServeHTTP(resp, req, logger)
+		}
+
+		It("deletes each task container and reports the result of each", func() {
+			fakeClient.DeleteContainerStub = func(_ lager.Logger, guid string) error {
+				switch guid {
+				case "missing":
+					return executor.ErrContainerNotFound
+				case "broken":
+					return errors.New("boom")
+				}
+				return nil
+			}
+
+			serve(JSONFor([]string{"task-1", "missing", "broken"}))
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(results()).To(Equal([]rep.BatchResult{
+				{Guid: "task-1"},
+				{Guid: "missing"},
+				{Guid: "broken", Error: "boom"},
+			}))
+			Expect(fakeClient.DeleteContainerCallCount()).To(Equal(3))
+		})
+
+		It("shares the bound on concurrent calls with other batches", func() {
+			var lock sync.Mutex
+			running, maxRunning := 0, 0
+			track := func() {
+				lock.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				lock.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				lock.Lock()
+				running--
+				lock.Unlock()
+			}
+			fakeClient.DeleteContainerStub = func(lager.Logger, string) error {
+				track()
+				return nil
+			}
+			fakeClient.StopContainerStub = func(lager.Logger, string) error {
+				track()
+				return nil
+			}
+
+			taskGuids := []string{}
+			instances := []rep.LRPInstanceKeys{}
+			for i := 0; i < 5; i++ {
+				taskGuids = append(taskGuids, fmt.Sprintf("task-%d", i))
+				instances = append(instances, rep.NewLRPInstanceKeys(models.NewActualLRPKey("pg", int32(i), "domain"), models.NewActualLRPInstanceKey("ig", "cell")))
+			}
+
+			wg := sync.WaitGroup{}
+			for i := 0; i < 2; i++ {
+				wg.Add(2)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					req, err := http.NewRequest("POST", "", bytes.NewBufferString(JSONFor(taskGuids)))
+					Expect(err).NotTo(HaveOccurred())
+					handlers.NewCancelTasksHandler(fakeClient, pool).ServeHTTP(httptest.NewRecorder(), req, logger)
+				}()
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					req, err := http.NewRequest("POST", "", bytes.NewBufferString(JSONFor(instances)))
+					Expect(err).NotTo(HaveOccurred())
+					handlers.NewStopLRPInstancesHandler(fakeClient, pool).ServeHTTP(httptest.NewRecorder(), req, logger)
+				}()
+			}
+			wg.Wait()
+
+			Expect(fakeClient.DeleteContainerCallCount()).To(Equal(10))
+			Expect(fakeClient.StopContainerCallCount()).To(Equal(10))
+			Expect(maxRunning).To(BeNumerically("<=", 2))
+		})
+
+		It("rejects a batch that is too large", func() {
+			taskGuids := make([]string, handlers.MaxBatchSize+1)
+			for i := range taskGuids {
+				taskGuids[i] = fmt.Sprintf("task-%d", i)
+			}
+			serve(JSONFor(taskGuids))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeClient.DeleteContainerCallCount()).To(BeZero())
+		})
+
+		It("rejects an invalid body", func() {
+			serve("[1]")
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeClient.DeleteContainerCallCount()).To(BeZero())
+		})
+
+		It("rejects a blank task guid", func() {
+			serve(JSONFor([]string{"task-1", ""}))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeClient.DeleteContainerCallCount()).To(BeZero())
+		})
+	})
+})
@@ -24,6 +24,10 @@ func (h CancelTaskHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, log
 		"instance-guid": taskGuid,
 	})
 
+	if callerGaveUp(w, r, logger) {
+		return
+	}
+
 	w.WriteHeader(http.StatusAccepted)
 
 	go func() {
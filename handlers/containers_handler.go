@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+type ContainersHandler struct {
+	client executor.Client
+}
+
+func NewContainersHandler(client executor.Client) *ContainersHandler {
+	return &ContainersHandler{
+		client: client,
+	}
+}
+
+func (h ContainersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	filter := rep.NewContainerFilter(r.URL.Query())
+	logger = logger.Session("handling-list-containers", lager.Data{"filter": filter})
+
+	containers, err := h.client.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	infos := []rep.ContainerInfo{}
+	for _, container := range containers {
+		info := rep.NewContainerInfo(container)
+		if filter.Matches(info) {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Guid < infos[j].Guid })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+type ContainerHandler struct {
+	client executor.Client
+}
+
+func NewContainerHandler(client executor.Client) *ContainerHandler {
+	return &ContainerHandler{
+		client: client,
+	}
+}
+
+func (h ContainerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	guid := r.FormValue(":guid")
+	logger = logger.Session("handling-get-container", lager.Data{"guid": guid})
+
+	if guid == "" {
+		logger.Error("missing-guid", errors.New("guid missing from request"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	container, err := h.client.GetContainer(logger, guid)
+	if err == executor.ErrContainerNotFound {
+		logger.Info("container-not-found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Error("failed-to-get-container", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rep.NewContainerInfo(container))
+}
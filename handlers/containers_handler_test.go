@@ -0,0 +1,162 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"code.cloudfoundry.org/executor"
+	executorfakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Container handlers", func() {
+	var (
+		fakeClient *executorfakes.FakeClient
+		resp       *httptest.ResponseRecorder
+		req        *http.Request
+		logger     *lagertest.TestLogger
+		lrp, task  executor.Container
+	)
+
+	BeforeEach(func() {
+		var err error
+		fakeClient = &executorfakes.FakeClient{}
+		logger = lagertest.NewTestLogger("test")
+		logger.RegisterSink(lager.NewWriterSink(GinkgoWriter, lager.DEBUG))
+		resp = httptest.NewRecorder()
+
+		req, err = http.NewRequest("GET", "", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		lrp = executor.Container{
+			Guid:  "lrp-guid",
+			State: executor.StateRunning,
+			Tags: executor.Tags{
+				rep.LifecycleTag:    rep.LRPLifecycle,
+				rep.DomainTag:       "cf-apps",
+				rep.ProcessGuidTag:  "process-guid",
+				rep.InstanceGuidTag: "instance-guid",
+				rep.ProcessIndexTag: "1",
+			},
+		}
+		task = executor.Container{
+			Guid:  "task-guid",
+			State: executor.StateCompleted,
+			Tags: executor.Tags{
+				rep.LifecycleTag: rep.TaskLifecycle,
+				rep.DomainTag:    "cf-tasks",
+			},
+			RunResult: executor.ContainerRunResult{Failed: true, FailureReason: "boom"},
+		}
+	})
+
+	Describe("ContainersHandler", func() {
+		var infos []rep.ContainerInfo
+
+		JustBeforeEach(func() {
+			handlers.NewContainersHandler(fakeClient).ServeHTTP(resp, req, logger)
+			infos = nil
+			if resp.Code == http.StatusOK {
+				Expect(json.NewDecoder(resp.Body).Decode(&infos)).To(Succeed())
+			}
+		})
+
+		BeforeEach(func() {
+			fakeClient.ListContainersReturns([]executor.Container{task, lrp}, nil)
+		})
+
+		It("lists every container, sorted by guid", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(infos).To(Equal([]rep.ContainerInfo{rep.NewContainerInfo(lrp), rep.NewContainerInfo(task)}))
+		})
+
+		Context("when filtering", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = url.Values{rep.ContainerLifecycleParam: {rep.TaskLifecycle}}.Encode()
+			})
+
+			It("only lists the matching containers", func() {
+				Expect(infos).To(Equal([]rep.ContainerInfo{rep.NewContainerInfo(task)}))
+			})
+		})
+
+		Context("when nothing matches", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = url.Values{rep.ContainerStateParam: {string(executor.StateReserved)}}.Encode()
+			})
+
+			It("returns an empty list", func() {
+				Expect(resp.Body.String()).To(MatchJSON("[]"))
+			})
+		})
+
+		Context("when listing the containers fails", func() {
+			BeforeEach(func() {
+				fakeClient.ListContainersReturns(nil, errors.New("boom"))
+			})
+
+			It("fails", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("ContainerHandler", func() {
+		JustBeforeEach(func() {
+			handlers.NewContainerHandler(fakeClient).ServeHTTP(resp, req, logger)
+		})
+
+		BeforeEach(func() {
+			req.URL.RawQuery = url.Values{":guid": {"lrp-guid"}}.Encode()
+			fakeClient.GetContainerReturns(lrp, nil)
+		})
+
+		It("returns the container", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Body.String()).To(MatchJSON(JSONFor(rep.NewContainerInfo(lrp))))
+
+			_, guid := fakeClient.GetContainerArgsForCall(0)
+			Expect(guid).To(Equal("lrp-guid"))
+		})
+
+		Context("when the container does not exist", func() {
+			BeforeEach(func() {
+				fakeClient.GetContainerReturns(executor.Container{}, executor.ErrContainerNotFound)
+			})
+
+			It("responds with not found", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("when getting the container fails", func() {
+			BeforeEach(func() {
+				fakeClient.GetContainerReturns(executor.Container{}, errors.New("boom"))
+			})
+
+			It("fails", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		Context("when the guid is missing", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = ""
+			})
+
+			It("responds with bad request", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(fakeClient.GetContainerCallCount()).To(BeZero())
+			})
+		})
+	})
+})
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+// stateInvalidator is implemented by cell clients that cache their state,
+// such as auctioncellrep.StateTracker.
+type stateInvalidator interface {
+	Invalidate()
+}
+
+type CordonHandler struct {
+	cordonable evacuation_context.Cordonable
+	cell       auctioncellrep.AuctionCellClient
+	cordon     bool
+}
+
+// Cordon Handler serves the admin routes that stop the cell from taking new
+// work, and let it take work again, without touching its containers
+func NewCordonHandler(cordonable evacuation_context.Cordonable, cell auctioncellrep.AuctionCellClient, cordon bool) *CordonHandler {
+	return &CordonHandler{
+		cordonable: cordonable,
+		cell:       cell,
+		cordon:     cordon,
+	}
+}
+
+func (h *CordonHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if h.cordon {
+		logger = logger.Session("handling-cordon")
+		h.cordonable.Cordon()
+	} else {
+		logger = logger.Session("handling-uncordon")
+		h.cordonable.Uncordon()
+	}
+	logger.Info("done", lager.Data{"cordoned": h.cordon})
+
+	// advertise the change straight away rather than once the cache expires
+	if invalidator, ok := h.cell.(stateInvalidator); ok {
+		invalidator.Invalidate()
+	}
+
+	jsonBytes, err := json.Marshal(map[string]bool{"cordoned": h.cordon})
+	if err != nil {
+		logger.Error("failed-to-marshal-response-payload", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(jsonBytes)))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
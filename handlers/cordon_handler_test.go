@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	executorfakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CordonHandler", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeCordonable   *fake_evacuation_context.FakeCordonable
+		cell             *auctioncellrepfakes.FakeAuctionCellClient
+		responseRecorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeCordonable = new(fake_evacuation_context.FakeCordonable)
+		cell = new(auctioncellrepfakes.FakeAuctionCellClient)
+		responseRecorder = httptest.NewRecorder()
+	})
+
+	serve := func(handler *handlers.CordonHandler, path string) map[string]bool {
+		request, err := http.NewRequest("POST", path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(responseRecorder, request, logger)
+
+		var response map[string]bool
+		Expect(json.Unmarshal(responseRecorder.Body.Bytes(), &response)).To(Succeed())
+		return response
+	}
+
+	It("cordons the cell", func() {
+		response := serve(handlers.NewCordonHandler(fakeCordonable, cell, true), "/cordon")
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(response).To(Equal(map[string]bool{"cordoned": true}))
+		Expect(fakeCordonable.CordonCallCount()).To(Equal(1))
+		Expect(fakeCordonable.UncordonCallCount()).To(Equal(0))
+	})
+
+	It("uncordons the cell", func() {
+		response := serve(handlers.NewCordonHandler(fakeCordonable, cell, false), "/uncordon")
+		Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+		Expect(response).To(Equal(map[string]bool{"cordoned": false}))
+		Expect(fakeCordonable.UncordonCallCount()).To(Equal(1))
+		Expect(fakeCordonable.CordonCallCount()).To(Equal(0))
+	})
+
+	Context("when the cell caches its state", func() {
+		var tracker *auctioncellrep.StateTracker
+
+		BeforeEach(func() {
+			clock := fakeclock.NewFakeClock(time.Now())
+			tracker = auctioncellrep.NewStateTracker(logger, cell, new(executorfakes.FakeClient), clock, time.Minute)
+			cell.StateReturns(rep.CellState{}, true, nil)
+		})
+
+		It("advertises the change straight away", func() {
+			_, _, err := tracker.State(context.Background(), logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			cell.StateReturns(rep.CellState{Cordoned: true}, true, nil)
+			serve(handlers.NewCordonHandler(fakeCordonable, tracker, true), "/cordon")
+
+			state, _, err := tracker.State(context.Background(), logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Cordoned).To(BeTrue())
+			Expect(cell.StateCallCount()).To(Equal(2))
+		})
+	})
+})
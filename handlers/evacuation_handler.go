@@ -6,19 +6,23 @@ import (
 	"strconv"
 
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/auctioncellrep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 )
 
 type EvacuationHandler struct {
 	evacuatable evacuation_context.Evacuatable
+	cell        auctioncellrep.AuctionCellClient
 }
 
 // Evacuation Handler serves a route that is called by the rep drain script
 func NewEvacuationHandler(
 	evacuatable evacuation_context.Evacuatable,
+	cell auctioncellrep.AuctionCellClient,
 ) *EvacuationHandler {
 	return &EvacuationHandler{
 		evacuatable: evacuatable,
+		cell:        cell,
 	}
 }
 
@@ -27,7 +31,12 @@ func (h *EvacuationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, lo
 
 	h.evacuatable.Evacuate()
 
-	jsonBytes, err := json.Marshal(map[string]string{"ping_path": "/ping"})
+	// advertise the evacuation straight away rather than once the cache expires
+	if invalidator, ok := h.cell.(stateInvalidator); ok {
+		invalidator.Invalidate()
+	}
+
+	jsonBytes, err := json.Marshal(map[string]string{"ping_path": "/ping", "status_path": "/evacuation"})
 	if err != nil {
 		logger.Error("failed-to-marshal-response-payload", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 
 	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/handlers"
 
@@ -18,6 +19,7 @@ var _ = Describe("EvacuationHandler", func() {
 		var (
 			logger          *lagertest.TestLogger
 			fakeEvacuatable *fake_evacuation_context.FakeEvacuatable
+			cell            *invalidatingCell
 			handler         *handlers.EvacuationHandler
 
 			responseRecorder *httptest.ResponseRecorder
@@ -27,7 +29,8 @@ var _ = Describe("EvacuationHandler", func() {
 		BeforeEach(func() {
 			logger = lagertest.NewTestLogger("test")
 			fakeEvacuatable = new(fake_evacuation_context.FakeEvacuatable)
-			handler = handlers.NewEvacuationHandler(fakeEvacuatable)
+			cell = &invalidatingCell{FakeAuctionCellClient: new(auctioncellrepfakes.FakeAuctionCellClient)}
+			handler = handlers.NewEvacuationHandler(fakeEvacuatable, cell)
 		})
 
 		Context("when receiving a request", func() {
@@ -45,6 +48,10 @@ var _ = Describe("EvacuationHandler", func() {
 				Expect(fakeEvacuatable.EvacuateCallCount()).To(Equal(1))
 			})
 
+			It("invalidates the cached state of the cell", func() {
+				Expect(cell.invalidations).To(Equal(1))
+			})
+
 			It("responds with 202 ACCEPTED", func() {
 				Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
 			})
@@ -56,6 +63,24 @@ var _ = Describe("EvacuationHandler", func() {
 				Expect(responseValues).To(HaveKey("ping_path"))
 				Expect(responseValues["ping_path"]).To(Equal("/ping"))
 			})
+
+			It("returns the location of the evacuation status endpoint", func() {
+				var responseValues map[string]string
+				err := json.Unmarshal(responseRecorder.Body.Bytes(), &responseValues)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(responseValues["status_path"]).To(Equal("/evacuation"))
+			})
 		})
 	})
 })
+
+// invalidatingCell is a cell client that caches its state, such as
+// auctioncellrep.StateTracker.
+type invalidatingCell struct {
+	*auctioncellrepfakes.FakeAuctionCellClient
+	invalidations int
+}
+
+func (c *invalidatingCell) Invalidate() {
+	c.invalidations++
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+type EvacuationStatusHandler struct {
+	reporter evacuation_context.EvacuationStatusReporter
+}
+
+// Evacuation Status Handler serves a route that the rep drain script polls
+// to follow the progress of an evacuation
+func NewEvacuationStatusHandler(
+	reporter evacuation_context.EvacuationStatusReporter,
+) *EvacuationStatusHandler {
+	return &EvacuationStatusHandler{
+		reporter: reporter,
+	}
+}
+
+func (h *EvacuationStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("handling-evacuation-status")
+
+	status := h.reporter.EvacuationStatus(logger)
+
+	jsonBytes, err := json.Marshal(status)
+	if err != nil {
+		logger.Error("failed-to-marshal-response-payload", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(jsonBytes)))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
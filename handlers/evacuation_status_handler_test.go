@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EvacuationStatusHandler", func() {
+	Describe("ServeHTTP", func() {
+		var (
+			logger       *lagertest.TestLogger
+			fakeReporter *fake_evacuation_context.FakeEvacuationStatusReporter
+			handler      *handlers.EvacuationStatusHandler
+			status       evacuation_context.EvacuationStatus
+
+			responseRecorder *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			fakeReporter = new(fake_evacuation_context.FakeEvacuationStatusReporter)
+			handler = handlers.NewEvacuationStatusHandler(fakeReporter)
+
+			startedAt := time.Unix(1000, 0).UTC()
+			deadline := startedAt.Add(10 * time.Minute)
+			status = evacuation_context.EvacuationStatus{
+				Active:         true,
+				StartedAt:      &startedAt,
+				Deadline:       &deadline,
+				RemainingLRPs:  map[string]int{"running": 2, "created": 1},
+				RemainingTasks: map[string]int{"running": 1},
+				HandedOffLRPs:  3,
+			}
+			fakeReporter.EvacuationStatusReturns(status)
+
+			responseRecorder = httptest.NewRecorder()
+			request, err := http.NewRequest("GET", "/evacuation", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(responseRecorder, request, logger)
+		})
+
+		It("responds with 200 OK", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		})
+
+		It("returns the status of the evacuation", func() {
+			Expect(fakeReporter.EvacuationStatusCallCount()).To(Equal(1))
+
+			var returnedStatus evacuation_context.EvacuationStatus
+			err := json.Unmarshal(responseRecorder.Body.Bytes(), &returnedStatus)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(returnedStatus.Active).To(BeTrue())
+			Expect(*returnedStatus.StartedAt).To(BeTemporally("==", *status.StartedAt))
+			Expect(*returnedStatus.Deadline).To(BeTemporally("==", *status.Deadline))
+			Expect(returnedStatus.RemainingLRPs).To(Equal(status.RemainingLRPs))
+			Expect(returnedStatus.RemainingTasks).To(Equal(status.RemainingTasks))
+			Expect(returnedStatus.HandedOffLRPs).To(Equal(3))
+		})
+
+		It("uses snake case field names", func() {
+			var fields map[string]interface{}
+			err := json.Unmarshal(responseRecorder.Body.Bytes(), &fields)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fields).To(HaveKeyWithValue("active", true))
+			Expect(fields).To(HaveKeyWithValue("handed_off_lrps", BeNumerically("==", 3)))
+			Expect(fields).To(HaveKey("started_at"))
+			Expect(fields).To(HaveKey("deadline"))
+			Expect(fields).To(HaveKey("remaining_lrps"))
+			Expect(fields).To(HaveKey("remaining_tasks"))
+		})
+	})
+})
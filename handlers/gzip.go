@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// MaxDecompressedRequestSize is the most a gzipped request body may expand
+// to, so that a small payload cannot make the rep read without bound.
+const MaxDecompressedRequestSize = 64 * 1024 * 1024
+
+// gzipWrap lets callers send gzipped request bodies and ask for gzipped
+// responses, and advertises that the route accepts them.
+func gzipWrap(loggable func(http.ResponseWriter, *http.Request, lager.Logger)) func(http.ResponseWriter, *http.Request, lager.Logger) {
+	return func(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+		w.Header().Set("Accept-Encoding", rep.GzipEncoding)
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if r.Header.Get("Content-Encoding") == rep.GzipEncoding {
+			body, err := gzip.NewReader(r.Body)
+			if err != nil {
+				logger.Error("failed-to-decompress-request", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			defer body.Close()
+			r.Body = http.MaxBytesReader(w, body, MaxDecompressedRequestSize)
+			r.Header.Del("Content-Encoding")
+		}
+
+		if !rep.AcceptsGzip(r.Header.Get("Accept-Encoding")) {
+			loggable(w, r, logger)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer func() {
+			if err := gw.Close(); err != nil {
+				logger.Error("failed-to-compress-response", err)
+			}
+		}()
+		loggable(gw, r, logger)
+	}
+}
+
+// gzipResponseWriter only compresses responses that have a body, so that
+// empty responses such as 304s stay empty.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status int
+	writer *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if w.writer == nil {
+		w.ResponseWriter.Header().Set("Content-Encoding", rep.GzipEncoding)
+		w.ResponseWriter.Header().Del("Content-Length")
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.ResponseWriter.WriteHeader(w.status)
+		w.writer = gzip.NewWriter(w.ResponseWriter)
+	}
+	return w.writer.Write(p)
+}
+
+func (w *gzipResponseWriter) Close() error {
+	if w.writer == nil {
+		if w.status != 0 {
+			w.ResponseWriter.WriteHeader(w.status)
+		}
+		return nil
+	}
+	return w.writer.Close()
+}
@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compression", func() {
+	var repState rep.CellState
+
+	BeforeEach(func() {
+		repState = rep.CellState{Zone: "some-zone"}
+		fakeLocalRep.StateReturns(repState, true, nil)
+	})
+
+	gunzip := func(body []byte) []byte {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		decompressed, err := ioutil.ReadAll(reader)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return decompressed
+	}
+
+	do := func(req *http.Request) (*http.Response, []byte) {
+		resp, err := client.Do(req)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return resp, body
+	}
+
+	It("advertises that it accepts gzipped requests", func() {
+		req, err := requestGenerator.CreateRequest(rep.StateRoute, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, _ := do(req)
+		Expect(resp.Header.Get("Accept-Encoding")).To(Equal(rep.GzipEncoding))
+	})
+
+	It("gzips the state when the caller accepts it", func() {
+		req, err := requestGenerator.CreateRequest(rep.StateRoute, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept-Encoding", rep.GzipEncoding)
+
+		resp, body := do(req)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Encoding")).To(Equal(rep.GzipEncoding))
+		Expect(gunzip(body)).To(MatchJSON(JSONFor(repState)))
+	})
+
+	It("does not gzip empty responses", func() {
+		fakeLocalRep.StateReturns(rep.CellState{}, false, errors.New("boom"))
+		req, err := requestGenerator.CreateRequest(rep.StateRoute, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept-Encoding", rep.GzipEncoding)
+
+		resp, body := do(req)
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+		Expect(body).To(BeEmpty())
+	})
+
+	It("accepts gzipped work", func() {
+		work := rep.Work{Tasks: []rep.Task{rep.NewTask("some-task", "domain", rep.NewResource(1, 2, 3), rep.PlacementConstraint{})}}
+		failedWork := rep.Work{Tasks: work.Tasks}
+		fakeLocalRep.PerformReturns(failedWork, nil)
+
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		Expect(json.NewEncoder(writer).Encode(work)).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+
+		req, err := requestGenerator.CreateRequest(rep.PerformRoute, nil, &compressed)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Content-Encoding", rep.GzipEncoding)
+		req.Header.Set("Accept-Encoding", rep.GzipEncoding)
+
+		resp, body := do(req)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(gunzip(body)).To(MatchJSON(JSONFor(failedWork)))
+		Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
+		_, _, received := fakeLocalRep.PerformArgsForCall(0)
+		Expect(received).To(Equal(work))
+	})
+
+	It("rejects gzipped work that expands beyond the limit", func() {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		padding := bytes.Repeat([]byte(" "), 1024*1024)
+		for written := 0; written <= handlers.MaxDecompressedRequestSize; written += len(padding) {
+			_, err := writer.Write(padding)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(json.NewEncoder(writer).Encode(rep.Work{})).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+		Expect(compressed.Len()).To(BeNumerically("<", handlers.MaxDecompressedRequestSize/100))
+
+		req, err := requestGenerator.CreateRequest(rep.PerformRoute, nil, &compressed)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Content-Encoding", rep.GzipEncoding)
+
+		resp, _ := do(req)
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(fakeLocalRep.PerformCallCount()).To(BeZero())
+	})
+
+	It("rejects a body that is not gzipped", func() {
+		req, err := requestGenerator.CreateRequest(rep.PerformRoute, nil, JSONReaderFor(rep.Work{}))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Content-Encoding", rep.GzipEncoding)
+
+		resp, _ := do(req)
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(fakeLocalRep.PerformCallCount()).To(BeZero())
+	})
+})
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+	"code.cloudfoundry.org/rep/health"
 	"github.com/tedsuo/rata"
 )
 
@@ -15,6 +18,10 @@ func New(
 	localCellClient auctioncellrep.AuctionCellClient,
 	executorClient executor.Client,
 	evacuatable evacuation_context.Evacuatable,
+	evacuationStatusReporter evacuation_context.EvacuationStatusReporter,
+	evacuationAborter evacuation_context.EvacuationAborter,
+	cordonable evacuation_context.Cordonable,
+	healthReporter health.Reporter,
 	logger lager.Logger,
 	secure bool,
 ) rata.Handlers {
@@ -26,19 +33,41 @@ func New(
 		resetHandler := &reset{rep: localCellClient}
 		stopLrpHandler := NewStopLRPInstanceHandler(executorClient)
 		cancelTaskHandler := NewCancelTaskHandler(executorClient)
+		batchPool := NewWorkPool(BatchWorkPoolSize)
+		stopLrpsHandler := NewStopLRPInstancesHandler(executorClient, batchPool)
+		cancelTasksHandler := NewCancelTasksHandler(executorClient, batchPool)
+		containersHandler := NewContainersHandler(executorClient)
+		containerHandler := NewContainerHandler(executorClient)
 
-		handlers[rep.StateRoute] = logWrap(stateHandler.ServeHTTP, logger)
-		handlers[rep.PerformRoute] = logWrap(performHandler.ServeHTTP, logger)
+		handlers[rep.StateRoute] = logWrap(gzipWrap(stateHandler.ServeHTTP), logger)
+		handlers[rep.PerformRoute] = logWrap(gzipWrap(performHandler.ServeHTTP), logger)
 		handlers[rep.Sim_ResetRoute] = logWrap(resetHandler.ServeHTTP, logger)
 
 		handlers[rep.StopLRPInstanceRoute] = logWrap(stopLrpHandler.ServeHTTP, logger)
 		handlers[rep.CancelTaskRoute] = logWrap(cancelTaskHandler.ServeHTTP, logger)
+		handlers[rep.StopLRPInstancesRoute] = logWrap(stopLrpsHandler.ServeHTTP, logger)
+		handlers[rep.CancelTasksRoute] = logWrap(cancelTasksHandler.ServeHTTP, logger)
+
+		handlers[rep.ContainersRoute] = logWrap(containersHandler.ServeHTTP, logger)
+		handlers[rep.ContainerRoute] = logWrap(containerHandler.ServeHTTP, logger)
 	} else {
 		pingHandler := NewPingHandler()
-		evacuationHandler := NewEvacuationHandler(evacuatable)
+		evacuationHandler := NewEvacuationHandler(evacuatable, localCellClient)
+		evacuationStatusHandler := NewEvacuationStatusHandler(evacuationStatusReporter)
+		abortEvacuationHandler := NewAbortEvacuationHandler(evacuationAborter, localCellClient)
+		cordonHandler := NewCordonHandler(cordonable, localCellClient, true)
+		uncordonHandler := NewCordonHandler(cordonable, localCellClient, false)
+		healthHandler := NewHealthHandler(healthReporter, false)
+		readyHandler := NewHealthHandler(healthReporter, true)
 
 		handlers[rep.PingRoute] = logWrap(pingHandler.ServeHTTP, logger)
 		handlers[rep.EvacuateRoute] = logWrap(evacuationHandler.ServeHTTP, logger)
+		handlers[rep.EvacuationStatusRoute] = logWrap(evacuationStatusHandler.ServeHTTP, logger)
+		handlers[rep.AbortEvacuationRoute] = logWrap(abortEvacuationHandler.ServeHTTP, logger)
+		handlers[rep.CordonRoute] = logWrap(cordonHandler.ServeHTTP, logger)
+		handlers[rep.UncordonRoute] = logWrap(uncordonHandler.ServeHTTP, logger)
+		handlers[rep.HealthRoute] = logWrap(healthHandler.ServeHTTP, logger)
+		handlers[rep.ReadyRoute] = logWrap(readyHandler.ServeHTTP, logger)
 	}
 
 	return handlers
@@ -48,10 +77,14 @@ func NewLegacy(
 	localCellClient auctioncellrep.AuctionCellClient,
 	executorClient executor.Client,
 	evacuatable evacuation_context.Evacuatable,
+	evacuationStatusReporter evacuation_context.EvacuationStatusReporter,
+	evacuationAborter evacuation_context.EvacuationAborter,
+	cordonable evacuation_context.Cordonable,
+	healthReporter health.Reporter,
 	logger lager.Logger,
 ) rata.Handlers {
-	insecureHandlers := New(localCellClient, executorClient, evacuatable, logger, false)
-	secureHandlers := New(localCellClient, executorClient, evacuatable, logger, true)
+	insecureHandlers := New(localCellClient, executorClient, evacuatable, evacuationStatusReporter, evacuationAborter, cordonable, healthReporter, logger, false)
+	secureHandlers := New(localCellClient, executorClient, evacuatable, evacuationStatusReporter, evacuationAborter, cordonable, healthReporter, logger, true)
 	for name, handler := range secureHandlers {
 		insecureHandlers[name] = handler
 	}
@@ -68,6 +101,49 @@ func logWrap(loggable func(http.ResponseWriter, *http.Request, lager.Logger), lo
 		defer requestLog.Debug("done")
 		requestLog.Debug("serving")
 
+		r, cancel, ok := withRequestTimeout(r, requestLog)
+		if !ok {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		defer cancel()
+
 		loggable(w, r, requestLog)
 	}
 }
+
+// callerGaveUp responds with a gateway timeout once the context of the
+// request is done, since nobody is waiting for the result any more.
+func callerGaveUp(w http.ResponseWriter, r *http.Request, logger lager.Logger) bool {
+	err := r.Context().Err()
+	if err == nil {
+		return false
+	}
+
+	logger.Info("caller-gave-up", lager.Data{"error": err.Error()})
+	w.WriteHeader(http.StatusGatewayTimeout)
+	return true
+}
+
+// withRequestTimeout bounds the context of the request by the timeout the
+// caller sent, and reports false if the caller has already given up.
+func withRequestTimeout(r *http.Request, logger lager.Logger) (*http.Request, context.CancelFunc, bool) {
+	header := r.Header.Get(rep.RequestTimeoutHeader)
+	if header == "" {
+		return r, func() {}, true
+	}
+
+	timeout, err := time.ParseDuration(header)
+	if err != nil {
+		logger.Error("invalid-request-timeout", err, lager.Data{"timeout": header})
+		return r, func() {}, true
+	}
+
+	if timeout <= 0 {
+		logger.Info("request-timeout-exceeded", lager.Data{"timeout": header})
+		return r, func() {}, false
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return r.WithContext(ctx), cancel, true
+}
@@ -14,6 +14,7 @@ import (
 	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/rep/health/healthfakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -31,6 +32,7 @@ var server *httptest.Server
 var requestGenerator *rata.RequestGenerator
 var client *http.Client
 var fakeLocalRep *auctioncellrepfakes.FakeAuctionCellClient
+var fakeExecutorClient *executorfakes.FakeClient
 var repGuid string
 var logger *lagertest.TestLogger
 
@@ -38,9 +40,13 @@ var _ = BeforeEach(func() {
 	logger = lagertest.NewTestLogger("handlers")
 
 	fakeLocalRep = new(auctioncellrepfakes.FakeAuctionCellClient)
-	fakeExecutorClient := new(executorfakes.FakeClient)
+	fakeExecutorClient = new(executorfakes.FakeClient)
 	fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
-	handler, err := rata.NewRouter(rep.Routes, handlers.NewLegacy(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, logger))
+	fakeEvacuationStatusReporter := new(fake_evacuation_context.FakeEvacuationStatusReporter)
+	fakeEvacuationAborter := new(fake_evacuation_context.FakeEvacuationAborter)
+	fakeCordonable := new(fake_evacuation_context.FakeCordonable)
+	fakeHealthReporter := new(healthfakes.FakeReporter)
+	handler, err := rata.NewRouter(rep.Routes, handlers.NewLegacy(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, fakeEvacuationStatusReporter, fakeEvacuationAborter, fakeCordonable, fakeHealthReporter, logger))
 	Expect(err).NotTo(HaveOccurred())
 	server = httptest.NewServer(handler)
 
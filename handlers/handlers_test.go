@@ -4,6 +4,7 @@ import (
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/rep/health/healthfakes"
 
 	executorfakes "code.cloudfoundry.org/executor/fakes"
 
@@ -17,7 +18,11 @@ var _ = Describe("NewLegacy", func() {
 
 		fakeExecutorClient := new(executorfakes.FakeClient)
 		fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
-		handlers := handlers.NewLegacy(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, logger)
+		fakeEvacuationStatusReporter := new(fake_evacuation_context.FakeEvacuationStatusReporter)
+		fakeEvacuationAborter := new(fake_evacuation_context.FakeEvacuationAborter)
+		fakeCordonable := new(fake_evacuation_context.FakeCordonable)
+		fakeHealthReporter := new(healthfakes.FakeReporter)
+		handlers := handlers.NewLegacy(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, fakeEvacuationStatusReporter, fakeEvacuationAborter, fakeCordonable, fakeHealthReporter, logger)
 
 		for _, route := range rep.Routes {
 			Expect(handlers[route.Name]).NotTo(BeNil())
@@ -32,7 +37,11 @@ var _ = Describe("New", func() {
 		BeforeEach(func() {
 			fakeExecutorClient := new(executorfakes.FakeClient)
 			fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
-			test_handlers = handlers.New(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, logger, false)
+			fakeEvacuationStatusReporter := new(fake_evacuation_context.FakeEvacuationStatusReporter)
+			fakeEvacuationAborter := new(fake_evacuation_context.FakeEvacuationAborter)
+			fakeCordonable := new(fake_evacuation_context.FakeCordonable)
+			fakeHealthReporter := new(healthfakes.FakeReporter)
+			test_handlers = handlers.New(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, fakeEvacuationStatusReporter, fakeEvacuationAborter, fakeCordonable, fakeHealthReporter, logger, false)
 		})
 
 		It("has no secure routes", func() {
@@ -52,7 +61,11 @@ var _ = Describe("New", func() {
 		BeforeEach(func() {
 			fakeExecutorClient := new(executorfakes.FakeClient)
 			fakeEvacuatable := new(fake_evacuation_context.FakeEvacuatable)
-			test_handlers = handlers.New(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, logger, true)
+			fakeEvacuationStatusReporter := new(fake_evacuation_context.FakeEvacuationStatusReporter)
+			fakeEvacuationAborter := new(fake_evacuation_context.FakeEvacuationAborter)
+			fakeCordonable := new(fake_evacuation_context.FakeCordonable)
+			fakeHealthReporter := new(healthfakes.FakeReporter)
+			test_handlers = handlers.New(fakeLocalRep, fakeExecutorClient, fakeEvacuatable, fakeEvacuationStatusReporter, fakeEvacuationAborter, fakeCordonable, fakeHealthReporter, logger, true)
 		})
 
 		It("has all the secure routes", func() {
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/health"
+)
+
+type HealthHandler struct {
+	reporter  health.Reporter
+	readiness bool
+}
+
+// Health Handler serves the liveness and readiness routes that monitoring
+// and the rep drain script poll. Both report every component of the rep,
+// and respond with 503 when the cell is not alive, or not ready for work.
+func NewHealthHandler(reporter health.Reporter, readiness bool) *HealthHandler {
+	return &HealthHandler{
+		reporter:  reporter,
+		readiness: readiness,
+	}
+}
+
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	var report health.Report
+	if h.readiness {
+		logger = logger.Session("handling-ready")
+		report = h.reporter.Readiness(logger)
+	} else {
+		logger = logger.Session("handling-health")
+		report = h.reporter.Liveness(logger)
+	}
+
+	jsonBytes, err := json.Marshal(report)
+	if err != nil {
+		logger.Error("failed-to-marshal-response-payload", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if !report.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(jsonBytes)))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonBytes)
+}
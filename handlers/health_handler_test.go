@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/rep/health"
+	"code.cloudfoundry.org/rep/health/healthfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HealthHandler", func() {
+	var (
+		logger             *lagertest.TestLogger
+		fakeHealthReporter *healthfakes.FakeReporter
+		responseRecorder   *httptest.ResponseRecorder
+		healthyReport      health.Report
+		unhealthyReport    health.Report
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeHealthReporter = new(healthfakes.FakeReporter)
+		responseRecorder = httptest.NewRecorder()
+
+		healthyReport = health.Report{
+			Healthy: true,
+			Components: []health.ComponentStatus{
+				{Name: "executor", Healthy: true, Liveness: true, LatencyMS: 1.5},
+			},
+		}
+		unhealthyReport = health.Report{
+			Healthy: false,
+			Components: []health.ComponentStatus{
+				{Name: "bbs", Healthy: false, LatencyMS: 3, Error: "bbs is unreachable"},
+			},
+		}
+	})
+
+	serve := func(handler *handlers.HealthHandler, path string) health.Report {
+		request, err := http.NewRequest("GET", path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(responseRecorder, request, logger)
+
+		var report health.Report
+		Expect(json.Unmarshal(responseRecorder.Body.Bytes(), &report)).To(Succeed())
+		return report
+	}
+
+	Describe("liveness", func() {
+		It("responds with the liveness report", func() {
+			fakeHealthReporter.LivenessReturns(healthyReport)
+
+			report := serve(handlers.NewHealthHandler(fakeHealthReporter, false), "/health")
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(report).To(Equal(healthyReport))
+			Expect(fakeHealthReporter.LivenessCallCount()).To(Equal(1))
+			Expect(fakeHealthReporter.ReadinessCallCount()).To(Equal(0))
+		})
+
+		It("responds with 503 when the rep is not alive", func() {
+			fakeHealthReporter.LivenessReturns(unhealthyReport)
+
+			report := serve(handlers.NewHealthHandler(fakeHealthReporter, false), "/health")
+			Expect(responseRecorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(report).To(Equal(unhealthyReport))
+		})
+	})
+
+	Describe("readiness", func() {
+		It("responds with the readiness report", func() {
+			fakeHealthReporter.ReadinessReturns(healthyReport)
+
+			report := serve(handlers.NewHealthHandler(fakeHealthReporter, true), "/ready")
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(report).To(Equal(healthyReport))
+			Expect(fakeHealthReporter.ReadinessCallCount()).To(Equal(1))
+			Expect(fakeHealthReporter.LivenessCallCount()).To(Equal(0))
+		})
+
+		It("responds with 503 when the rep is not ready", func() {
+			fakeHealthReporter.ReadinessReturns(unhealthyReport)
+
+			report := serve(handlers.NewHealthHandler(fakeHealthReporter, true), "/ready")
+			Expect(responseRecorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(report).To(Equal(unhealthyReport))
+		})
+	})
+})
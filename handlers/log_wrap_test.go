@@ -2,13 +2,17 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"net/http"
+	"time"
 
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/rata"
 )
 
 var _ = Describe("LogWrap", func() {
@@ -18,4 +22,78 @@ var _ = Describe("LogWrap", func() {
 		Expect(logger.Buffer()).To(gbytes.Say("serving"))
 		Expect(logger.Buffer()).To(gbytes.Say("done"))
 	})
+
+	Describe("request timeouts", func() {
+		perform := func(timeout string) int {
+			request, err := requestGenerator.CreateRequest(rep.PerformRoute, nil, JSONReaderFor(rep.Work{}))
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set(rep.RequestTimeoutHeader, timeout)
+
+			response, err := client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+			return response.StatusCode
+		}
+
+		It("serves requests the caller is still waiting for", func() {
+			Expect(perform("1m")).To(Equal(http.StatusOK))
+			Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
+		})
+
+		It("rejects requests the caller has already given up on", func() {
+			Expect(perform("-1s")).To(Equal(http.StatusGatewayTimeout))
+			Expect(fakeLocalRep.PerformCallCount()).To(BeZero())
+			Expect(logger.Buffer()).To(gbytes.Say("request-timeout-exceeded"))
+		})
+
+		It("does not perform work once the timeout has passed", func() {
+			Expect(perform("1ns")).To(Equal(http.StatusGatewayTimeout))
+			Expect(fakeLocalRep.PerformCallCount()).To(BeZero())
+		})
+
+		It("ignores an invalid timeout", func() {
+			Expect(perform("soon")).To(Equal(http.StatusOK))
+			Expect(logger.Buffer()).To(gbytes.Say("invalid-request-timeout"))
+		})
+
+		It("passes the timeout on to the cell", func() {
+			Expect(perform("1m")).To(Equal(http.StatusOK))
+			ctx, _, _ := fakeLocalRep.PerformArgsForCall(0)
+			deadline, ok := ctx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), 5*time.Second))
+		})
+
+		It("gives up on work the cell abandons once the timeout passes", func() {
+			fakeLocalRep.PerformStub = func(ctx context.Context, _ lager.Logger, _ rep.Work) (rep.Work, error) {
+				<-ctx.Done()
+				return rep.Work{}, ctx.Err()
+			}
+			Expect(perform("50ms")).To(Equal(http.StatusGatewayTimeout))
+		})
+
+		It("does not stop an instance once the timeout has passed", func() {
+			request, err := requestGenerator.CreateRequest(rep.StopLRPInstanceRoute, rata.Params{"process_guid": "pg", "instance_guid": "ig", "index": "0"}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set(rep.RequestTimeoutHeader, "1ns")
+
+			response, err := client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+			Expect(response.StatusCode).To(Equal(http.StatusGatewayTimeout))
+			Expect(fakeExecutorClient.StopContainerCallCount()).To(BeZero())
+		})
+
+		It("does not cancel a task once the timeout has passed", func() {
+			request, err := requestGenerator.CreateRequest(rep.CancelTaskRoute, rata.Params{"task_guid": "task-guid"}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set(rep.RequestTimeoutHeader, "1ns")
+
+			response, err := client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+			Expect(response.StatusCode).To(Equal(http.StatusGatewayTimeout))
+			Consistently(fakeExecutorClient.DeleteContainerCallCount).Should(BeZero())
+		})
+	})
 })
@@ -24,8 +24,16 @@ func (h *perform) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager
 		return
 	}
 
-	failedWork, err := h.rep.Perform(logger, work)
+	if callerGaveUp(w, r, logger) {
+		return
+	}
+
+	failedWork, err := h.rep.Perform(r.Context(), logger, work)
 	if err != nil {
+		if callerGaveUp(w, r, logger) {
+			return
+		}
+
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error("failed-to-perform-work", err)
 		return
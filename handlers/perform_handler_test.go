@@ -49,7 +49,7 @@ var _ = Describe("Perform", func() {
 				Expect(body).To(MatchJSON(JSONFor(failedWork)))
 
 				Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
-				_, actualWork := fakeLocalRep.PerformArgsForCall(0)
+				_, _, actualWork := fakeLocalRep.PerformArgsForCall(0)
 				Expect(actualWork).To(Equal(requestedWork))
 			})
 		})
@@ -67,7 +67,7 @@ var _ = Describe("Perform", func() {
 				Expect(body).To(BeEmpty())
 
 				Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
-				_, actualWork := fakeLocalRep.PerformArgsForCall(0)
+				_, _, actualWork := fakeLocalRep.PerformArgsForCall(0)
 				Expect(actualWork).To(Equal(requestedWork))
 			})
 		})
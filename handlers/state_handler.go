@@ -3,8 +3,10 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
 )
 
@@ -15,13 +17,72 @@ type state struct {
 func (h *state) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
 	logger = logger.Session("auction-fetch-state")
 
-	state, healthy, err := h.rep.State(logger)
+	if versioned, ok := h.rep.(auctioncellrep.VersionedStateClient); ok {
+		h.serveVersioned(w, r, logger, versioned)
+		return
+	}
+
+	state, healthy, err := h.rep.State(r.Context(), logger)
 	if err != nil {
+		if callerGaveUp(w, r, logger) {
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error("failed-to-fetch-state", err)
 		return
 	}
 
+	h.respond(w, r, logger, state, healthy)
+}
+
+func (h *state) serveVersioned(w http.ResponseWriter, r *http.Request, logger lager.Logger, versioned auctioncellrep.VersionedStateClient) {
+	var known rep.StateVersion
+	if etag := r.Header.Get("If-None-Match"); etag != "" {
+		var err error
+		known, err = rep.ParseStateETag(etag)
+		if err != nil {
+			logger.Info("ignoring-invalid-etag", lager.Data{"etag": etag})
+		}
+	}
+
+	wait, err := stateWait(r)
+	if err != nil {
+		logger.Error("invalid-wait", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var state rep.CellState
+	var version rep.StateVersion
+	var healthy bool
+	if wait > 0 && !known.IsZero() {
+		state, version, healthy, err = versioned.WaitForStateChange(r.Context(), logger, known, wait)
+	} else {
+		state, version, healthy, err = versioned.VersionedState(r.Context(), logger)
+	}
+	if err != nil {
+		if callerGaveUp(w, r, logger) {
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error("failed-to-fetch-state", err)
+		return
+	}
+
+	w.Header().Set("ETag", version.ETag())
+	if version == known {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.respond(w, r, logger, state, healthy)
+}
+
+func (h *state) respond(w http.ResponseWriter, r *http.Request, logger lager.Logger, state rep.CellState, healthy bool) {
+	if callerGaveUp(w, r, logger) {
+		return
+	}
+
 	if !healthy {
 		logger.Info("cell-not-healthy")
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -29,3 +90,19 @@ func (h *state) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.L
 
 	json.NewEncoder(w).Encode(state)
 }
+
+func stateWait(r *http.Request) (time.Duration, error) {
+	param := r.URL.Query().Get(rep.StateWaitParam)
+	if param == "" {
+		return 0, nil
+	}
+
+	wait, err := time.ParseDuration(param)
+	if err != nil {
+		return 0, err
+	}
+	if wait > rep.MaxStateWait {
+		wait = rep.MaxStateWait
+	}
+	return wait, nil
+}
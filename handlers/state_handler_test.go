@@ -1,10 +1,21 @@
 package handlers_test
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"time"
 
+	"code.cloudfoundry.org/clock"
+	executorfakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/rep/health/healthfakes"
+	"github.com/tedsuo/rata"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -51,4 +62,107 @@ var _ = Describe("State", func() {
 			Expect(fakeLocalRep.StateCallCount()).To(Equal(1))
 		})
 	})
+
+	Context("when the cell versions its state", func() {
+		var (
+			tracker          *auctioncellrep.StateTracker
+			versionedServer  *httptest.Server
+			versionedRequest func(etag, wait string) *http.Response
+		)
+
+		BeforeEach(func() {
+			tracker = auctioncellrep.NewStateTracker(logger, fakeLocalRep, new(executorfakes.FakeClient), clock.NewClock(), 0)
+			router, err := rata.NewRouter(rep.Routes, handlers.NewLegacy(tracker, new(executorfakes.FakeClient), new(fake_evacuation_context.FakeEvacuatable), new(fake_evacuation_context.FakeEvacuationStatusReporter), new(fake_evacuation_context.FakeEvacuationAborter), new(fake_evacuation_context.FakeCordonable), new(healthfakes.FakeReporter), logger))
+			Expect(err).NotTo(HaveOccurred())
+			versionedServer = httptest.NewServer(router)
+
+			versionedRequest = func(etag, wait string) *http.Response {
+				req, err := rata.NewRequestGenerator(versionedServer.URL, rep.Routes).CreateRequest(rep.StateRoute, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				if etag != "" {
+					req.Header.Set("If-None-Match", etag)
+				}
+				if wait != "" {
+					req.URL.RawQuery = rep.StateWaitParam + "=" + wait
+				}
+				resp, err := client.Do(req)
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				return resp
+			}
+		})
+
+		AfterEach(func() {
+			versionedServer.Close()
+		})
+
+		It("returns the version of the state as an etag", func() {
+			resp := versionedRequest("", "")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			version, err := rep.ParseStateETag(resp.Header.Get("ETag"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.Number).To(BeNumerically(">", 0))
+		})
+
+		It("returns not modified when the state matches the etag", func() {
+			etag := versionedRequest("", "").Header.Get("ETag")
+
+			resp := versionedRequest(etag, "")
+			Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+			Expect(resp.Header.Get("ETag")).To(Equal(etag))
+		})
+
+		It("returns the state when it has changed since the etag", func() {
+			etag := versionedRequest("", "").Header.Get("ETag")
+			fakeLocalRep.StateReturns(rep.CellState{Zone: "other-zone"}, true, nil)
+
+			resp := versionedRequest(etag, "")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("ETag")).NotTo(Equal(etag))
+		})
+
+		It("ignores an invalid etag", func() {
+			resp := versionedRequest("garbage", "")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		Context("when long-polling", func() {
+			It("returns as soon as the state changes", func() {
+				etag := versionedRequest("", "").Header.Get("ETag")
+
+				changed := make(chan struct{})
+				fakeLocalRep.StateStub = func(context.Context, lager.Logger) (rep.CellState, bool, error) {
+					select {
+					case <-changed:
+						return rep.CellState{Zone: "other-zone"}, true, nil
+					default:
+						return repState, true, nil
+					}
+				}
+
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					close(changed)
+					tracker.Invalidate()
+				}()
+
+				resp := versionedRequest(etag, "10s")
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Header.Get("ETag")).NotTo(Equal(etag))
+			})
+
+			It("returns not modified when the wait elapses", func() {
+				etag := versionedRequest("", "").Header.Get("ETag")
+
+				resp := versionedRequest(etag, "50ms")
+				Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+			})
+
+			It("rejects an invalid wait", func() {
+				resp := versionedRequest("", "forever")
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
 })
@@ -40,6 +40,10 @@ func (h StopLRPInstanceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if callerGaveUp(w, r, logger) {
+		return
+	}
+
 	err := h.client.StopContainer(logger, rep.LRPContainerGuid(processGuid, instanceGuid))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
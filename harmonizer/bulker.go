@@ -2,6 +2,7 @@ package harmonizer
 
 import (
 	"os"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/clock"
@@ -24,6 +25,9 @@ type Bulker struct {
 	generator              generator.Generator
 	queue                  operationq.Queue
 	metronClient           loggregator_v2.IngressClient
+
+	lock     sync.Mutex
+	lastSync time.Time
 }
 
 func NewBulker(
@@ -51,6 +55,7 @@ func NewBulker(
 
 func (b *Bulker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	evacuateNotify := b.evacuationNotifier.EvacuateNotify()
+	var resumeNotify <-chan struct{}
 	close(ready)
 
 	logger := b.logger.Session("running-bulker")
@@ -72,10 +77,19 @@ func (b *Bulker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 		case <-evacuateNotify:
 			timer.Stop()
 			evacuateNotify = nil
+			resumeNotify = b.evacuationNotifier.ResumeNotify()
 
 			logger.Info("notified-of-evacuation")
 			interval = b.evacuationPollInterval
 
+		case <-resumeNotify:
+			timer.Stop()
+			resumeNotify = nil
+			evacuateNotify = b.evacuationNotifier.EvacuateNotify()
+
+			logger.Info("notified-of-resumption")
+			interval = b.pollInterval
+
 		case signal := <-signals:
 			logger.Info("received-signal", lager.Data{"signal": signal.String()})
 			return nil
@@ -111,4 +125,16 @@ func (b *Bulker) sync(logger lager.Logger) {
 	for _, operation := range ops {
 		b.queue.Push(operation)
 	}
+
+	b.lock.Lock()
+	b.lastSync = endTime
+	b.lock.Unlock()
+}
+
+// LastSync returns when the bulker last generated operations successfully,
+// or the zero time if it never has.
+func (b *Bulker) LastSync() time.Time {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.lastSync
 }
@@ -30,6 +30,7 @@ var _ = Describe("Bulker", func() {
 		fakeQueue              *fake_operationq.FakeQueue
 		evacuatable            evacuation_context.Evacuatable
 		evacuationNotifier     evacuation_context.EvacuationNotifier
+		resumable              evacuation_context.Resumable
 		fakeMetronClient       *mfakes.FakeIngressClient
 
 		bulker  *harmonizer.Bulker
@@ -45,7 +46,7 @@ var _ = Describe("Bulker", func() {
 		fakeQueue = new(fake_operationq.FakeQueue)
 		fakeMetronClient = new(mfakes.FakeIngressClient)
 
-		evacuatable, _, evacuationNotifier = evacuation_context.New()
+		evacuatable, _, evacuationNotifier, resumable = evacuation_context.New()
 
 		bulker = harmonizer.NewBulker(
 			logger,
@@ -103,6 +104,11 @@ var _ = Describe("Bulker", func() {
 				Expect(name).To(Equal("RepBulkSyncDuration"))
 				Expect(value).To(BeNumerically("==", 10*time.Second))
 			})
+
+			It("records when it last synced", func() {
+				Eventually(fakeQueue.PushCallCount).Should(Equal(expectedQueueLength))
+				Eventually(bulker.LastSync).Should(Equal(fakeClock.Now()))
+			})
 		})
 
 		Context("when generating the batch operations fails", func() {
@@ -116,6 +122,11 @@ var _ = Describe("Bulker", func() {
 				Eventually(logger).Should(gbytes.Say("failed-to-generate-operations"))
 				Eventually(logger).Should(gbytes.Say("nope"))
 			})
+
+			It("does not record a sync", func() {
+				Eventually(logger).Should(gbytes.Say("failed-to-generate-operations"))
+				Expect(bulker.LastSync()).To(BeZero())
+			})
 		})
 	}
 
@@ -165,5 +176,36 @@ var _ = Describe("Bulker", func() {
 				Consistently(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
 			})
 		})
+
+		Context("when the evacuation is aborted", func() {
+			JustBeforeEach(func() {
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+				resumable.Resume()
+			})
+
+			It("batches operations straight away", func() {
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
+			})
+
+			It("goes back to the normal polling interval", func() {
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
+
+				fakeClock.WaitForWatcherAndIncrement(evacuationPollInterval + time.Second)
+				Consistently(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
+
+				fakeClock.Increment(pollInterval)
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(3))
+			})
+
+			It("switches to the evacuation interval when evacuating again", func() {
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(2))
+
+				evacuatable.Evacuate()
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(3))
+
+				fakeClock.WaitForWatcherAndIncrement(evacuationPollInterval + time.Second)
+				Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(4))
+			})
+		})
 	})
 })
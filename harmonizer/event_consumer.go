@@ -2,6 +2,7 @@ package harmonizer
 
 import (
 	"os"
+	"sync"
 
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
@@ -14,6 +15,9 @@ type EventConsumer struct {
 	executorClient executor.Client
 	generator      generator.Generator
 	queue          operationq.Queue
+
+	lock       sync.Mutex
+	subscribed bool
 }
 
 func NewEventConsumer(
@@ -39,6 +43,9 @@ func (consumer *EventConsumer) Run(signals <-chan os.Signal, ready chan<- struct
 		return err
 	}
 
+	consumer.setSubscribed(true)
+	defer consumer.setSubscribed(false)
+
 	close(ready)
 	logger.Info("started")
 
@@ -60,3 +67,17 @@ func (consumer *EventConsumer) Run(signals <-chan os.Signal, ready chan<- struct
 
 	return nil
 }
+
+// Subscribed reports whether the consumer is receiving operations from the
+// event stream.
+func (consumer *EventConsumer) Subscribed() bool {
+	consumer.lock.Lock()
+	defer consumer.lock.Unlock()
+	return consumer.subscribed
+}
+
+func (consumer *EventConsumer) setSubscribed(subscribed bool) {
+	consumer.lock.Lock()
+	consumer.subscribed = subscribed
+	consumer.lock.Unlock()
+}
@@ -53,6 +53,11 @@ var _ = Describe("EventConsumer", func() {
 			fakeGenerator.OperationStreamReturns(operations, nil)
 		})
 
+		It("reports that it is subscribed", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+			Expect(consumer.Subscribed()).To(BeTrue())
+		})
+
 		Context("when an operation is received", func() {
 			var fakeOperation *fake_operationq.FakeOperation
 
@@ -74,6 +79,13 @@ var _ = Describe("EventConsumer", func() {
 
 				Eventually(process.Wait()).Should(Receive(BeNil()))
 			})
+
+			It("no longer reports that it is subscribed", func() {
+				close(receivedOperations)
+
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+				Expect(consumer.Subscribed()).To(BeFalse())
+			})
 		})
 	})
 
@@ -87,5 +99,10 @@ var _ = Describe("EventConsumer", func() {
 		It("exits with failure", func() {
 			Eventually(process.Wait()).Should(Receive(Equal(disaster)))
 		})
+
+		It("does not report that it is subscribed", func() {
+			Eventually(process.Wait()).Should(Receive())
+			Expect(consumer.Subscribed()).To(BeFalse())
+		})
 	})
 })
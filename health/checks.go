@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+	"code.cloudfoundry.org/rep/maintain"
+)
+
+var (
+	ErrExecutorUnhealthy = errors.New("executor is unhealthy")
+	ErrBBSUnreachable    = errors.New("bbs is unreachable")
+	ErrNotSubscribed     = errors.New("not subscribed to the event stream")
+	ErrEvacuating        = errors.New("cell is evacuating")
+	ErrCordoned          = errors.New("cell is cordoned")
+	ErrPresenceNotOwned  = errors.New("presence of the cell is held by another owner")
+)
+
+// Subscriber is implemented by harmonizer.EventConsumer.
+type Subscriber interface {
+	Subscribed() bool
+}
+
+// Syncer is implemented by harmonizer.Bulker.
+type Syncer interface {
+	LastSync() time.Time
+}
+
+// ExecutorCheck fails when the executor cannot be reached or its garden
+// health check is failing.
+func ExecutorCheck(executorClient executor.Client) Check {
+	return func(logger lager.Logger) error {
+		if err := executorClient.Ping(logger); err != nil {
+			return err
+		}
+		if !executorClient.Healthy(logger) {
+			return ErrExecutorUnhealthy
+		}
+		return nil
+	}
+}
+
+func BBSCheck(bbsClient bbs.InternalClient) Check {
+	return func(logger lager.Logger) error {
+		if !bbsClient.Ping(logger) {
+			return ErrBBSUnreachable
+		}
+		return nil
+	}
+}
+
+// ConsulPresenceCheck fails when the presence of the cell is not held in
+// consul, or is held for a rep at another address.
+func ConsulPresenceCheck(serviceClient maintain.CellPresenceClient, cellID, repAddress string) Check {
+	return func(logger lager.Logger) error {
+		presence, err := serviceClient.CellById(logger, cellID)
+		if err != nil {
+			return err
+		}
+		if presence.RepAddress != repAddress {
+			return ErrPresenceNotOwned
+		}
+		return nil
+	}
+}
+
+// LocketPresenceCheck fails when the presence of the cell is not held in
+// locket by owner. The fetch is cancelled after timeout, so that a hung
+// locket does not leave a request behind for every probe.
+func LocketPresenceCheck(locketClient locketmodels.LocketClient, cellID, owner string, timeout time.Duration) Check {
+	return func(logger lager.Logger) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		response, err := locketClient.Fetch(ctx, &locketmodels.FetchRequest{Key: cellID})
+		if err != nil {
+			return err
+		}
+		if response.Resource == nil || response.Resource.Owner != owner {
+			return ErrPresenceNotOwned
+		}
+		return nil
+	}
+}
+
+func EventStreamCheck(subscriber Subscriber) Check {
+	return func(logger lager.Logger) error {
+		if !subscriber.Subscribed() {
+			return ErrNotSubscribed
+		}
+		return nil
+	}
+}
+
+// BulkSyncCheck fails when there has not been a successful bulk sync for
+// maxAge. Until the first sync, the age is counted from when the check was
+// created.
+func BulkSyncCheck(clock clock.Clock, syncer Syncer, maxAge time.Duration) Check {
+	createdAt := clock.Now()
+	return func(logger lager.Logger) error {
+		lastSync := syncer.LastSync()
+		if lastSync.IsZero() {
+			lastSync = createdAt
+		}
+		if age := clock.Since(lastSync); age > maxAge {
+			return fmt.Errorf("no successful bulk sync for %s", age)
+		}
+		return nil
+	}
+}
+
+func EvacuationCheck(reporter evacuation_context.EvacuationReporter) Check {
+	return func(logger lager.Logger) error {
+		if reporter.Evacuating() {
+			return ErrEvacuating
+		}
+		return nil
+	}
+}
+
+func CordonCheck(reporter evacuation_context.CordonReporter) Check {
+	return func(logger lager.Logger) error {
+		if reporter.Cordoned() {
+			return ErrCordoned
+		}
+		return nil
+	}
+}
@@ -0,0 +1,217 @@
+package health_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	efakes "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/health"
+	"code.cloudfoundry.org/rep/maintain/maintainfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeSubscriber bool
+
+func (s fakeSubscriber) Subscribed() bool { return bool(s) }
+
+type fakeSyncer struct{ lastSync time.Time }
+
+func (s *fakeSyncer) LastSync() time.Time { return s.lastSync }
+
+var _ = Describe("Checks", func() {
+	var logger *lagertest.TestLogger
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+	})
+
+	Describe("ExecutorCheck", func() {
+		var executorClient *efakes.FakeClient
+
+		BeforeEach(func() {
+			executorClient = new(efakes.FakeClient)
+			executorClient.HealthyReturns(true)
+		})
+
+		It("passes when the executor is reachable and healthy", func() {
+			Expect(health.ExecutorCheck(executorClient)(logger)).To(Succeed())
+		})
+
+		It("fails when the executor cannot be pinged", func() {
+			executorClient.PingReturns(errors.New("boom"))
+			Expect(health.ExecutorCheck(executorClient)(logger)).To(MatchError("boom"))
+		})
+
+		It("fails when the garden health check is failing", func() {
+			executorClient.HealthyReturns(false)
+			Expect(health.ExecutorCheck(executorClient)(logger)).To(Equal(health.ErrExecutorUnhealthy))
+		})
+	})
+
+	Describe("BBSCheck", func() {
+		var bbsClient *fake_bbs.FakeInternalClient
+
+		BeforeEach(func() {
+			bbsClient = new(fake_bbs.FakeInternalClient)
+		})
+
+		It("passes when the bbs answers", func() {
+			bbsClient.PingReturns(true)
+			Expect(health.BBSCheck(bbsClient)(logger)).To(Succeed())
+		})
+
+		It("fails when the bbs does not answer", func() {
+			bbsClient.PingReturns(false)
+			Expect(health.BBSCheck(bbsClient)(logger)).To(Equal(health.ErrBBSUnreachable))
+		})
+	})
+
+	Describe("ConsulPresenceCheck", func() {
+		var serviceClient *maintainfakes.FakeCellPresenceClient
+
+		BeforeEach(func() {
+			serviceClient = new(maintainfakes.FakeCellPresenceClient)
+		})
+
+		It("passes when the presence of the cell is held for this rep", func() {
+			serviceClient.CellByIdReturns(&models.CellPresence{CellId: "cell-id", RepAddress: "rep-address"}, nil)
+			Expect(health.ConsulPresenceCheck(serviceClient, "cell-id", "rep-address")(logger)).To(Succeed())
+
+			_, cellID := serviceClient.CellByIdArgsForCall(0)
+			Expect(cellID).To(Equal("cell-id"))
+		})
+
+		It("fails when the presence is not held", func() {
+			serviceClient.CellByIdReturns(nil, models.ErrResourceNotFound)
+			Expect(health.ConsulPresenceCheck(serviceClient, "cell-id", "rep-address")(logger)).To(Equal(models.ErrResourceNotFound))
+		})
+
+		It("fails when the presence is held for another rep", func() {
+			serviceClient.CellByIdReturns(&models.CellPresence{CellId: "cell-id", RepAddress: "other-address"}, nil)
+			Expect(health.ConsulPresenceCheck(serviceClient, "cell-id", "rep-address")(logger)).To(Equal(health.ErrPresenceNotOwned))
+		})
+	})
+
+	Describe("LocketPresenceCheck", func() {
+		var (
+			locketClient *modelsfakes.FakeLocketClient
+			check        health.Check
+		)
+
+		BeforeEach(func() {
+			locketClient = new(modelsfakes.FakeLocketClient)
+			check = health.LocketPresenceCheck(locketClient, "cell-id", "owner", time.Second)
+		})
+
+		It("passes when the presence of the cell is held by the owner", func() {
+			locketClient.FetchReturns(&locketmodels.FetchResponse{
+				Resource: &locketmodels.Resource{Key: "cell-id", Owner: "owner"},
+			}, nil)
+			Expect(check(logger)).To(Succeed())
+
+			_, request, _ := locketClient.FetchArgsForCall(0)
+			Expect(request.Key).To(Equal("cell-id"))
+		})
+
+		It("fetches with the timeout of the check", func() {
+			locketClient.FetchReturns(&locketmodels.FetchResponse{
+				Resource: &locketmodels.Resource{Key: "cell-id", Owner: "owner"},
+			}, nil)
+			Expect(check(logger)).To(Succeed())
+
+			ctx, _, _ := locketClient.FetchArgsForCall(0)
+			deadline, ok := ctx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Second), time.Second))
+			Expect(ctx.Err()).To(HaveOccurred())
+		})
+
+		It("fails when the presence is not held", func() {
+			locketClient.FetchReturns(nil, locketmodels.ErrResourceNotFound)
+			Expect(check(logger)).To(Equal(locketmodels.ErrResourceNotFound))
+		})
+
+		It("fails when the presence is held by another owner", func() {
+			locketClient.FetchReturns(&locketmodels.FetchResponse{
+				Resource: &locketmodels.Resource{Key: "cell-id", Owner: "stale-owner"},
+			}, nil)
+			Expect(check(logger)).To(Equal(health.ErrPresenceNotOwned))
+		})
+	})
+
+	Describe("EventStreamCheck", func() {
+		It("passes while subscribed", func() {
+			Expect(health.EventStreamCheck(fakeSubscriber(true))(logger)).To(Succeed())
+		})
+
+		It("fails when not subscribed", func() {
+			Expect(health.EventStreamCheck(fakeSubscriber(false))(logger)).To(Equal(health.ErrNotSubscribed))
+		})
+	})
+
+	Describe("BulkSyncCheck", func() {
+		var (
+			clock  *fakeclock.FakeClock
+			syncer *fakeSyncer
+			check  health.Check
+		)
+
+		BeforeEach(func() {
+			clock = fakeclock.NewFakeClock(time.Now())
+			syncer = &fakeSyncer{}
+			check = health.BulkSyncCheck(clock, syncer, time.Minute)
+		})
+
+		It("passes before the first sync is due", func() {
+			clock.Increment(time.Minute)
+			Expect(check(logger)).To(Succeed())
+		})
+
+		It("fails when the first sync is overdue", func() {
+			clock.Increment(time.Minute + time.Second)
+			Expect(check(logger)).To(MatchError(ContainSubstring("no successful bulk sync")))
+		})
+
+		It("passes while the last sync is recent", func() {
+			clock.Increment(2 * time.Minute)
+			syncer.lastSync = clock.Now()
+			clock.Increment(30 * time.Second)
+			Expect(check(logger)).To(Succeed())
+		})
+
+		It("fails when the last sync is too old", func() {
+			syncer.lastSync = clock.Now()
+			clock.Increment(2 * time.Minute)
+			Expect(check(logger)).To(HaveOccurred())
+		})
+	})
+
+	Describe("EvacuationCheck", func() {
+		It("fails while the cell is evacuating", func() {
+			reporter := new(fake_evacuation_context.FakeEvacuationReporter)
+			Expect(health.EvacuationCheck(reporter)(logger)).To(Succeed())
+
+			reporter.EvacuatingReturns(true)
+			Expect(health.EvacuationCheck(reporter)(logger)).To(Equal(health.ErrEvacuating))
+		})
+	})
+
+	Describe("CordonCheck", func() {
+		It("fails while the cell is cordoned", func() {
+			reporter := new(fake_evacuation_context.FakeCordonReporter)
+			Expect(health.CordonCheck(reporter)(logger)).To(Succeed())
+
+			reporter.CordonedReturns(true)
+			Expect(health.CordonCheck(reporter)(logger)).To(Equal(health.ErrCordoned))
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package health
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+var ErrCheckTimedOut = errors.New("health check timed out")
+
+// Check returns an error when the component it checks is not healthy.
+type Check func(logger lager.Logger) error
+
+// Component is a dependency of the rep. Liveness components must be healthy
+// for the rep to be alive; every component must be healthy for the rep to be
+// ready to take work.
+type Component struct {
+	Name     string
+	Check    Check
+	Liveness bool
+
+	// LastSuccess, if set, reports when the component last did its job.
+	LastSuccess func() time.Time
+}
+
+type ComponentStatus struct {
+	Name        string     `json:"name"`
+	Healthy     bool       `json:"healthy"`
+	Liveness    bool       `json:"liveness"`
+	LatencyMS   float64    `json:"latency_ms"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+type Report struct {
+	Healthy    bool              `json:"healthy"`
+	Components []ComponentStatus `json:"components"`
+}
+
+//go:generate counterfeiter . Reporter
+
+type Reporter interface {
+	Liveness(logger lager.Logger) Report
+	Readiness(logger lager.Logger) Report
+}
+
+// Checker runs the checks of its components concurrently. A check that takes
+// longer than the timeout is reported as failing, so that a hung dependency
+// cannot hang the probe.
+type Checker struct {
+	clock      clock.Clock
+	timeout    time.Duration
+	components []Component
+}
+
+func NewChecker(clock clock.Clock, timeout time.Duration, components ...Component) *Checker {
+	return &Checker{
+		clock:      clock,
+		timeout:    timeout,
+		components: components,
+	}
+}
+
+// Liveness reports every component, but is only unhealthy when a liveness
+// component is.
+func (c *Checker) Liveness(logger lager.Logger) Report {
+	return c.report(logger.Session("liveness"), true)
+}
+
+// Readiness is unhealthy when any component is.
+func (c *Checker) Readiness(logger lager.Logger) Report {
+	return c.report(logger.Session("readiness"), false)
+}
+
+func (c *Checker) report(logger lager.Logger, liveness bool) Report {
+	statuses := make([]ComponentStatus, len(c.components))
+
+	wg := sync.WaitGroup{}
+	for i, component := range c.components {
+		wg.Add(1)
+		go func(i int, component Component) {
+			defer wg.Done()
+			statuses[i] = c.check(logger, component)
+		}(i, component)
+	}
+	wg.Wait()
+
+	report := Report{Healthy: true, Components: statuses}
+	for _, status := range statuses {
+		if !status.Healthy && (status.Liveness || !liveness) {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func (c *Checker) check(logger lager.Logger, component Component) ComponentStatus {
+	logger = logger.Session("check", lager.Data{"component": component.Name})
+
+	result := make(chan error, 1)
+	startTime := c.clock.Now()
+	go func() {
+		result <- component.Check(logger)
+	}()
+
+	var err error
+	if c.timeout > 0 {
+		timer := c.clock.NewTimer(c.timeout)
+		select {
+		case err = <-result:
+		case <-timer.C():
+			err = ErrCheckTimedOut
+		}
+		timer.Stop()
+	} else {
+		err = <-result
+	}
+
+	status := ComponentStatus{
+		Name:      component.Name,
+		Healthy:   err == nil,
+		Liveness:  component.Liveness,
+		LatencyMS: float64(c.clock.Since(startTime)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		logger.Error("failed", err)
+		status.Error = err.Error()
+	}
+	if component.LastSuccess != nil {
+		if lastSuccess := component.LastSuccess(); !lastSuccess.IsZero() {
+			status.LastSuccess = &lastSuccess
+		}
+	}
+	return status
+}
@@ -0,0 +1,167 @@
+package health_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep/health"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checker", func() {
+	var (
+		logger     *lagertest.TestLogger
+		clock      *fakeclock.FakeClock
+		timeout    time.Duration
+		components []health.Component
+		checker    *health.Checker
+	)
+
+	passing := func(lager.Logger) error { return nil }
+	failing := func(lager.Logger) error { return errors.New("boom") }
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		clock = fakeclock.NewFakeClock(time.Now())
+		timeout = 0
+		components = nil
+	})
+
+	JustBeforeEach(func() {
+		checker = health.NewChecker(clock, timeout, components...)
+	})
+
+	Context("when every component is healthy", func() {
+		BeforeEach(func() {
+			components = []health.Component{
+				{Name: "executor", Check: passing, Liveness: true},
+				{Name: "bbs", Check: passing},
+			}
+		})
+
+		It("is alive and ready", func() {
+			Expect(checker.Liveness(logger).Healthy).To(BeTrue())
+			Expect(checker.Readiness(logger).Healthy).To(BeTrue())
+		})
+
+		It("reports every component in order", func() {
+			report := checker.Readiness(logger)
+			Expect(report.Components).To(HaveLen(2))
+			Expect(report.Components[0].Name).To(Equal("executor"))
+			Expect(report.Components[0].Healthy).To(BeTrue())
+			Expect(report.Components[0].Liveness).To(BeTrue())
+			Expect(report.Components[0].Error).To(BeEmpty())
+			Expect(report.Components[1].Name).To(Equal("bbs"))
+			Expect(report.Components[1].Liveness).To(BeFalse())
+		})
+	})
+
+	Context("when a readiness component is unhealthy", func() {
+		BeforeEach(func() {
+			components = []health.Component{
+				{Name: "executor", Check: passing, Liveness: true},
+				{Name: "bbs", Check: failing},
+			}
+		})
+
+		It("is alive but not ready", func() {
+			Expect(checker.Liveness(logger).Healthy).To(BeTrue())
+			Expect(checker.Readiness(logger).Healthy).To(BeFalse())
+		})
+
+		It("still reports the failure in the liveness report", func() {
+			report := checker.Liveness(logger)
+			Expect(report.Components[1].Healthy).To(BeFalse())
+			Expect(report.Components[1].Error).To(Equal("boom"))
+		})
+	})
+
+	Context("when a liveness component is unhealthy", func() {
+		BeforeEach(func() {
+			components = []health.Component{
+				{Name: "executor", Check: failing, Liveness: true},
+				{Name: "bbs", Check: passing},
+			}
+		})
+
+		It("is neither alive nor ready", func() {
+			Expect(checker.Liveness(logger).Healthy).To(BeFalse())
+			Expect(checker.Readiness(logger).Healthy).To(BeFalse())
+		})
+	})
+
+	Context("when a check takes a while", func() {
+		BeforeEach(func() {
+			components = []health.Component{
+				{Name: "slow", Check: func(lager.Logger) error {
+					clock.Increment(250 * time.Millisecond)
+					return nil
+				}},
+			}
+		})
+
+		It("reports its latency", func() {
+			report := checker.Readiness(logger)
+			Expect(report.Components[0].LatencyMS).To(BeNumerically("==", 250))
+		})
+	})
+
+	Context("when a component reports when it last did its job", func() {
+		var lastSync time.Time
+
+		BeforeEach(func() {
+			lastSync = clock.Now().Add(-time.Minute)
+			components = []health.Component{
+				{Name: "bulk-sync", Check: passing, LastSuccess: func() time.Time { return lastSync }},
+				{Name: "never", Check: passing, LastSuccess: func() time.Time { return time.Time{} }},
+			}
+		})
+
+		It("includes the time in the report", func() {
+			report := checker.Readiness(logger)
+			Expect(report.Components[0].LastSuccess).NotTo(BeNil())
+			Expect(report.Components[0].LastSuccess.Equal(lastSync)).To(BeTrue())
+			Expect(report.Components[1].LastSuccess).To(BeNil())
+		})
+	})
+
+	Context("with a timeout", func() {
+		var release chan struct{}
+
+		BeforeEach(func() {
+			timeout = time.Second
+			release = make(chan struct{})
+			components = []health.Component{
+				{Name: "hung", Check: func(lager.Logger) error {
+					<-release
+					return nil
+				}},
+			}
+		})
+
+		AfterEach(func() {
+			close(release)
+		})
+
+		It("fails checks that take too long", func() {
+			reports := make(chan health.Report)
+			go func() {
+				reports <- checker.Readiness(logger)
+			}()
+
+			Eventually(clock.WatcherCount).Should(Equal(1))
+			Consistently(reports).ShouldNot(Receive())
+			clock.Increment(timeout)
+
+			var report health.Report
+			Eventually(reports).Should(Receive(&report))
+			Expect(report.Healthy).To(BeFalse())
+			Expect(report.Components[0].Error).To(Equal(health.ErrCheckTimedOut.Error()))
+		})
+	})
+})
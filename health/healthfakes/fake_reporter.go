@@ -0,0 +1,156 @@
+// This file was generated by counterfeiter
+package healthfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep/health"
+)
+
+type FakeReporter struct {
+	LivenessStub        func(logger lager.Logger) health.Report
+	livenessMutex       sync.RWMutex
+	livenessArgsForCall []struct {
+		logger lager.Logger
+	}
+	livenessReturns struct {
+		result1 health.Report
+	}
+	livenessReturnsOnCall map[int]struct {
+		result1 health.Report
+	}
+	ReadinessStub        func(logger lager.Logger) health.Report
+	readinessMutex       sync.RWMutex
+	readinessArgsForCall []struct {
+		logger lager.Logger
+	}
+	readinessReturns struct {
+		result1 health.Report
+	}
+	readinessReturnsOnCall map[int]struct {
+		result1 health.Report
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeReporter) Liveness(logger lager.Logger) health.Report {
+	fake.livenessMutex.Lock()
+	ret, specificReturn := fake.livenessReturnsOnCall[len(fake.livenessArgsForCall)]
+	fake.livenessArgsForCall = append(fake.livenessArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("Liveness", []interface{}{logger})
+	fake.livenessMutex.Unlock()
+	if fake.LivenessStub != nil {
+		return fake.LivenessStub(logger)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.livenessReturns.result1
+}
+
+func (fake *FakeReporter) LivenessCallCount() int {
+	fake.livenessMutex.RLock()
+	defer fake.livenessMutex.RUnlock()
+	return len(fake.livenessArgsForCall)
+}
+
+func (fake *FakeReporter) LivenessArgsForCall(i int) lager.Logger {
+	fake.livenessMutex.RLock()
+	defer fake.livenessMutex.RUnlock()
+	return fake.livenessArgsForCall[i].logger
+}
+
+func (fake *FakeReporter) LivenessReturns(result1 health.Report) {
+	fake.LivenessStub = nil
+	fake.livenessReturns = struct {
+		result1 health.Report
+	}{result1}
+}
+
+func (fake *FakeReporter) LivenessReturnsOnCall(i int, result1 health.Report) {
+	fake.LivenessStub = nil
+	if fake.livenessReturnsOnCall == nil {
+		fake.livenessReturnsOnCall = make(map[int]struct {
+			result1 health.Report
+		})
+	}
+	fake.livenessReturnsOnCall[i] = struct {
+		result1 health.Report
+	}{result1}
+}
+
+func (fake *FakeReporter) Readiness(logger lager.Logger) health.Report {
+	fake.readinessMutex.Lock()
+	ret, specificReturn := fake.readinessReturnsOnCall[len(fake.readinessArgsForCall)]
+	fake.readinessArgsForCall = append(fake.readinessArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("Readiness", []interface{}{logger})
+	fake.readinessMutex.Unlock()
+	if fake.ReadinessStub != nil {
+		return fake.ReadinessStub(logger)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.readinessReturns.result1
+}
+
+func (fake *FakeReporter) ReadinessCallCount() int {
+	fake.readinessMutex.RLock()
+	defer fake.readinessMutex.RUnlock()
+	return len(fake.readinessArgsForCall)
+}
+
+func (fake *FakeReporter) ReadinessArgsForCall(i int) lager.Logger {
+	fake.readinessMutex.RLock()
+	defer fake.readinessMutex.RUnlock()
+	return fake.readinessArgsForCall[i].logger
+}
+
+func (fake *FakeReporter) ReadinessReturns(result1 health.Report) {
+	fake.ReadinessStub = nil
+	fake.readinessReturns = struct {
+		result1 health.Report
+	}{result1}
+}
+
+func (fake *FakeReporter) ReadinessReturnsOnCall(i int, result1 health.Report) {
+	fake.ReadinessStub = nil
+	if fake.readinessReturnsOnCall == nil {
+		fake.readinessReturnsOnCall = make(map[int]struct {
+			result1 health.Report
+		})
+	}
+	fake.readinessReturnsOnCall[i] = struct {
+		result1 health.Report
+	}{result1}
+}
+
+func (fake *FakeReporter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.livenessMutex.RLock()
+	defer fake.livenessMutex.RUnlock()
+	fake.readinessMutex.RLock()
+	defer fake.readinessMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeReporter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ health.Reporter = new(FakeReporter)
@@ -0,0 +1 @@
+package healthfakes // import "code.cloudfoundry.org/rep/health/healthfakes"
@@ -0,0 +1 @@
+package health // import "code.cloudfoundry.org/rep/health"
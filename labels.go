@@ -0,0 +1,219 @@
+package rep
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type LabelSelectorOperator string
+
+const (
+	LabelSelectorIn        LabelSelectorOperator = "in"
+	LabelSelectorNotIn     LabelSelectorOperator = "notin"
+	LabelSelectorExists    LabelSelectorOperator = "exists"
+	LabelSelectorNotExists LabelSelectorOperator = "!exists"
+)
+
+var (
+	ErrInvalidLabelSelector = errors.New("invalid label selector")
+)
+
+// LabelSelectorRequirement is a single expression in a LabelSelector, e.g.
+// "disk in (ssd, nvme)" or "!gpu".
+type LabelSelectorRequirement struct {
+	Key      string                `json:"key"`
+	Operator LabelSelectorOperator `json:"operator"`
+	Values   []string              `json:"values,omitempty"`
+}
+
+func (r LabelSelectorRequirement) Validate() error {
+	if r.Key == "" {
+		return fmt.Errorf("%s: blank key", ErrInvalidLabelSelector)
+	}
+
+	switch r.Operator {
+	case LabelSelectorIn, LabelSelectorNotIn:
+		if len(r.Values) == 0 {
+			return fmt.Errorf("%s: %s requires at least one value", ErrInvalidLabelSelector, r.Operator)
+		}
+	case LabelSelectorExists, LabelSelectorNotExists:
+		if len(r.Values) != 0 {
+			return fmt.Errorf("%s: %s takes no values", ErrInvalidLabelSelector, r.Operator)
+		}
+	default:
+		return fmt.Errorf("%s: unknown operator %q", ErrInvalidLabelSelector, r.Operator)
+	}
+
+	return nil
+}
+
+func (r LabelSelectorRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+
+	switch r.Operator {
+	case LabelSelectorIn:
+		return ok && contains(r.Values, value)
+	case LabelSelectorNotIn:
+		return !ok || !contains(r.Values, value)
+	case LabelSelectorExists:
+		return ok
+	case LabelSelectorNotExists:
+		return !ok
+	}
+
+	return false
+}
+
+func (r LabelSelectorRequirement) String() string {
+	switch r.Operator {
+	case LabelSelectorExists:
+		return r.Key
+	case LabelSelectorNotExists:
+		return "!" + r.Key
+	}
+	return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ", "))
+}
+
+// A LabelSelector matches a set of cell labels when all of its requirements
+// match.
+type LabelSelector []LabelSelectorRequirement
+
+func (s LabelSelector) Validate() error {
+	for _, r := range s {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	return len(s.Unmatched(labels)) == 0
+}
+
+// Unmatched returns the requirements that do not match the labels.
+func (s LabelSelector) Unmatched(labels map[string]string) LabelSelector {
+	var unmatched LabelSelector
+	for _, r := range s {
+		if !r.Matches(labels) {
+			unmatched = append(unmatched, r)
+		}
+	}
+	return unmatched
+}
+
+func (s LabelSelector) String() string {
+	exprs := make([]string, 0, len(s))
+	for _, r := range s {
+		exprs = append(exprs, r.String())
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// ParseLabelSelector parses a comma separated list of expressions:
+//
+//	key in (v1, v2)    key notin (v1, v2)
+//	key=value          key!=value
+//	key                !key
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	result := LabelSelector{}
+
+	for _, expr := range splitSelector(selector) {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+
+		requirement, err := parseRequirement(expr)
+		if err != nil {
+			return nil, err
+		}
+		if err := requirement.Validate(); err != nil {
+			return nil, err
+		}
+		result = append(result, requirement)
+	}
+
+	return result, nil
+}
+
+func parseRequirement(expr string) (LabelSelectorRequirement, error) {
+	if strings.HasPrefix(expr, "!") && !strings.ContainsAny(expr, "=( ") {
+		return LabelSelectorRequirement{Key: strings.TrimSpace(expr[1:]), Operator: LabelSelectorNotExists}, nil
+	}
+
+	if i := strings.Index(expr, "!="); i >= 0 {
+		return LabelSelectorRequirement{
+			Key:      strings.TrimSpace(expr[:i]),
+			Operator: LabelSelectorNotIn,
+			Values:   []string{strings.TrimSpace(expr[i+2:])},
+		}, nil
+	}
+
+	if i := strings.Index(expr, "="); i >= 0 {
+		return LabelSelectorRequirement{
+			Key:      strings.TrimSpace(expr[:i]),
+			Operator: LabelSelectorIn,
+			Values:   []string{strings.TrimSpace(strings.TrimPrefix(expr[i+1:], "="))},
+		}, nil
+	}
+
+	open := strings.Index(expr, "(")
+	if open < 0 {
+		fields := strings.Fields(expr)
+		if len(fields) != 1 {
+			return LabelSelectorRequirement{}, fmt.Errorf("%s: %q", ErrInvalidLabelSelector, expr)
+		}
+		return LabelSelectorRequirement{Key: fields[0], Operator: LabelSelectorExists}, nil
+	}
+
+	head := strings.Fields(expr[:open])
+	if len(head) != 2 || !strings.HasSuffix(expr, ")") {
+		return LabelSelectorRequirement{}, fmt.Errorf("%s: %q", ErrInvalidLabelSelector, expr)
+	}
+
+	operator := LabelSelectorOperator(head[1])
+	if operator != LabelSelectorIn && operator != LabelSelectorNotIn {
+		return LabelSelectorRequirement{}, fmt.Errorf("%s: %q", ErrInvalidLabelSelector, expr)
+	}
+
+	values := []string{}
+	for _, value := range strings.Split(expr[open+1:len(expr)-1], ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return LabelSelectorRequirement{Key: head[0], Operator: operator, Values: values}, nil
+}
+
+// splitSelector splits on commas that are not inside a value list.
+func splitSelector(selector string) []string {
+	exprs := []string{}
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				exprs = append(exprs, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(exprs, selector[start:])
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package rep_test
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LabelSelector", func() {
+	Describe("ParseLabelSelector", func() {
+		It("parses each form of expression", func() {
+			selector, err := rep.ParseLabelSelector("disk in (ssd, nvme), rack notin (r1), zone=z1, os!=windows, gpu, !spot")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector).To(Equal(rep.LabelSelector{
+				{Key: "disk", Operator: rep.LabelSelectorIn, Values: []string{"ssd", "nvme"}},
+				{Key: "rack", Operator: rep.LabelSelectorNotIn, Values: []string{"r1"}},
+				{Key: "zone", Operator: rep.LabelSelectorIn, Values: []string{"z1"}},
+				{Key: "os", Operator: rep.LabelSelectorNotIn, Values: []string{"windows"}},
+				{Key: "gpu", Operator: rep.LabelSelectorExists},
+				{Key: "spot", Operator: rep.LabelSelectorNotExists},
+			}))
+		})
+
+		It("accepts == as equality", func() {
+			selector, err := rep.ParseLabelSelector("zone==z1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector).To(Equal(rep.LabelSelector{{Key: "zone", Operator: rep.LabelSelectorIn, Values: []string{"z1"}}}))
+		})
+
+		It("returns an empty selector for an empty string", func() {
+			selector, err := rep.ParseLabelSelector("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector).To(BeEmpty())
+		})
+
+		It("rejects invalid expressions", func() {
+			for _, selector := range []string{
+				"disk within (ssd)",
+				"disk in ()",
+				"disk in (ssd",
+				"=ssd",
+				"disk ssd",
+			} {
+				_, err := rep.ParseLabelSelector(selector)
+				Expect(err).To(HaveOccurred(), selector)
+				Expect(err.Error()).To(ContainSubstring(rep.ErrInvalidLabelSelector.Error()))
+			}
+		})
+	})
+
+	Describe("Matches", func() {
+		var labels map[string]string
+
+		BeforeEach(func() {
+			labels = map[string]string{"disk": "ssd", "rack": "r1"}
+		})
+
+		It("matches in", func() {
+			Expect(rep.LabelSelectorRequirement{Key: "disk", Operator: rep.LabelSelectorIn, Values: []string{"ssd", "nvme"}}.Matches(labels)).To(BeTrue())
+			Expect(rep.LabelSelectorRequirement{Key: "disk", Operator: rep.LabelSelectorIn, Values: []string{"hdd"}}.Matches(labels)).To(BeFalse())
+			Expect(rep.LabelSelectorRequirement{Key: "gpu", Operator: rep.LabelSelectorIn, Values: []string{"true"}}.Matches(labels)).To(BeFalse())
+		})
+
+		It("matches notin", func() {
+			Expect(rep.LabelSelectorRequirement{Key: "rack", Operator: rep.LabelSelectorNotIn, Values: []string{"r1"}}.Matches(labels)).To(BeFalse())
+			Expect(rep.LabelSelectorRequirement{Key: "rack", Operator: rep.LabelSelectorNotIn, Values: []string{"r2"}}.Matches(labels)).To(BeTrue())
+			Expect(rep.LabelSelectorRequirement{Key: "gpu", Operator: rep.LabelSelectorNotIn, Values: []string{"true"}}.Matches(labels)).To(BeTrue())
+		})
+
+		It("matches exists", func() {
+			Expect(rep.LabelSelectorRequirement{Key: "disk", Operator: rep.LabelSelectorExists}.Matches(labels)).To(BeTrue())
+			Expect(rep.LabelSelectorRequirement{Key: "gpu", Operator: rep.LabelSelectorExists}.Matches(labels)).To(BeFalse())
+		})
+
+		It("matches !exists", func() {
+			Expect(rep.LabelSelectorRequirement{Key: "disk", Operator: rep.LabelSelectorNotExists}.Matches(labels)).To(BeFalse())
+			Expect(rep.LabelSelectorRequirement{Key: "gpu", Operator: rep.LabelSelectorNotExists}.Matches(labels)).To(BeTrue())
+		})
+
+		It("never matches an unknown operator", func() {
+			Expect(rep.LabelSelectorRequirement{Key: "disk", Operator: "within"}.Matches(labels)).To(BeFalse())
+		})
+
+		It("returns the unmatched requirements", func() {
+			selector, err := rep.ParseLabelSelector("disk=ssd, rack=r2, gpu")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector.Matches(labels)).To(BeFalse())
+			Expect(selector.Unmatched(labels).String()).To(Equal("rack in (r2), gpu"))
+		})
+
+		It("matches a cell without labels only with negative requirements", func() {
+			selector, err := rep.ParseLabelSelector("!gpu, rack notin (r1)")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector.Matches(nil)).To(BeTrue())
+		})
+	})
+
+	It("round trips through json", func() {
+		selector, err := rep.ParseLabelSelector("disk in (ssd, nvme), !spot")
+		Expect(err).NotTo(HaveOccurred())
+
+		payload, err := json.Marshal(selector)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payload).To(MatchJSON(`[
+			{"key": "disk", "operator": "in", "values": ["ssd", "nvme"]},
+			{"key": "spot", "operator": "!exists"}
+		]`))
+
+		var decoded rep.LabelSelector
+		Expect(json.Unmarshal(payload, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(selector))
+	})
+})
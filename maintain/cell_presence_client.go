@@ -1,6 +1,7 @@
 package maintain
 
 import (
+	"errors"
 	"os"
 	"path"
 	"time"
@@ -10,15 +11,34 @@ import (
 	"code.cloudfoundry.org/consuladapter"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket"
+	"github.com/hashicorp/consul/api"
 	"github.com/tedsuo/ifrit"
 )
 
 const CellSchemaKey = "cell"
 
+var ErrPresenceNotHeld = errors.New("cell presence is not held by this rep")
+
+// CellPresence is the presence advertised by the rep. It embeds the bbs cell
+// presence so that consumers which only know about models.CellPresence can
+// still decode it.
+type CellPresence struct {
+	models.CellPresence
+	Labels   map[string]string `json:"labels,omitempty"`
+	Topology []string          `json:"topology,omitempty"`
+}
+
+func NewCellPresence(cellPresence models.CellPresence, labels map[string]string) CellPresence {
+	return CellPresence{CellPresence: cellPresence, Labels: labels}
+}
+
 //go:generate counterfeiter . CellPresenceClient
 
 type CellPresenceClient interface {
-	NewCellPresenceRunner(logger lager.Logger, cellPresence *models.CellPresence, retryInterval, lockTTL time.Duration) ifrit.Runner
+	NewCellPresenceRunner(logger lager.Logger, cellPresence *CellPresence, retryInterval, lockTTL time.Duration) ifrit.Runner
+	// UpdateCellPresence replaces the presence held by a running presence
+	// runner of the same rep, without giving up the lock.
+	UpdateCellPresence(logger lager.Logger, cellPresence *CellPresence) error
 
 	CellById(logger lager.Logger, cellId string) (*models.CellPresence, error)
 	Cells(logger lager.Logger) (models.CellSet, error)
@@ -45,7 +65,7 @@ func NewCellPresenceClient(client consuladapter.Client, clock clock.Clock) *cell
 	}
 }
 
-func (db *cellPresenceClient) NewCellPresenceRunner(logger lager.Logger, cellPresence *models.CellPresence, retryInterval time.Duration, lockTTL time.Duration) ifrit.Runner {
+func (db *cellPresenceClient) NewCellPresenceRunner(logger lager.Logger, cellPresence *CellPresence, retryInterval time.Duration, lockTTL time.Duration) ifrit.Runner {
 	payload, err := models.ToJSON(cellPresence)
 	if err != nil {
 		panic(err)
@@ -54,6 +74,42 @@ func (db *cellPresenceClient) NewCellPresenceRunner(logger lager.Logger, cellPre
 	return locket.NewPresence(logger, db.consulClient, CellSchemaPath(cellPresence.CellId), payload, db.clock, retryInterval, lockTTL)
 }
 
+func (c *cellPresenceClient) UpdateCellPresence(logger lager.Logger, cellPresence *CellPresence) error {
+	key := CellSchemaPath(cellPresence.CellId)
+	kvPair, _, err := c.consulClient.KV().Get(key, nil)
+	if err != nil {
+		return convertConsulError(err)
+	}
+	if kvPair == nil || kvPair.Session == "" {
+		return ErrPresenceNotHeld
+	}
+
+	current := new(models.CellPresence)
+	err = models.FromJSON(kvPair.Value, current)
+	if err != nil {
+		return models.NewError(models.Error_InvalidJSON, err.Error())
+	}
+	if current.RepAddress != cellPresence.RepAddress {
+		return ErrPresenceNotHeld
+	}
+
+	payload, err := models.ToJSON(cellPresence)
+	if err != nil {
+		return err
+	}
+
+	// a write without acquire keeps the key locked by the session that holds
+	// it, and the check-and-set fails if the lock changed hands since the get
+	swapped, _, err := c.consulClient.KV().CAS(&api.KVPair{Key: key, Value: payload, ModifyIndex: kvPair.ModifyIndex}, nil)
+	if err != nil {
+		return convertConsulError(err)
+	}
+	if !swapped {
+		return ErrPresenceNotHeld
+	}
+	return nil
+}
+
 func (c *cellPresenceClient) Cells(logger lager.Logger) (models.CellSet, error) {
 	kvPairs, _, err := c.consulClient.KV().List(CellSchemaRoot(), nil)
 	if err != nil {
@@ -1,13 +1,16 @@
 package maintain_test
 
 import (
+	"encoding/json"
 	"os"
 
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/clock"
+	consulfakes "code.cloudfoundry.org/consuladapter/fakes"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/locket"
 	"code.cloudfoundry.org/rep/maintain"
+	"github.com/hashicorp/consul/api"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/ginkgomon"
 	"github.com/tedsuo/ifrit/grouper"
@@ -31,7 +34,7 @@ var _ = Describe("CellPresenceClient", func() {
 		var (
 			cellID       string
 			process      ifrit.Process
-			cellPresence *models.CellPresence
+			cellPresence *maintain.CellPresence
 		)
 
 		BeforeEach(func() {
@@ -51,7 +54,16 @@ var _ = Describe("CellPresenceClient", func() {
 			It("returns the correct CellPresence", func() {
 				presence, err := cellPresenceClient.CellById(logger, cellID)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(presence).To(BeEquivalentTo(cellPresence))
+				Expect(presence).To(BeEquivalentTo(&cellPresence.CellPresence))
+			})
+
+			It("advertises the cell labels", func() {
+				kvPair, _, err := consulClient.KV().Get(maintain.CellSchemaPath(cellID), nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				var presence maintain.CellPresence
+				Expect(json.Unmarshal(kvPair.Value, &presence)).To(Succeed())
+				Expect(presence.Labels).To(Equal(map[string]string{"disk": "ssd"}))
 			})
 		})
 
@@ -65,6 +77,78 @@ var _ = Describe("CellPresenceClient", func() {
 		})
 	})
 
+	Describe("UpdateCellPresence", func() {
+		const cellID = "cell-id"
+
+		Context("when the presence is held", func() {
+			var process ifrit.Process
+
+			BeforeEach(func() {
+				process = ifrit.Invoke(cellPresenceClient.NewCellPresenceRunner(logger, newCellPresence(cellID), locket.RetryInterval, locket.DefaultSessionTTL))
+				Eventually(func() error {
+					_, err := cellPresenceClient.CellById(logger, cellID)
+					return err
+				}).Should(Succeed())
+			})
+
+			AfterEach(func() {
+				ginkgomon.Interrupt(process)
+			})
+
+			It("replaces the presence without releasing the lock", func() {
+				updated := newCellPresence(cellID)
+				updated.PlacementTags = []string{"new-tag"}
+				Expect(cellPresenceClient.UpdateCellPresence(logger, updated)).To(Succeed())
+
+				kvPair, _, err := consulClient.KV().Get(maintain.CellSchemaPath(cellID), nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(kvPair.Session).NotTo(BeEmpty())
+
+				presence, err := cellPresenceClient.CellById(logger, cellID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(presence.PlacementTags).To(Equal([]string{"new-tag"}))
+			})
+
+			It("refuses to replace the presence of another rep", func() {
+				other := newCellPresence(cellID)
+				other.RepAddress = "other.example.com"
+				Expect(cellPresenceClient.UpdateCellPresence(logger, other)).To(Equal(maintain.ErrPresenceNotHeld))
+			})
+		})
+
+		Context("when the presence is not held", func() {
+			It("returns ErrPresenceNotHeld", func() {
+				Expect(cellPresenceClient.UpdateCellPresence(logger, newCellPresence(cellID))).To(Equal(maintain.ErrPresenceNotHeld))
+			})
+		})
+
+		Context("when the presence changes hands after it is read", func() {
+			var fakeKV *consulfakes.FakeKV
+
+			BeforeEach(func() {
+				payload, err := json.Marshal(newCellPresence(cellID))
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeKV = &consulfakes.FakeKV{}
+				fakeKV.GetReturns(&api.KVPair{Key: maintain.CellSchemaPath(cellID), Value: payload, Session: "the-session", ModifyIndex: 42}, nil, nil)
+				fakeKV.CASReturns(false, nil, nil)
+
+				fakeConsulClient := &consulfakes.FakeClient{}
+				fakeConsulClient.KVReturns(fakeKV)
+				cellPresenceClient = maintain.NewCellPresenceClient(fakeConsulClient, clock.NewClock())
+			})
+
+			It("does not overwrite the presence of the new holder", func() {
+				Expect(cellPresenceClient.UpdateCellPresence(logger, newCellPresence(cellID))).To(Equal(maintain.ErrPresenceNotHeld))
+
+				Expect(fakeKV.PutCallCount()).To(BeZero())
+				Expect(fakeKV.CASCallCount()).To(Equal(1))
+				kvPair, _ := fakeKV.CASArgsForCall(0)
+				Expect(kvPair.ModifyIndex).To(BeEquivalentTo(42))
+			})
+		})
+	})
+
 	Describe("Cells", func() {
 		const cell1 = "cell-id-1"
 		const cell2 = "cell-id-2"
@@ -93,8 +177,8 @@ var _ = Describe("CellPresenceClient", func() {
 	})
 })
 
-func newCellPresence(cellID string) *models.CellPresence {
-	presence := models.NewCellPresence(
+func newCellPresence(cellID string) *maintain.CellPresence {
+	presence := maintain.NewCellPresence(models.NewCellPresence(
 		cellID,
 		"cell.example.com",
 		"http://cell.example.com",
@@ -104,6 +188,6 @@ func newCellPresence(cellID string) *models.CellPresence {
 		nil,
 		nil,
 		nil,
-	)
+	), map[string]string{"disk": "ssd"})
 	return &presence
 }
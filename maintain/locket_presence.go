@@ -0,0 +1,160 @@
+package maintain
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/rep"
+	"google.golang.org/grpc"
+)
+
+// LocketPresence holds the presence of the cell in locket under one owner
+// for the life of the rep. Locket lets the owner of a lock change its value,
+// so when the placement settings change the presence is locked again with
+// the new value and the cell never disappears from the bbs.
+type LocketPresence struct {
+	logger         lager.Logger
+	executorClient executor.Client
+	locketClient   locketmodels.LocketClient
+	owner          string
+	lockTTL        time.Duration
+	clock          clock.Clock
+	update         chan struct{}
+
+	lock   sync.Mutex
+	config Config
+}
+
+func NewLocketPresence(
+	logger lager.Logger,
+	config Config,
+	executorClient executor.Client,
+	locketClient locketmodels.LocketClient,
+	owner string,
+	lockTTL time.Duration,
+	clock clock.Clock,
+) *LocketPresence {
+	return &LocketPresence{
+		logger:         logger.Session("locket-presence"),
+		executorClient: executorClient,
+		locketClient:   locketClient,
+		owner:          owner,
+		lockTTL:        lockTTL,
+		clock:          clock,
+		update:         make(chan struct{}, 1),
+		config:         config,
+	}
+}
+
+func (p *LocketPresence) UpdatePlacement(preloadedStackPathMap rep.StackPathMap, placementTags, optionalPlacementTags []string) {
+	p.lock.Lock()
+	p.config.updatePlacement(preloadedStackPathMap, placementTags, optionalPlacementTags)
+	p.lock.Unlock()
+
+	select {
+	case p.update <- struct{}{}:
+	default:
+	}
+}
+
+func (p *LocketPresence) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := p.logger.Session("run", lager.Data{"key": p.config.CellID, "owner": p.owner})
+	logger.Info("started")
+	defer logger.Info("completed")
+
+	resources, err := p.executorClient.TotalResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-total-resources", err)
+		return err
+	}
+
+	retry := p.clock.NewTimer(p.config.RetryInterval)
+	defer retry.Stop()
+
+	acquired := false
+	for {
+		resource, err := p.resource(resources)
+		if err != nil {
+			logger.Error("failed-to-encode-cell-presence", err)
+			return err
+		}
+
+		err = p.lockPresence(resource)
+		if err != nil {
+			if acquired {
+				logger.Error("lost-lock", err)
+				acquired = false
+			} else if grpc.Code(err) != grpc.Code(locketmodels.ErrLockCollision) {
+				logger.Error("failed-to-acquire-lock", err)
+			}
+		} else if !acquired {
+			logger.Info("acquired-lock")
+			acquired = true
+			if ready != nil {
+				close(ready)
+				ready = nil
+			}
+		}
+
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			p.releasePresence(logger, resource)
+			return nil
+
+		case <-p.update:
+			logger.Info("republishing-presence")
+			retry.Stop()
+
+		case <-retry.C():
+		}
+		retry.Reset(p.config.RetryInterval)
+	}
+}
+
+func (p *LocketPresence) resource(resources executor.ExecutorResources) (*locketmodels.Resource, error) {
+	p.lock.Lock()
+	cellPresence := p.config.cellPresence(resources)
+	p.lock.Unlock()
+
+	payload, err := json.Marshal(cellPresence)
+	if err != nil {
+		return nil, err
+	}
+
+	return &locketmodels.Resource{
+		Key:      cellPresence.CellId,
+		Owner:    p.owner,
+		Value:    string(payload),
+		TypeCode: locketmodels.PRESENCE,
+		Type:     locketmodels.PresenceType,
+	}, nil
+}
+
+func (p *LocketPresence) lockPresence(resource *locketmodels.Resource) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.lockTTL)
+	defer cancel()
+
+	ttlInSeconds := int64(p.lockTTL / time.Second)
+	_, err := p.locketClient.Lock(ctx, &locketmodels.LockRequest{Resource: resource, TtlInSeconds: ttlInSeconds}, grpc.FailFast(false))
+	return err
+}
+
+func (p *LocketPresence) releasePresence(logger lager.Logger, resource *locketmodels.Resource) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.lockTTL)
+	defer cancel()
+
+	_, err := p.locketClient.Release(ctx, &locketmodels.ReleaseRequest{Resource: resource})
+	if err != nil {
+		logger.Error("failed-to-release-lock", err)
+		return
+	}
+	logger.Info("released-lock")
+}
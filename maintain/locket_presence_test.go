@@ -0,0 +1,141 @@
+package maintain_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
+	fake_client "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/maintain"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LocketPresence", func() {
+	var (
+		logger         *lagertest.TestLogger
+		clock          *fakeclock.FakeClock
+		executorClient *fake_client.FakeClient
+		locketClient   *modelsfakes.FakeLocketClient
+		presence       *maintain.LocketPresence
+		process        ifrit.Process
+	)
+
+	lockRequest := func(i int) *locketmodels.LockRequest {
+		_, request, _ := locketClient.LockArgsForCall(i)
+		return request
+	}
+
+	advertised := func(i int) maintain.CellPresence {
+		var cellPresence maintain.CellPresence
+		Expect(json.Unmarshal([]byte(lockRequest(i).Resource.Value), &cellPresence)).To(Succeed())
+		return cellPresence
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		clock = fakeclock.NewFakeClock(time.Now())
+		executorClient = new(fake_client.FakeClient)
+		executorClient.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 128, DiskMB: 1024, Containers: 6}, nil)
+		locketClient = new(modelsfakes.FakeLocketClient)
+
+		config := maintain.Config{
+			CellID:        "cell-id",
+			RepAddress:    "1.2.3.4",
+			RetryInterval: time.Second,
+			PlacementTags: []string{"tag-1"},
+		}
+		presence = maintain.NewLocketPresence(logger, config, executorClient, locketClient, "the-owner", 15*time.Second, clock)
+	})
+
+	JustBeforeEach(func() {
+		process = ifrit.Background(presence)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("locks the presence and becomes ready", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+
+		request := lockRequest(0)
+		Expect(request.Resource.Key).To(Equal("cell-id"))
+		Expect(request.Resource.Owner).To(Equal("the-owner"))
+		Expect(request.Resource.Type).To(Equal(locketmodels.PresenceType))
+		Expect(request.TtlInSeconds).To(BeEquivalentTo(15))
+		Expect(advertised(0).PlacementTags).To(Equal([]string{"tag-1"}))
+	})
+
+	It("keeps refreshing the lock with the same owner", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+
+		clock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(locketClient.LockCallCount).Should(Equal(2))
+		Expect(lockRequest(1).Resource.Owner).To(Equal("the-owner"))
+	})
+
+	It("releases the lock when signalled", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+		ginkgomon.Interrupt(process)
+
+		Expect(locketClient.ReleaseCallCount()).To(Equal(1))
+		_, request, _ := locketClient.ReleaseArgsForCall(0)
+		Expect(request.Resource.Owner).To(Equal("the-owner"))
+	})
+
+	Context("when the placement changes", func() {
+		JustBeforeEach(func() {
+			Eventually(process.Ready()).Should(BeClosed())
+			presence.UpdatePlacement(rep.StackPathMap{"cflinuxfs3": "/path"}, []string{"tag-2"}, []string{"optional"})
+		})
+
+		It("updates the presence in place without releasing the lock", func() {
+			Eventually(locketClient.LockCallCount).Should(Equal(2))
+			Expect(lockRequest(1).Resource.Owner).To(Equal("the-owner"))
+			Expect(advertised(1).PlacementTags).To(Equal([]string{"tag-2"}))
+			Expect(advertised(1).OptionalPlacementTags).To(Equal([]string{"optional"}))
+			Expect(locketClient.ReleaseCallCount()).To(Equal(0))
+			Consistently(process.Wait()).ShouldNot(Receive())
+		})
+
+		It("keeps advertising the new placement", func() {
+			Eventually(locketClient.LockCallCount).Should(Equal(2))
+			clock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(locketClient.LockCallCount).Should(Equal(3))
+			Expect(advertised(2).PlacementTags).To(Equal([]string{"tag-2"}))
+		})
+	})
+
+	Context("when the lock is held by someone else", func() {
+		BeforeEach(func() {
+			locketClient.LockReturnsOnCall(0, nil, locketmodels.ErrLockCollision)
+		})
+
+		It("retries until it acquires the lock", func() {
+			Consistently(process.Ready()).ShouldNot(BeClosed())
+
+			clock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(process.Ready()).Should(BeClosed())
+		})
+	})
+
+	Context("when the executor resources cannot be fetched", func() {
+		BeforeEach(func() {
+			executorClient.TotalResourcesReturns(executor.ExecutorResources{}, errors.New("boom"))
+		})
+
+		It("exits with the error", func() {
+			Eventually(process.Wait()).Should(Receive(MatchError("boom")))
+		})
+	})
+})
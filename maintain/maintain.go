@@ -3,12 +3,14 @@ package maintain
 import (
 	"errors"
 	"os"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
 	"github.com/tedsuo/ifrit"
 )
 
@@ -19,6 +21,10 @@ type Maintainer struct {
 	logger         lager.Logger
 	lockTTL        time.Duration
 	clock          clock.Clock
+	republish      chan struct{}
+
+	lock      sync.Mutex
+	resources executor.ExecutorResources
 }
 
 type Config struct {
@@ -31,6 +37,33 @@ type Config struct {
 	PreloadedRootFSes     []string
 	PlacementTags         []string
 	OptionalPlacementTags []string
+	Labels                map[string]string
+	Topology              []string
+}
+
+// cellPresence builds the presence advertised for the given total resources
+// of the executor.
+func (c Config) cellPresence(resources executor.ExecutorResources) CellPresence {
+	cellCapacity := models.NewCellCapacity(int32(resources.MemoryMB), int32(resources.DiskMB), int32(resources.Containers))
+	cellPresence := NewCellPresence(
+		models.NewCellPresence(c.CellID, c.RepAddress, c.RepUrl, c.Zone, cellCapacity, c.RootFSProviders, c.PreloadedRootFSes, c.PlacementTags, c.OptionalPlacementTags),
+		c.Labels,
+	)
+	cellPresence.Topology = c.Topology
+	return cellPresence
+}
+
+func (c *Config) updatePlacement(preloadedStackPathMap rep.StackPathMap, placementTags, optionalPlacementTags []string) {
+	c.PreloadedRootFSes = preloadedStackPathMap.Stacks()
+	c.PlacementTags = placementTags
+	c.OptionalPlacementTags = optionalPlacementTags
+}
+
+// PresenceRunner advertises the presence of the cell, and updates it in
+// place when the placement settings of the cell change.
+type PresenceRunner interface {
+	ifrit.Runner
+	UpdatePlacement(preloadedStackPathMap rep.StackPathMap, placementTags, optionalPlacementTags []string)
 }
 
 func New(
@@ -48,6 +81,21 @@ func New(
 		logger:         logger.Session("maintainer"),
 		lockTTL:        lockTTL,
 		clock:          clock,
+		republish:      make(chan struct{}, 1),
+	}
+}
+
+// UpdatePlacement rewrites the presence that is being heartbeated, without
+// giving up its lock, and makes later heartbeaters advertise the new
+// placement settings.
+func (m *Maintainer) UpdatePlacement(preloadedStackPathMap rep.StackPathMap, placementTags, optionalPlacementTags []string) {
+	m.lock.Lock()
+	m.Config.updatePlacement(preloadedStackPathMap, placementTags, optionalPlacementTags)
+	m.lock.Unlock()
+
+	select {
+	case m.republish <- struct{}{}:
+	default:
 	}
 }
 
@@ -106,11 +154,34 @@ func (m *Maintainer) createHeartbeater() (ifrit.Runner, error) {
 	if err != nil {
 		return nil, err
 	}
-	cellCapacity := models.NewCellCapacity(int32(resources.MemoryMB), int32(resources.DiskMB), int32(resources.Containers))
-	cellPresence := models.NewCellPresence(m.CellID, m.RepAddress, m.RepUrl, m.Zone, cellCapacity, m.RootFSProviders, m.PreloadedRootFSes, m.PlacementTags, m.OptionalPlacementTags)
+
+	// the new heartbeater already advertises any pending placement change
+	select {
+	case <-m.republish:
+	default:
+	}
+
+	m.lock.Lock()
+	m.resources = resources
+	cellPresence := m.Config.cellPresence(resources)
+	m.lock.Unlock()
+
 	return m.serviceClient.NewCellPresenceRunner(m.logger, &cellPresence, m.RetryInterval, m.lockTTL), nil
 }
 
+func (m *Maintainer) republishPresence() {
+	m.lock.Lock()
+	cellPresence := m.Config.cellPresence(m.resources)
+	m.lock.Unlock()
+
+	err := m.serviceClient.UpdateCellPresence(m.logger, &cellPresence)
+	if err != nil {
+		m.logger.Error("failed-to-republish-presence", err)
+		return
+	}
+	m.logger.Info("republished-presence")
+}
+
 func (m *Maintainer) heartbeat(sigChan <-chan os.Signal, ready chan<- struct{}, heartbeater ifrit.Runner) error {
 	m.logger.Info("start-heartbeating")
 	defer m.logger.Info("complete-heartbeating")
@@ -138,8 +209,17 @@ func (m *Maintainer) heartbeat(sigChan <-chan os.Signal, ready chan<- struct{},
 		close(ready)
 	}
 
+	// the heartbeater writes the presence it was started with whenever it
+	// reacquires the lock, so once the placement has changed the presence is
+	// rewritten on every tick
+	republished := false
+
 	for {
 		select {
+		case <-m.republish:
+			republished = true
+			m.republishPresence()
+
 		case err := <-heartbeatExitChan:
 			m.logger.Error("heartbeat-lost-lock", err)
 			return err
@@ -154,6 +234,9 @@ func (m *Maintainer) heartbeat(sigChan <-chan os.Signal, ready chan<- struct{},
 			m.logger.Debug("heartbeat-pinging-executor")
 			err := m.executorClient.Ping(m.logger)
 			if err == nil {
+				if republished {
+					m.republishPresence()
+				}
 				continue
 			}
 
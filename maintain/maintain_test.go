@@ -11,6 +11,7 @@ import (
 	fake_client "code.cloudfoundry.org/executor/fakes"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/maintain"
 	"code.cloudfoundry.org/rep/maintain/maintainfakes"
 	"github.com/tedsuo/ifrit"
@@ -29,7 +30,7 @@ var _ = Describe("Maintain Presence", func() {
 		serviceClient   *maintainfakes.FakeCellPresenceClient
 		logger          *lagertest.TestLogger
 
-		maintainer        ifrit.Runner
+		maintainer        *maintain.Maintainer
 		maintainProcess   ifrit.Process
 		heartbeaterErrors chan error
 		observedSignals   chan os.Signal
@@ -83,6 +84,8 @@ var _ = Describe("Maintain Presence", func() {
 			RootFSProviders:       []string{"provider-1", "provider-2"},
 			PlacementTags:         []string{"test-tag-1", "test-tag-2"},
 			OptionalPlacementTags: []string{"optional-test-tag-1", "optional-test-tag-2"},
+			Labels:                map[string]string{"disk": "ssd"},
+			Topology:              []string{"region", "az1", "rack-1"},
 		}
 		maintainer = maintain.New(logger, config, fakeClient, serviceClient, 10*time.Second, clock)
 	})
@@ -182,17 +185,21 @@ var _ = Describe("Maintain Presence", func() {
 			It("starts maintaining presence", func() {
 				Expect(serviceClient.NewCellPresenceRunnerCallCount()).To(Equal(1))
 
-				expectedPresence := models.NewCellPresence(
-					"cell-id",
-					"1.2.3.4",
-					"https://cell-id.service.cf.internal",
-					"az1",
-					models.NewCellCapacity(128, 1024, 6),
-					[]string{"provider-1", "provider-2"},
-					[]string{},
-					[]string{"test-tag-1", "test-tag-2"},
-					[]string{"optional-test-tag-1", "optional-test-tag-2"},
+				expectedPresence := maintain.NewCellPresence(
+					models.NewCellPresence(
+						"cell-id",
+						"1.2.3.4",
+						"https://cell-id.service.cf.internal",
+						"az1",
+						models.NewCellCapacity(128, 1024, 6),
+						[]string{"provider-1", "provider-2"},
+						[]string{},
+						[]string{"test-tag-1", "test-tag-2"},
+						[]string{"optional-test-tag-1", "optional-test-tag-2"},
+					),
+					map[string]string{"disk": "ssd"},
 				)
+				expectedPresence.Topology = []string{"region", "az1", "rack-1"}
 
 				_, presence, retryInterval, lockTTL := serviceClient.NewCellPresenceRunnerArgsForCall(0)
 				Expect(*presence).To(Equal(expectedPresence))
@@ -253,6 +260,33 @@ var _ = Describe("Maintain Presence", func() {
 				})
 			})
 
+			Context("when the placement changes", func() {
+				BeforeEach(func() {
+					maintainer.UpdatePlacement(rep.StackPathMap{"cflinuxfs3": "/path"}, []string{"new-tag"}, nil)
+				})
+
+				It("updates the presence in place", func() {
+					Eventually(serviceClient.UpdateCellPresenceCallCount).Should(Equal(1))
+					_, presence := serviceClient.UpdateCellPresenceArgsForCall(0)
+					Expect(presence.CellId).To(Equal("cell-id"))
+					Expect(presence.PlacementTags).To(Equal([]string{"new-tag"}))
+					Expect(presence.OptionalPlacementTags).To(BeEmpty())
+				})
+
+				It("keeps heartbeating the same presence", func() {
+					Eventually(serviceClient.UpdateCellPresenceCallCount).Should(Equal(1))
+					Consistently(observedSignals).ShouldNot(Receive())
+					Expect(serviceClient.NewCellPresenceRunnerCallCount()).To(Equal(1))
+				})
+
+				It("rewrites the presence on every tick in case the heartbeater reacquired its lock", func() {
+					Eventually(serviceClient.UpdateCellPresenceCallCount).Should(Equal(1))
+					pingErrors <- nil
+					clock.Increment(1 * time.Second)
+					Eventually(serviceClient.UpdateCellPresenceCallCount).Should(Equal(2))
+				})
+			})
+
 			Context("when heartbeating fails", func() {
 				BeforeEach(func() {
 					heartbeaterErrors <- errors.New("heartbeating failed")
@@ -12,11 +12,11 @@ import (
 )
 
 type FakeCellPresenceClient struct {
-	NewCellPresenceRunnerStub        func(logger lager.Logger, cellPresence *models.CellPresence, retryInterval, lockTTL time.Duration) ifrit.Runner
+	NewCellPresenceRunnerStub        func(logger lager.Logger, cellPresence *maintain.CellPresence, retryInterval, lockTTL time.Duration) ifrit.Runner
 	newCellPresenceRunnerMutex       sync.RWMutex
 	newCellPresenceRunnerArgsForCall []struct {
 		logger        lager.Logger
-		cellPresence  *models.CellPresence
+		cellPresence  *maintain.CellPresence
 		retryInterval time.Duration
 		lockTTL       time.Duration
 	}
@@ -64,16 +64,28 @@ type FakeCellPresenceClient struct {
 	cellEventsReturnsOnCall map[int]struct {
 		result1 <-chan models.CellEvent
 	}
+	UpdateCellPresenceStub        func(logger lager.Logger, cellPresence *maintain.CellPresence) error
+	updateCellPresenceMutex       sync.RWMutex
+	updateCellPresenceArgsForCall []struct {
+		logger       lager.Logger
+		cellPresence *maintain.CellPresence
+	}
+	updateCellPresenceReturns struct {
+		result1 error
+	}
+	updateCellPresenceReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeCellPresenceClient) NewCellPresenceRunner(logger lager.Logger, cellPresence *models.CellPresence, retryInterval time.Duration, lockTTL time.Duration) ifrit.Runner {
+func (fake *FakeCellPresenceClient) NewCellPresenceRunner(logger lager.Logger, cellPresence *maintain.CellPresence, retryInterval time.Duration, lockTTL time.Duration) ifrit.Runner {
 	fake.newCellPresenceRunnerMutex.Lock()
 	ret, specificReturn := fake.newCellPresenceRunnerReturnsOnCall[len(fake.newCellPresenceRunnerArgsForCall)]
 	fake.newCellPresenceRunnerArgsForCall = append(fake.newCellPresenceRunnerArgsForCall, struct {
 		logger        lager.Logger
-		cellPresence  *models.CellPresence
+		cellPresence  *maintain.CellPresence
 		retryInterval time.Duration
 		lockTTL       time.Duration
 	}{logger, cellPresence, retryInterval, lockTTL})
@@ -94,7 +106,7 @@ func (fake *FakeCellPresenceClient) NewCellPresenceRunnerCallCount() int {
 	return len(fake.newCellPresenceRunnerArgsForCall)
 }
 
-func (fake *FakeCellPresenceClient) NewCellPresenceRunnerArgsForCall(i int) (lager.Logger, *models.CellPresence, time.Duration, time.Duration) {
+func (fake *FakeCellPresenceClient) NewCellPresenceRunnerArgsForCall(i int) (lager.Logger, *maintain.CellPresence, time.Duration, time.Duration) {
 	fake.newCellPresenceRunnerMutex.RLock()
 	defer fake.newCellPresenceRunnerMutex.RUnlock()
 	return fake.newCellPresenceRunnerArgsForCall[i].logger, fake.newCellPresenceRunnerArgsForCall[i].cellPresence, fake.newCellPresenceRunnerArgsForCall[i].retryInterval, fake.newCellPresenceRunnerArgsForCall[i].lockTTL
@@ -270,6 +282,55 @@ func (fake *FakeCellPresenceClient) CellEventsReturnsOnCall(i int, result1 <-cha
 	}{result1}
 }
 
+func (fake *FakeCellPresenceClient) UpdateCellPresence(logger lager.Logger, cellPresence *maintain.CellPresence) error {
+	fake.updateCellPresenceMutex.Lock()
+	ret, specificReturn := fake.updateCellPresenceReturnsOnCall[len(fake.updateCellPresenceArgsForCall)]
+	fake.updateCellPresenceArgsForCall = append(fake.updateCellPresenceArgsForCall, struct {
+		logger       lager.Logger
+		cellPresence *maintain.CellPresence
+	}{logger, cellPresence})
+	fake.recordInvocation("UpdateCellPresence", []interface{}{logger, cellPresence})
+	fake.updateCellPresenceMutex.Unlock()
+	if fake.UpdateCellPresenceStub != nil {
+		return fake.UpdateCellPresenceStub(logger, cellPresence)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateCellPresenceReturns.result1
+}
+
+func (fake *FakeCellPresenceClient) UpdateCellPresenceCallCount() int {
+	fake.updateCellPresenceMutex.RLock()
+	defer fake.updateCellPresenceMutex.RUnlock()
+	return len(fake.updateCellPresenceArgsForCall)
+}
+
+func (fake *FakeCellPresenceClient) UpdateCellPresenceArgsForCall(i int) (lager.Logger, *maintain.CellPresence) {
+	fake.updateCellPresenceMutex.RLock()
+	defer fake.updateCellPresenceMutex.RUnlock()
+	return fake.updateCellPresenceArgsForCall[i].logger, fake.updateCellPresenceArgsForCall[i].cellPresence
+}
+
+func (fake *FakeCellPresenceClient) UpdateCellPresenceReturns(result1 error) {
+	fake.UpdateCellPresenceStub = nil
+	fake.updateCellPresenceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCellPresenceClient) UpdateCellPresenceReturnsOnCall(i int, result1 error) {
+	fake.UpdateCellPresenceStub = nil
+	if fake.updateCellPresenceReturnsOnCall == nil {
+		fake.updateCellPresenceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateCellPresenceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeCellPresenceClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -281,6 +342,8 @@ func (fake *FakeCellPresenceClient) Invocations() map[string][][]interface{} {
 	defer fake.cellsMutex.RUnlock()
 	fake.cellEventsMutex.RLock()
 	defer fake.cellEventsMutex.RUnlock()
+	fake.updateCellPresenceMutex.RLock()
+	defer fake.updateCellPresenceMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -0,0 +1,44 @@
+package rep
+
+// Overcommit converts the physical capacity of the cell into the capacity the
+// executor allocates against, and so the capacity the cell advertises. The
+// reserved headroom is set aside for the system first and the remainder is
+// scaled by the ratio. A ratio of zero is unset and treated as 1; the rep
+// refuses to start with a ratio below 1.
+type Overcommit struct {
+	MemoryRatio      float64
+	DiskRatio        float64
+	ReservedMemoryMB int32
+	ReservedDiskMB   int32
+}
+
+// Ceiling is the largest amount of memory and disk a single container may
+// use, regardless of the overcommit ratios.
+func (o Overcommit) Ceiling(physical Resources) Resources {
+	return Resources{
+		MemoryMB: nonNegative(physical.MemoryMB - o.ReservedMemoryMB),
+		DiskMB:   nonNegative(physical.DiskMB - o.ReservedDiskMB),
+	}
+}
+
+func (o Overcommit) Total(physical Resources) Resources {
+	ceiling := o.Ceiling(physical)
+	total := physical
+	total.MemoryMB = scale(ceiling.MemoryMB, o.MemoryRatio)
+	total.DiskMB = scale(ceiling.DiskMB, o.DiskRatio)
+	return total
+}
+
+func scale(value int32, ratio float64) int32 {
+	if ratio == 0 {
+		return value
+	}
+	return int32(float64(value) * ratio)
+}
+
+func nonNegative(value int32) int32 {
+	if value < 0 {
+		return 0
+	}
+	return value
+}
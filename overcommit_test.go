@@ -0,0 +1,69 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Overcommit", func() {
+	var (
+		physicalTotal rep.Resources
+		overcommit    rep.Overcommit
+	)
+
+	BeforeEach(func() {
+		physicalTotal = rep.NewResources(1024, 2048, 10)
+		overcommit = rep.Overcommit{}
+	})
+
+	Context("when no overcommit is configured", func() {
+		It("passes the physical resources through", func() {
+			Expect(overcommit.Total(physicalTotal)).To(Equal(physicalTotal))
+			Expect(overcommit.Ceiling(physicalTotal)).To(Equal(rep.Resources{MemoryMB: 1024, DiskMB: 2048}))
+		})
+	})
+
+	Context("when ratios and reserved headroom are configured", func() {
+		BeforeEach(func() {
+			overcommit = rep.Overcommit{
+				MemoryRatio:      2,
+				DiskRatio:        1.5,
+				ReservedMemoryMB: 24,
+				ReservedDiskMB:   48,
+			}
+		})
+
+		It("scales what is left after the reserved headroom", func() {
+			Expect(overcommit.Total(physicalTotal)).To(Equal(rep.NewResources(2000, 3000, 10)))
+		})
+
+		It("caps a single container at the physical resources less the headroom", func() {
+			Expect(overcommit.Ceiling(physicalTotal)).To(Equal(rep.Resources{MemoryMB: 1000, DiskMB: 2000}))
+		})
+
+		It("never advertises negative resources", func() {
+			overcommit.ReservedMemoryMB = 4096
+			Expect(overcommit.Total(physicalTotal).MemoryMB).To(BeZero())
+		})
+	})
+
+	Context("when the cell advertises a resource ceiling", func() {
+		var cellState rep.CellState
+
+		BeforeEach(func() {
+			cellState = rep.NewCellState(nil, rep.NewResources(2000, 3000, 5), rep.NewResources(2000, 3000, 10), nil, nil, "my-zone", 0, false, nil, nil, nil)
+			cellState.ResourceCeiling = &rep.Resources{MemoryMB: 1000, DiskMB: 2000}
+		})
+
+		It("rejects work larger than the ceiling even if the overcommitted capacity is available", func() {
+			resource := rep.NewResource(1500, 2500, 0)
+			Expect(cellState.ResourceMatch(&resource)).To(MatchError("insufficient resources: disk, memory"))
+		})
+
+		It("accepts work within the ceiling", func() {
+			resource := rep.NewResource(1000, 2000, 0)
+			Expect(cellState.ResourceMatch(&resource)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,195 @@
+package rep
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	RootFSInvalidURL        = "invalid-url"
+	RootFSUnsupportedScheme = "unsupported-scheme"
+	RootFSNotProvided       = "not-provided"
+)
+
+// PlacementExplanation describes, constraint by constraint, whether a cell can
+// run a piece of work and why not.
+type PlacementExplanation struct {
+	Placeable               bool                `json:"placeable"`
+	Cordoned                bool                `json:"cordoned,omitempty"`
+	RootFS                  RootFSVerdict       `json:"rootfs"`
+	MissingVolumeDrivers    []string            `json:"missing_volume_drivers,omitempty"`
+	MissingPlacementTags    []string            `json:"missing_placement_tags,omitempty"`
+	UnexpectedPlacementTags []string            `json:"unexpected_placement_tags,omitempty"`
+	UnmatchedLabelSelectors []string            `json:"unmatched_label_selectors,omitempty"`
+	InsufficientResources   []ResourceShortfall `json:"insufficient_resources,omitempty"`
+}
+
+type RootFSVerdict struct {
+	RootFS  string `json:"rootfs"`
+	Scheme  string `json:"scheme,omitempty"`
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type ResourceShortfall struct {
+	Resource  string `json:"resource"`
+	Requested int64  `json:"requested"`
+	Available int64  `json:"available"`
+}
+
+func (c *CellState) ExplainLRP(lrp *LRP) PlacementExplanation {
+	return c.Explain(&lrp.PlacementConstraint, &lrp.Resource)
+}
+
+func (c *CellState) ExplainTask(task *Task) PlacementExplanation {
+	return c.Explain(&task.PlacementConstraint, &task.Resource)
+}
+
+func (c *CellState) Explain(pc *PlacementConstraint, res *Resource) PlacementExplanation {
+	explanation := c.ExplainConstraints(pc)
+	explanation.InsufficientResources = c.resourceShortfalls(res)
+	explanation.Placeable = explanation.Placeable && len(explanation.InsufficientResources) == 0
+	return explanation
+}
+
+// ExplainConstraints is like Explain but ignores the resources available on
+// the cell.
+func (c *CellState) ExplainConstraints(pc *PlacementConstraint) PlacementExplanation {
+	explanation := PlacementExplanation{
+		Cordoned:             c.Cordoned,
+		RootFS:               c.explainRootFS(pc.RootFs),
+		MissingVolumeDrivers: c.missingVolumeDrivers(pc.VolumeDrivers),
+	}
+
+	desiredTags := toSet(pc.PlacementTags)
+	requiredTags := toSet(c.PlacementTags)
+	allTags := requiredTags.union(toSet(c.OptionalPlacementTags))
+	explanation.MissingPlacementTags = requiredTags.difference(desiredTags)
+	explanation.UnexpectedPlacementTags = desiredTags.difference(allTags)
+
+	for _, requirement := range pc.LabelSelector.Unmatched(c.Labels) {
+		explanation.UnmatchedLabelSelectors = append(explanation.UnmatchedLabelSelectors, requirement.String())
+	}
+
+	explanation.Placeable = explanation.Reason() == ""
+	return explanation
+}
+
+// Reason summarizes the constraints that were not satisfied, or returns an
+// empty string if there are none.
+func (e *PlacementExplanation) Reason() string {
+	reasons := []string{}
+	if e.Cordoned {
+		reasons = append(reasons, CellCordonedReason)
+	}
+	if !e.RootFS.Matched {
+		reasons = append(reasons, fmt.Sprintf("rootfs %s: %s", e.RootFS.Reason, e.RootFS.RootFS))
+	}
+	if len(e.MissingVolumeDrivers) > 0 {
+		reasons = append(reasons, "missing volume drivers: "+strings.Join(e.MissingVolumeDrivers, ", "))
+	}
+	if len(e.MissingPlacementTags) > 0 {
+		reasons = append(reasons, "missing placement tags: "+strings.Join(e.MissingPlacementTags, ", "))
+	}
+	if len(e.UnexpectedPlacementTags) > 0 {
+		reasons = append(reasons, "unexpected placement tags: "+strings.Join(e.UnexpectedPlacementTags, ", "))
+	}
+	if len(e.UnmatchedLabelSelectors) > 0 {
+		reasons = append(reasons, "unmatched label selectors: "+strings.Join(e.UnmatchedLabelSelectors, ", "))
+	}
+	if len(e.InsufficientResources) > 0 {
+		names := make([]string, 0, len(e.InsufficientResources))
+		for _, shortfall := range e.InsufficientResources {
+			names = append(names, shortfall.Resource)
+		}
+		reasons = append(reasons, "insufficient resources: "+strings.Join(names, ", "))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func (c *CellState) explainRootFS(rootfs string) RootFSVerdict {
+	verdict := RootFSVerdict{RootFS: rootfs}
+
+	rootFSURL, err := url.Parse(rootfs)
+	if err != nil {
+		verdict.Reason = RootFSInvalidURL
+		return verdict
+	}
+	verdict.Scheme = rootFSURL.Scheme
+
+	provider, ok := c.RootFSProviders[rootFSURL.Scheme]
+	if !ok || provider == nil {
+		verdict.Reason = RootFSUnsupportedScheme
+		return verdict
+	}
+
+	verdict.Matched = provider.Match(*rootFSURL)
+	if !verdict.Matched {
+		verdict.Reason = RootFSNotProvided
+	}
+	return verdict
+}
+
+func (c *CellState) missingVolumeDrivers(volumeDrivers []string) []string {
+	return toSet(volumeDrivers).difference(toSet(c.VolumeDrivers))
+}
+
+func (c *CellState) resourceShortfalls(res *Resource) []ResourceShortfall {
+	shortfalls := []ResourceShortfall{}
+	add := func(name string, requested, available int64) {
+		shortfalls = append(shortfalls, ResourceShortfall{Resource: name, Requested: requested, Available: available})
+	}
+
+	if c.AvailableResources.DiskMB < res.DiskMB {
+		add("disk", int64(res.DiskMB), int64(c.AvailableResources.DiskMB))
+	} else if c.ResourceCeiling != nil && c.ResourceCeiling.DiskMB > 0 && c.ResourceCeiling.DiskMB < res.DiskMB {
+		add("disk", int64(res.DiskMB), int64(c.ResourceCeiling.DiskMB))
+	}
+	if c.AvailableResources.MemoryMB < res.MemoryMB {
+		add("memory", int64(res.MemoryMB), int64(c.AvailableResources.MemoryMB))
+	} else if c.ResourceCeiling != nil && c.ResourceCeiling.MemoryMB > 0 && c.ResourceCeiling.MemoryMB < res.MemoryMB {
+		add("memory", int64(res.MemoryMB), int64(c.ResourceCeiling.MemoryMB))
+	}
+	if c.AvailableResources.Containers < 1 {
+		add("containers", 1, int64(c.AvailableResources.Containers))
+	}
+	// once the cell limits pids, work without a limit of its own could use
+	// them all up
+	if c.TotalResources.MaxPids > 0 && (res.MaxPids == 0 || c.AvailableResources.MaxPids < res.MaxPids) {
+		add("pids", int64(res.MaxPids), int64(c.AvailableResources.MaxPids))
+	}
+	if c.TotalResources.CPUWeight > 0 && c.AvailableResources.CPUWeight < res.CPUWeight {
+		add("cpu", int64(res.CPUWeight), int64(c.AvailableResources.CPUWeight))
+	}
+
+	names := make([]string, 0, len(res.Extended))
+	for name := range res.Extended {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		requested, available := res.Extended[name], c.AvailableResources.Extended[name]
+		if requested > 0 && available < requested {
+			add(name, int64(requested), int64(available))
+		}
+	}
+
+	if len(shortfalls) == 0 {
+		return nil
+	}
+	return shortfalls
+}
+
+// difference returns the sorted members of set that are not in other.
+func (set placementTagSet) difference(other placementTagSet) []string {
+	var missing []string
+	for k := range set {
+		if _, ok := other[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
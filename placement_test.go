@@ -0,0 +1,136 @@
+package rep_test
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Explain", func() {
+	var (
+		cellState rep.CellState
+		lrp       *rep.LRP
+	)
+
+	BeforeEach(func() {
+		cellState = rep.NewCellState(
+			rep.RootFSProviders{
+				models.PreloadedRootFSScheme: rep.NewFixedSetRootFSProvider("linux"),
+				"docker":                     rep.ArbitraryRootFSProvider{},
+			},
+			rep.NewResources(100, 200, 1),
+			rep.NewResources(1000, 2000, 10),
+			nil,
+			nil,
+			"my-zone",
+			0,
+			false,
+			[]string{"driver-1"},
+			[]string{"required"},
+			[]string{"optional"},
+		)
+
+		lrp = BuildLRP("pg-1", "domain", 0, models.PreloadedRootFS("linux"), 10, 20, 0)
+		lrp.PlacementTags = []string{"required"}
+	})
+
+	It("explains a placeable lrp", func() {
+		explanation := cellState.ExplainLRP(lrp)
+		Expect(explanation.Placeable).To(BeTrue())
+		Expect(explanation.RootFS.Matched).To(BeTrue())
+		Expect(explanation.MissingVolumeDrivers).To(BeEmpty())
+		Expect(explanation.MissingPlacementTags).To(BeEmpty())
+		Expect(explanation.UnexpectedPlacementTags).To(BeEmpty())
+		Expect(explanation.InsufficientResources).To(BeEmpty())
+	})
+
+	It("does not place anything on a cordoned cell", func() {
+		cellState.Cordoned = true
+		explanation := cellState.ExplainLRP(lrp)
+		Expect(explanation.Placeable).To(BeFalse())
+		Expect(explanation.Cordoned).To(BeTrue())
+		Expect(explanation.Reason()).To(Equal(rep.CellCordonedReason))
+	})
+
+	Describe("rootfs", func() {
+		It("reports an unsupported scheme", func() {
+			lrp.RootFs = "oci://some/image"
+			explanation := cellState.ExplainLRP(lrp)
+			Expect(explanation.Placeable).To(BeFalse())
+			Expect(explanation.RootFS).To(Equal(rep.RootFSVerdict{
+				RootFS: "oci://some/image",
+				Scheme: "oci",
+				Reason: rep.RootFSUnsupportedScheme,
+			}))
+		})
+
+		It("reports a stack the cell does not provide", func() {
+			lrp.RootFs = models.PreloadedRootFS("windows")
+			explanation := cellState.ExplainLRP(lrp)
+			Expect(explanation.RootFS.Matched).To(BeFalse())
+			Expect(explanation.RootFS.Reason).To(Equal(rep.RootFSNotProvided))
+		})
+
+		It("reports an invalid url", func() {
+			lrp.RootFs = "%x"
+			explanation := cellState.ExplainLRP(lrp)
+			Expect(explanation.RootFS.Reason).To(Equal(rep.RootFSInvalidURL))
+		})
+	})
+
+	It("reports each missing volume driver", func() {
+		task := BuildTask("tg-1", "domain", "docker:///busybox", 10, 20, 0, []string{"driver-1", "driver-3", "driver-2"})
+		task.PlacementTags = []string{"required"}
+		explanation := cellState.ExplainTask(task)
+		Expect(explanation.Placeable).To(BeFalse())
+		Expect(explanation.MissingVolumeDrivers).To(Equal([]string{"driver-2", "driver-3"}))
+	})
+
+	It("reports missing and unexpected placement tags", func() {
+		lrp.PlacementTags = []string{"optional", "other"}
+		explanation := cellState.ExplainLRP(lrp)
+		Expect(explanation.Placeable).To(BeFalse())
+		Expect(explanation.MissingPlacementTags).To(Equal([]string{"required"}))
+		Expect(explanation.UnexpectedPlacementTags).To(Equal([]string{"other"}))
+	})
+
+	It("reports unmatched label selectors alongside placement tags", func() {
+		cellState.Labels = map[string]string{"disk": "ssd"}
+		lrp.PlacementTags = []string{}
+		lrp.LabelSelector = rep.LabelSelector{
+			{Key: "disk", Operator: rep.LabelSelectorIn, Values: []string{"ssd"}},
+			{Key: "gpu", Operator: rep.LabelSelectorExists},
+		}
+		explanation := cellState.ExplainLRP(lrp)
+		Expect(explanation.Placeable).To(BeFalse())
+		Expect(explanation.MissingPlacementTags).To(Equal([]string{"required"}))
+		Expect(explanation.UnmatchedLabelSelectors).To(Equal([]string{"gpu"}))
+		Expect(explanation.Reason()).To(Equal("missing placement tags: required; unmatched label selectors: gpu"))
+	})
+
+	It("reports each short resource with requested and available amounts", func() {
+		lrp.MemoryMB = 500
+		cellState.AvailableResources.Containers = 0
+		explanation := cellState.ExplainLRP(lrp)
+		Expect(explanation.Placeable).To(BeFalse())
+		Expect(explanation.InsufficientResources).To(ConsistOf(
+			rep.ResourceShortfall{Resource: "memory", Requested: 500, Available: 100},
+			rep.ResourceShortfall{Resource: "containers", Requested: 1, Available: 0},
+		))
+	})
+
+	It("serializes to json", func() {
+		lrp.RootFs = "oci://some/image"
+		lrp.DiskMB = 300
+		payload, err := json.Marshal(cellState.ExplainLRP(lrp))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payload).To(MatchJSON(`{
+			"placeable": false,
+			"rootfs": {"rootfs": "oci://some/image", "scheme": "oci", "matched": false, "reason": "unsupported-scheme"},
+			"insufficient_resources": [{"resource": "disk", "requested": 300, "available": 200}]
+		}`))
+	})
+})
@@ -0,0 +1 @@
+package reloader // import "code.cloudfoundry.org/rep/reloader"
@@ -0,0 +1,184 @@
+package reloader
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// Placement is the part of the rep configuration that can be reloaded
+// without restarting the rep.
+type Placement struct {
+	PreloadedRootFS       rep.StackPathMap
+	PlacementTags         []string
+	OptionalPlacementTags []string
+}
+
+type LoadFunc func() (Placement, error)
+
+//go:generate counterfeiter . PlacementUpdater
+
+type PlacementUpdater interface {
+	UpdatePlacement(preloadedStackPathMap rep.StackPathMap, placementTags, optionalPlacementTags []string)
+}
+
+// Reloader reloads the placement settings when it receives a signal on its
+// trigger channel, or when the modification time of the config file changes
+// if a poll interval is set, and passes any changes on to its updaters.
+type Reloader struct {
+	logger       lager.Logger
+	clock        clock.Clock
+	configPath   string
+	pollInterval time.Duration
+	trigger      <-chan os.Signal
+	load         LoadFunc
+	updaters     []PlacementUpdater
+	current      Placement
+}
+
+func New(
+	logger lager.Logger,
+	clock clock.Clock,
+	configPath string,
+	pollInterval time.Duration,
+	trigger <-chan os.Signal,
+	current Placement,
+	load LoadFunc,
+	updaters ...PlacementUpdater,
+) *Reloader {
+	return &Reloader{
+		logger:       logger.Session("reloader"),
+		clock:        clock,
+		configPath:   configPath,
+		pollInterval: pollInterval,
+		trigger:      trigger,
+		load:         load,
+		updaters:     updaters,
+		current:      current,
+	}
+}
+
+func (r *Reloader) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	var poll <-chan time.Time
+	var modTime time.Time
+	if r.pollInterval > 0 {
+		modTime = r.modTime()
+		ticker := r.clock.NewTicker(r.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C()
+	}
+
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+
+		case <-r.trigger:
+			r.logger.Info("received-reload-signal")
+			r.reload()
+
+		case <-poll:
+			latest := r.modTime()
+			if latest.Equal(modTime) {
+				continue
+			}
+			modTime = latest
+			r.logger.Info("config-file-changed")
+			r.reload()
+		}
+	}
+}
+
+func (r *Reloader) modTime() time.Time {
+	info, err := os.Stat(r.configPath)
+	if err != nil {
+		r.logger.Error("failed-to-stat-config-file", err)
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func (r *Reloader) reload() {
+	logger := r.logger.Session("reload")
+
+	placement, err := r.load()
+	if err != nil {
+		logger.Error("failed-to-load-config", err)
+		return
+	}
+
+	diff := NewDiff(r.current, placement)
+	if diff.Empty() {
+		logger.Info("unchanged")
+		return
+	}
+
+	logger.Info("placement-changed", lager.Data{"diff": diff})
+	for _, updater := range r.updaters {
+		updater.UpdatePlacement(placement.PreloadedRootFS, placement.PlacementTags, placement.OptionalPlacementTags)
+	}
+	r.current = placement
+}
+
+// Diff lists the stacks and placement tags that a reload adds, removes or,
+// for stacks, points at a different path.
+type Diff struct {
+	AddedStacks                  []string `json:"added_stacks,omitempty"`
+	RemovedStacks                []string `json:"removed_stacks,omitempty"`
+	ChangedStacks                []string `json:"changed_stacks,omitempty"`
+	AddedPlacementTags           []string `json:"added_placement_tags,omitempty"`
+	RemovedPlacementTags         []string `json:"removed_placement_tags,omitempty"`
+	AddedOptionalPlacementTags   []string `json:"added_optional_placement_tags,omitempty"`
+	RemovedOptionalPlacementTags []string `json:"removed_optional_placement_tags,omitempty"`
+}
+
+func NewDiff(old, new Placement) Diff {
+	diff := Diff{}
+	for stack, path := range new.PreloadedRootFS {
+		oldPath, ok := old.PreloadedRootFS[stack]
+		if !ok {
+			diff.AddedStacks = append(diff.AddedStacks, stack)
+		} else if oldPath != path {
+			diff.ChangedStacks = append(diff.ChangedStacks, stack)
+		}
+	}
+	for stack := range old.PreloadedRootFS {
+		if _, ok := new.PreloadedRootFS[stack]; !ok {
+			diff.RemovedStacks = append(diff.RemovedStacks, stack)
+		}
+	}
+	sort.Strings(diff.AddedStacks)
+	sort.Strings(diff.RemovedStacks)
+	sort.Strings(diff.ChangedStacks)
+
+	diff.AddedPlacementTags = difference(new.PlacementTags, old.PlacementTags)
+	diff.RemovedPlacementTags = difference(old.PlacementTags, new.PlacementTags)
+	diff.AddedOptionalPlacementTags = difference(new.OptionalPlacementTags, old.OptionalPlacementTags)
+	diff.RemovedOptionalPlacementTags = difference(old.OptionalPlacementTags, new.OptionalPlacementTags)
+	return diff
+}
+
+func (d Diff) Empty() bool {
+	return len(d.AddedStacks) == 0 && len(d.RemovedStacks) == 0 && len(d.ChangedStacks) == 0 &&
+		len(d.AddedPlacementTags) == 0 && len(d.RemovedPlacementTags) == 0 &&
+		len(d.AddedOptionalPlacementTags) == 0 && len(d.RemovedOptionalPlacementTags) == 0
+}
+
+// difference returns the sorted entries of a that are not in b.
+func difference(a, b []string) []string {
+	set := rep.NewStringSet(b...)
+	var result []string
+	for _, entry := range a {
+		if !set.Contains(entry) {
+			result = append(result, entry)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
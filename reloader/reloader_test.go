@@ -0,0 +1,159 @@
+package reloader_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/reloader"
+	"code.cloudfoundry.org/rep/reloader/reloaderfakes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Reloader", func() {
+	var (
+		logger       *lagertest.TestLogger
+		clock        *fakeclock.FakeClock
+		configPath   string
+		pollInterval time.Duration
+		trigger      chan os.Signal
+		initial      reloader.Placement
+		loaded       reloader.Placement
+		loadErr      error
+		updater      *reloaderfakes.FakePlacementUpdater
+
+		process ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		clock = fakeclock.NewFakeClock(time.Now())
+		pollInterval = 0
+		trigger = make(chan os.Signal, 1)
+		updater = &reloaderfakes.FakePlacementUpdater{}
+		loadErr = nil
+
+		configFile, err := ioutil.TempFile("", "rep-config")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configFile.Close()).To(Succeed())
+		configPath = configFile.Name()
+
+		initial = reloader.Placement{
+			PreloadedRootFS: rep.StackPathMap{"cflinuxfs3": "/path/to/cflinuxfs3"},
+			PlacementTags:   []string{"tag-1"},
+		}
+		loaded = initial
+	})
+
+	JustBeforeEach(func() {
+		load := func() (reloader.Placement, error) {
+			return loaded, loadErr
+		}
+		r := reloader.New(logger, clock, configPath, pollInterval, trigger, initial, load, updater)
+		process = ginkgomon.Invoke(r)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+		Expect(os.Remove(configPath)).To(Succeed())
+	})
+
+	Context("when signaled to reload", func() {
+		It("passes the new placement to the updaters and logs the diff", func() {
+			loaded = reloader.Placement{
+				PreloadedRootFS: rep.StackPathMap{
+					"cflinuxfs3":       "/path/to/cflinuxfs3",
+					"cflinuxfs4@1.0.0": "/path/to/cflinuxfs4",
+				},
+				PlacementTags:         []string{"tag-2"},
+				OptionalPlacementTags: []string{"optional-tag"},
+			}
+			trigger <- syscall.SIGHUP
+
+			Eventually(updater.UpdatePlacementCallCount).Should(Equal(1))
+			stackPathMap, placementTags, optionalPlacementTags := updater.UpdatePlacementArgsForCall(0)
+			Expect(stackPathMap).To(Equal(loaded.PreloadedRootFS))
+			Expect(placementTags).To(Equal([]string{"tag-2"}))
+			Expect(optionalPlacementTags).To(Equal([]string{"optional-tag"}))
+
+			Expect(logger).To(gbytes.Say(`"added_stacks":\["cflinuxfs4@1.0.0"\]`))
+			Expect(logger).To(gbytes.Say(`"added_placement_tags":\["tag-2"\],"removed_placement_tags":\["tag-1"\]`))
+		})
+
+		It("does not update when nothing changed", func() {
+			trigger <- syscall.SIGHUP
+			Eventually(logger).Should(gbytes.Say("unchanged"))
+			Expect(updater.UpdatePlacementCallCount()).To(BeZero())
+		})
+
+		It("keeps the current placement when the config cannot be loaded", func() {
+			loadErr = errors.New("bad config")
+			trigger <- syscall.SIGHUP
+			Eventually(logger).Should(gbytes.Say("failed-to-load-config"))
+			Expect(updater.UpdatePlacementCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when polling the config file", func() {
+		BeforeEach(func() {
+			pollInterval = time.Second
+		})
+
+		It("reloads when the config file changes", func() {
+			loaded.PlacementTags = []string{"tag-1", "tag-2"}
+
+			clock.WaitForWatcherAndIncrement(time.Second)
+			Consistently(updater.UpdatePlacementCallCount).Should(BeZero())
+
+			modTime := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(configPath, modTime, modTime)).To(Succeed())
+			clock.WaitForWatcherAndIncrement(time.Second)
+
+			Eventually(updater.UpdatePlacementCallCount).Should(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Diff", func() {
+	It("lists added, removed and changed stacks and tags", func() {
+		diff := reloader.NewDiff(
+			reloader.Placement{
+				PreloadedRootFS:       rep.StackPathMap{"a": "/a", "b": "/b", "c": "/c"},
+				PlacementTags:         []string{"x", "y"},
+				OptionalPlacementTags: []string{"o"},
+			},
+			reloader.Placement{
+				PreloadedRootFS: rep.StackPathMap{"a": "/a", "b": "/new-b", "d": "/d"},
+				PlacementTags:   []string{"y", "z"},
+			},
+		)
+
+		Expect(diff).To(Equal(reloader.Diff{
+			AddedStacks:                  []string{"d"},
+			RemovedStacks:                []string{"c"},
+			ChangedStacks:                []string{"b"},
+			AddedPlacementTags:           []string{"z"},
+			RemovedPlacementTags:         []string{"x"},
+			RemovedOptionalPlacementTags: []string{"o"},
+		}))
+		Expect(diff.Empty()).To(BeFalse())
+	})
+
+	It("ignores the order of placement tags", func() {
+		diff := reloader.NewDiff(
+			reloader.Placement{PlacementTags: []string{"x", "y"}},
+			reloader.Placement{PlacementTags: []string{"y", "x"}},
+		)
+		Expect(diff.Empty()).To(BeTrue())
+	})
+})
@@ -0,0 +1,56 @@
+// This file was generated by counterfeiter
+package reloaderfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/reloader"
+)
+
+type FakePlacementUpdater struct {
+	UpdatePlacementStub        func(preloadedStackPathMap rep.StackPathMap, placementTags, optionalPlacementTags []string)
+	updatePlacementMutex       sync.RWMutex
+	updatePlacementArgsForCall []struct {
+		preloadedStackPathMap rep.StackPathMap
+		placementTags         []string
+		optionalPlacementTags []string
+	}
+}
+
+func (fake *FakePlacementUpdater) UpdatePlacement(preloadedStackPathMap rep.StackPathMap, placementTags []string, optionalPlacementTags []string) {
+	var placementTagsCopy []string
+	if placementTags != nil {
+		placementTagsCopy = make([]string, len(placementTags))
+		copy(placementTagsCopy, placementTags)
+	}
+	var optionalPlacementTagsCopy []string
+	if optionalPlacementTags != nil {
+		optionalPlacementTagsCopy = make([]string, len(optionalPlacementTags))
+		copy(optionalPlacementTagsCopy, optionalPlacementTags)
+	}
+	fake.updatePlacementMutex.Lock()
+	fake.updatePlacementArgsForCall = append(fake.updatePlacementArgsForCall, struct {
+		preloadedStackPathMap rep.StackPathMap
+		placementTags         []string
+		optionalPlacementTags []string
+	}{preloadedStackPathMap, placementTagsCopy, optionalPlacementTagsCopy})
+	fake.updatePlacementMutex.Unlock()
+	if fake.UpdatePlacementStub != nil {
+		fake.UpdatePlacementStub(preloadedStackPathMap, placementTags, optionalPlacementTags)
+	}
+}
+
+func (fake *FakePlacementUpdater) UpdatePlacementCallCount() int {
+	fake.updatePlacementMutex.RLock()
+	defer fake.updatePlacementMutex.RUnlock()
+	return len(fake.updatePlacementArgsForCall)
+}
+
+func (fake *FakePlacementUpdater) UpdatePlacementArgsForCall(i int) (rep.StackPathMap, []string, []string) {
+	fake.updatePlacementMutex.RLock()
+	defer fake.updatePlacementMutex.RUnlock()
+	return fake.updatePlacementArgsForCall[i].preloadedStackPathMap, fake.updatePlacementArgsForCall[i].placementTags, fake.updatePlacementArgsForCall[i].optionalPlacementTags
+}
+
+var _ reloader.PlacementUpdater = new(FakePlacementUpdater)
@@ -0,0 +1 @@
+package reloaderfakes // import "code.cloudfoundry.org/rep/reloader/reloaderfakes"
@@ -17,7 +17,7 @@ import (
 var (
 	cfHttpTimeout time.Duration
 	auctionRep    *repfakes.FakeClient
-	factory       rep.ClientFactory
+	factory       rep.ContextClientFactory
 
 	client, clientForServerThatErrors rep.Client
 
@@ -0,0 +1,1306 @@
+// This file was generated by counterfeiter
+package repfakes
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+type FakeContextClient struct {
+	StateStub        func(logger lager.Logger) (rep.CellState, error)
+	stateMutex       sync.RWMutex
+	stateArgsForCall []struct {
+		logger lager.Logger
+	}
+	stateReturns struct {
+		result1 rep.CellState
+		result2 error
+	}
+	stateReturnsOnCall map[int]struct {
+		result1 rep.CellState
+		result2 error
+	}
+	PerformStub        func(logger lager.Logger, work rep.Work) (rep.Work, error)
+	performMutex       sync.RWMutex
+	performArgsForCall []struct {
+		logger lager.Logger
+		work   rep.Work
+	}
+	performReturns struct {
+		result1 rep.Work
+		result2 error
+	}
+	performReturnsOnCall map[int]struct {
+		result1 rep.Work
+		result2 error
+	}
+	StopLRPInstanceStub        func(logger lager.Logger, key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) error
+	stopLRPInstanceMutex       sync.RWMutex
+	stopLRPInstanceArgsForCall []struct {
+		logger      lager.Logger
+		key         models.ActualLRPKey
+		instanceKey models.ActualLRPInstanceKey
+	}
+	stopLRPInstanceReturns struct {
+		result1 error
+	}
+	stopLRPInstanceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CancelTaskStub        func(logger lager.Logger, taskGuid string) error
+	cancelTaskMutex       sync.RWMutex
+	cancelTaskArgsForCall []struct {
+		logger   lager.Logger
+		taskGuid string
+	}
+	cancelTaskReturns struct {
+		result1 error
+	}
+	cancelTaskReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetStateClientStub        func(stateClient *http.Client)
+	setStateClientMutex       sync.RWMutex
+	setStateClientArgsForCall []struct {
+		stateClient *http.Client
+	}
+	StateClientTimeoutStub        func() time.Duration
+	stateClientTimeoutMutex       sync.RWMutex
+	stateClientTimeoutArgsForCall []struct{}
+	stateClientTimeoutReturns     struct {
+		result1 time.Duration
+	}
+	stateClientTimeoutReturnsOnCall map[int]struct {
+		result1 time.Duration
+	}
+	StateWithContextStub        func(ctx context.Context, logger lager.Logger) (rep.CellState, error)
+	stateWithContextMutex       sync.RWMutex
+	stateWithContextArgsForCall []struct {
+		ctx    context.Context
+		logger lager.Logger
+	}
+	stateWithContextReturns struct {
+		result1 rep.CellState
+		result2 error
+	}
+	stateWithContextReturnsOnCall map[int]struct {
+		result1 rep.CellState
+		result2 error
+	}
+	PerformWithContextStub        func(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error)
+	performWithContextMutex       sync.RWMutex
+	performWithContextArgsForCall []struct {
+		ctx    context.Context
+		logger lager.Logger
+		work   rep.Work
+	}
+	performWithContextReturns struct {
+		result1 rep.Work
+		result2 error
+	}
+	performWithContextReturnsOnCall map[int]struct {
+		result1 rep.Work
+		result2 error
+	}
+	StopLRPInstanceWithContextStub        func(ctx context.Context, logger lager.Logger, key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) error
+	stopLRPInstanceWithContextMutex       sync.RWMutex
+	stopLRPInstanceWithContextArgsForCall []struct {
+		ctx         context.Context
+		logger      lager.Logger
+		key         models.ActualLRPKey
+		instanceKey models.ActualLRPInstanceKey
+	}
+	stopLRPInstanceWithContextReturns struct {
+		result1 error
+	}
+	stopLRPInstanceWithContextReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CancelTaskWithContextStub        func(ctx context.Context, logger lager.Logger, taskGuid string) error
+	cancelTaskWithContextMutex       sync.RWMutex
+	cancelTaskWithContextArgsForCall []struct {
+		ctx      context.Context
+		logger   lager.Logger
+		taskGuid string
+	}
+	cancelTaskWithContextReturns struct {
+		result1 error
+	}
+	cancelTaskWithContextReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StopLRPInstancesStub        func(logger lager.Logger, instances []rep.LRPInstanceKeys) ([]rep.BatchResult, error)
+	stopLRPInstancesMutex       sync.RWMutex
+	stopLRPInstancesArgsForCall []struct {
+		logger    lager.Logger
+		instances []rep.LRPInstanceKeys
+	}
+	stopLRPInstancesReturns struct {
+		result1 []rep.BatchResult
+		result2 error
+	}
+	stopLRPInstancesReturnsOnCall map[int]struct {
+		result1 []rep.BatchResult
+		result2 error
+	}
+	StopLRPInstancesWithContextStub        func(ctx context.Context, logger lager.Logger, instances []rep.LRPInstanceKeys) ([]rep.BatchResult, error)
+	stopLRPInstancesWithContextMutex       sync.RWMutex
+	stopLRPInstancesWithContextArgsForCall []struct {
+		ctx       context.Context
+		logger    lager.Logger
+		instances []rep.LRPInstanceKeys
+	}
+	stopLRPInstancesWithContextReturns struct {
+		result1 []rep.BatchResult
+		result2 error
+	}
+	stopLRPInstancesWithContextReturnsOnCall map[int]struct {
+		result1 []rep.BatchResult
+		result2 error
+	}
+	CancelTasksStub        func(logger lager.Logger, taskGuids []string) ([]rep.BatchResult, error)
+	cancelTasksMutex       sync.RWMutex
+	cancelTasksArgsForCall []struct {
+		logger    lager.Logger
+		taskGuids []string
+	}
+	cancelTasksReturns struct {
+		result1 []rep.BatchResult
+		result2 error
+	}
+	cancelTasksReturnsOnCall map[int]struct {
+		result1 []rep.BatchResult
+		result2 error
+	}
+	CancelTasksWithContextStub        func(ctx context.Context, logger lager.Logger, taskGuids []string) ([]rep.BatchResult, error)
+	cancelTasksWithContextMutex       sync.RWMutex
+	cancelTasksWithContextArgsForCall []struct {
+		ctx       context.Context
+		logger    lager.Logger
+		taskGuids []string
+	}
+	cancelTasksWithContextReturns struct {
+		result1 []rep.BatchResult
+		result2 error
+	}
+	cancelTasksWithContextReturnsOnCall map[int]struct {
+		result1 []rep.BatchResult
+		result2 error
+	}
+	StateSinceStub        func(ctx context.Context, logger lager.Logger, since rep.StateVersion, wait time.Duration) (rep.CellState, rep.StateVersion, bool, error)
+	stateSinceMutex       sync.RWMutex
+	stateSinceArgsForCall []struct {
+		ctx    context.Context
+		logger lager.Logger
+		since  rep.StateVersion
+		wait   time.Duration
+	}
+	stateSinceReturns struct {
+		result1 rep.CellState
+		result2 rep.StateVersion
+		result3 bool
+		result4 error
+	}
+	stateSinceReturnsOnCall map[int]struct {
+		result1 rep.CellState
+		result2 rep.StateVersion
+		result3 bool
+		result4 error
+	}
+	ContainersStub        func(logger lager.Logger, filter rep.ContainerFilter) ([]rep.ContainerInfo, error)
+	containersMutex       sync.RWMutex
+	containersArgsForCall []struct {
+		logger lager.Logger
+		filter rep.ContainerFilter
+	}
+	containersReturns struct {
+		result1 []rep.ContainerInfo
+		result2 error
+	}
+	containersReturnsOnCall map[int]struct {
+		result1 []rep.ContainerInfo
+		result2 error
+	}
+	ContainersWithContextStub        func(ctx context.Context, logger lager.Logger, filter rep.ContainerFilter) ([]rep.ContainerInfo, error)
+	containersWithContextMutex       sync.RWMutex
+	containersWithContextArgsForCall []struct {
+		ctx    context.Context
+		logger lager.Logger
+		filter rep.ContainerFilter
+	}
+	containersWithContextReturns struct {
+		result1 []rep.ContainerInfo
+		result2 error
+	}
+	containersWithContextReturnsOnCall map[int]struct {
+		result1 []rep.ContainerInfo
+		result2 error
+	}
+	ContainerStub        func(logger lager.Logger, guid string) (rep.ContainerInfo, error)
+	containerMutex       sync.RWMutex
+	containerArgsForCall []struct {
+		logger lager.Logger
+		guid   string
+	}
+	containerReturns struct {
+		result1 rep.ContainerInfo
+		result2 error
+	}
+	containerReturnsOnCall map[int]struct {
+		result1 rep.ContainerInfo
+		result2 error
+	}
+	ContainerWithContextStub        func(ctx context.Context, logger lager.Logger, guid string) (rep.ContainerInfo, error)
+	containerWithContextMutex       sync.RWMutex
+	containerWithContextArgsForCall []struct {
+		ctx    context.Context
+		logger lager.Logger
+		guid   string
+	}
+	containerWithContextReturns struct {
+		result1 rep.ContainerInfo
+		result2 error
+	}
+	containerWithContextReturnsOnCall map[int]struct {
+		result1 rep.ContainerInfo
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeContextClient) State(logger lager.Logger) (rep.CellState, error) {
+	fake.stateMutex.Lock()
+	ret, specificReturn := fake.stateReturnsOnCall[len(fake.stateArgsForCall)]
+	fake.stateArgsForCall = append(fake.stateArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("State", []interface{}{logger})
+	fake.stateMutex.Unlock()
+	if fake.StateStub != nil {
+		return fake.StateStub(logger)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.stateReturns.result1, fake.stateReturns.result2
+}
+
+func (fake *FakeContextClient) StateCallCount() int {
+	fake.stateMutex.RLock()
+	defer fake.stateMutex.RUnlock()
+	return len(fake.stateArgsForCall)
+}
+
+func (fake *FakeContextClient) StateArgsForCall(i int) lager.Logger {
+	fake.stateMutex.RLock()
+	defer fake.stateMutex.RUnlock()
+	return fake.stateArgsForCall[i].logger
+}
+
+func (fake *FakeContextClient) StateReturns(result1 rep.CellState, result2 error) {
+	fake.StateStub = nil
+	fake.stateReturns = struct {
+		result1 rep.CellState
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) StateReturnsOnCall(i int, result1 rep.CellState, result2 error) {
+	fake.StateStub = nil
+	if fake.stateReturnsOnCall == nil {
+		fake.stateReturnsOnCall = make(map[int]struct {
+			result1 rep.CellState
+			result2 error
+		})
+	}
+	fake.stateReturnsOnCall[i] = struct {
+		result1 rep.CellState
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) Perform(logger lager.Logger, work rep.Work) (rep.Work, error) {
+	fake.performMutex.Lock()
+	ret, specificReturn := fake.performReturnsOnCall[len(fake.performArgsForCall)]
+	fake.performArgsForCall = append(fake.performArgsForCall, struct {
+		logger lager.Logger
+		work   rep.Work
+	}{logger, work})
+	fake.recordInvocation("Perform", []interface{}{logger, work})
+	fake.performMutex.Unlock()
+	if fake.PerformStub != nil {
+		return fake.PerformStub(logger, work)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.performReturns.result1, fake.performReturns.result2
+}
+
+func (fake *FakeContextClient) PerformCallCount() int {
+	fake.performMutex.RLock()
+	defer fake.performMutex.RUnlock()
+	return len(fake.performArgsForCall)
+}
+
+func (fake *FakeContextClient) PerformArgsForCall(i int) (lager.Logger, rep.Work) {
+	fake.performMutex.RLock()
+	defer fake.performMutex.RUnlock()
+	return fake.performArgsForCall[i].logger, fake.performArgsForCall[i].work
+}
+
+func (fake *FakeContextClient) PerformReturns(result1 rep.Work, result2 error) {
+	fake.PerformStub = nil
+	fake.performReturns = struct {
+		result1 rep.Work
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) PerformReturnsOnCall(i int, result1 rep.Work, result2 error) {
+	fake.PerformStub = nil
+	if fake.performReturnsOnCall == nil {
+		fake.performReturnsOnCall = make(map[int]struct {
+			result1 rep.Work
+			result2 error
+		})
+	}
+	fake.performReturnsOnCall[i] = struct {
+		result1 rep.Work
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) StopLRPInstance(logger lager.Logger, key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) error {
+	fake.stopLRPInstanceMutex.Lock()
+	ret, specificReturn := fake.stopLRPInstanceReturnsOnCall[len(fake.stopLRPInstanceArgsForCall)]
+	fake.stopLRPInstanceArgsForCall = append(fake.stopLRPInstanceArgsForCall, struct {
+		logger      lager.Logger
+		key         models.ActualLRPKey
+		instanceKey models.ActualLRPInstanceKey
+	}{logger, key, instanceKey})
+	fake.recordInvocation("StopLRPInstance", []interface{}{logger, key, instanceKey})
+	fake.stopLRPInstanceMutex.Unlock()
+	if fake.StopLRPInstanceStub != nil {
+		return fake.StopLRPInstanceStub(logger, key, instanceKey)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.stopLRPInstanceReturns.result1
+}
+
+func (fake *FakeContextClient) StopLRPInstanceCallCount() int {
+	fake.stopLRPInstanceMutex.RLock()
+	defer fake.stopLRPInstanceMutex.RUnlock()
+	return len(fake.stopLRPInstanceArgsForCall)
+}
+
+func (fake *FakeContextClient) StopLRPInstanceArgsForCall(i int) (lager.Logger, models.ActualLRPKey, models.ActualLRPInstanceKey) {
+	fake.stopLRPInstanceMutex.RLock()
+	defer fake.stopLRPInstanceMutex.RUnlock()
+	return fake.stopLRPInstanceArgsForCall[i].logger, fake.stopLRPInstanceArgsForCall[i].key, fake.stopLRPInstanceArgsForCall[i].instanceKey
+}
+
+func (fake *FakeContextClient) StopLRPInstanceReturns(result1 error) {
+	fake.StopLRPInstanceStub = nil
+	fake.stopLRPInstanceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextClient) StopLRPInstanceReturnsOnCall(i int, result1 error) {
+	fake.StopLRPInstanceStub = nil
+	if fake.stopLRPInstanceReturnsOnCall == nil {
+		fake.stopLRPInstanceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.stopLRPInstanceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextClient) CancelTask(logger lager.Logger, taskGuid string) error {
+	fake.cancelTaskMutex.Lock()
+	ret, specificReturn := fake.cancelTaskReturnsOnCall[len(fake.cancelTaskArgsForCall)]
+	fake.cancelTaskArgsForCall = append(fake.cancelTaskArgsForCall, struct {
+		logger   lager.Logger
+		taskGuid string
+	}{logger, taskGuid})
+	fake.recordInvocation("CancelTask", []interface{}{logger, taskGuid})
+	fake.cancelTaskMutex.Unlock()
+	if fake.CancelTaskStub != nil {
+		return fake.CancelTaskStub(logger, taskGuid)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.cancelTaskReturns.result1
+}
+
+func (fake *FakeContextClient) CancelTaskCallCount() int {
+	fake.cancelTaskMutex.RLock()
+	defer fake.cancelTaskMutex.RUnlock()
+	return len(fake.cancelTaskArgsForCall)
+}
+
+func (fake *FakeContextClient) CancelTaskArgsForCall(i int) (lager.Logger, string) {
+	fake.cancelTaskMutex.RLock()
+	defer fake.cancelTaskMutex.RUnlock()
+	return fake.cancelTaskArgsForCall[i].logger, fake.cancelTaskArgsForCall[i].taskGuid
+}
+
+func (fake *FakeContextClient) CancelTaskReturns(result1 error) {
+	fake.CancelTaskStub = nil
+	fake.cancelTaskReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextClient) CancelTaskReturnsOnCall(i int, result1 error) {
+	fake.CancelTaskStub = nil
+	if fake.cancelTaskReturnsOnCall == nil {
+		fake.cancelTaskReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cancelTaskReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextClient) SetStateClient(stateClient *http.Client) {
+	fake.setStateClientMutex.Lock()
+	fake.setStateClientArgsForCall = append(fake.setStateClientArgsForCall, struct {
+		stateClient *http.Client
+	}{stateClient})
+	fake.recordInvocation("SetStateClient", []interface{}{stateClient})
+	fake.setStateClientMutex.Unlock()
+	if fake.SetStateClientStub != nil {
+		fake.SetStateClientStub(stateClient)
+	}
+}
+
+func (fake *FakeContextClient) SetStateClientCallCount() int {
+	fake.setStateClientMutex.RLock()
+	defer fake.setStateClientMutex.RUnlock()
+	return len(fake.setStateClientArgsForCall)
+}
+
+func (fake *FakeContextClient) SetStateClientArgsForCall(i int) *http.Client {
+	fake.setStateClientMutex.RLock()
+	defer fake.setStateClientMutex.RUnlock()
+	return fake.setStateClientArgsForCall[i].stateClient
+}
+
+func (fake *FakeContextClient) StateClientTimeout() time.Duration {
+	fake.stateClientTimeoutMutex.Lock()
+	ret, specificReturn := fake.stateClientTimeoutReturnsOnCall[len(fake.stateClientTimeoutArgsForCall)]
+	fake.stateClientTimeoutArgsForCall = append(fake.stateClientTimeoutArgsForCall, struct{}{})
+	fake.recordInvocation("StateClientTimeout", []interface{}{})
+	fake.stateClientTimeoutMutex.Unlock()
+	if fake.StateClientTimeoutStub != nil {
+		return fake.StateClientTimeoutStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.stateClientTimeoutReturns.result1
+}
+
+func (fake *FakeContextClient) StateClientTimeoutCallCount() int {
+	fake.stateClientTimeoutMutex.RLock()
+	defer fake.stateClientTimeoutMutex.RUnlock()
+	return len(fake.stateClientTimeoutArgsForCall)
+}
+
+func (fake *FakeContextClient) StateClientTimeoutReturns(result1 time.Duration) {
+	fake.StateClientTimeoutStub = nil
+	fake.stateClientTimeoutReturns = struct {
+		result1 time.Duration
+	}{result1}
+}
+
+func (fake *FakeContextClient) StateClientTimeoutReturnsOnCall(i int, result1 time.Duration) {
+	fake.StateClientTimeoutStub = nil
+	if fake.stateClientTimeoutReturnsOnCall == nil {
+		fake.stateClientTimeoutReturnsOnCall = make(map[int]struct {
+			result1 time.Duration
+		})
+	}
+	fake.stateClientTimeoutReturnsOnCall[i] = struct {
+		result1 time.Duration
+	}{result1}
+}
+
+func (fake *FakeContextClient) StateWithContext(ctx context.Context, logger lager.Logger) (rep.CellState, error) {
+	fake.stateWithContextMutex.Lock()
+	ret, specificReturn := fake.stateWithContextReturnsOnCall[len(fake.stateWithContextArgsForCall)]
+	fake.stateWithContextArgsForCall = append(fake.stateWithContextArgsForCall, struct {
+		ctx    context.Context
+		logger lager.Logger
+	}{ctx, logger})
+	fake.recordInvocation("StateWithContext", []interface{}{ctx, logger})
+	fake.stateWithContextMutex.Unlock()
+	if fake.StateWithContextStub != nil {
+		return fake.StateWithContextStub(ctx, logger)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.stateWithContextReturns.result1, fake.stateWithContextReturns.result2
+}
+
+func (fake *FakeContextClient) StateWithContextCallCount() int {
+	fake.stateWithContextMutex.RLock()
+	defer fake.stateWithContextMutex.RUnlock()
+	return len(fake.stateWithContextArgsForCall)
+}
+
+func (fake *FakeContextClient) StateWithContextArgsForCall(i int) (context.Context, lager.Logger) {
+	fake.stateWithContextMutex.RLock()
+	defer fake.stateWithContextMutex.RUnlock()
+	return fake.stateWithContextArgsForCall[i].ctx, fake.stateWithContextArgsForCall[i].logger
+}
+
+func (fake *FakeContextClient) StateWithContextReturns(result1 rep.CellState, result2 error) {
+	fake.StateWithContextStub = nil
+	fake.stateWithContextReturns = struct {
+		result1 rep.CellState
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) StateWithContextReturnsOnCall(i int, result1 rep.CellState, result2 error) {
+	fake.StateWithContextStub = nil
+	if fake.stateWithContextReturnsOnCall == nil {
+		fake.stateWithContextReturnsOnCall = make(map[int]struct {
+			result1 rep.CellState
+			result2 error
+		})
+	}
+	fake.stateWithContextReturnsOnCall[i] = struct {
+		result1 rep.CellState
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) PerformWithContext(ctx context.Context, logger lager.Logger, work rep.Work) (rep.Work, error) {
+	fake.performWithContextMutex.Lock()
+	ret, specificReturn := fake.performWithContextReturnsOnCall[len(fake.performWithContextArgsForCall)]
+	fake.performWithContextArgsForCall = append(fake.performWithContextArgsForCall, struct {
+		ctx    context.Context
+		logger lager.Logger
+		work   rep.Work
+	}{ctx, logger, work})
+	fake.recordInvocation("PerformWithContext", []interface{}{ctx, logger, work})
+	fake.performWithContextMutex.Unlock()
+	if fake.PerformWithContextStub != nil {
+		return fake.PerformWithContextStub(ctx, logger, work)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.performWithContextReturns.result1, fake.performWithContextReturns.result2
+}
+
+func (fake *FakeContextClient) PerformWithContextCallCount() int {
+	fake.performWithContextMutex.RLock()
+	defer fake.performWithContextMutex.RUnlock()
+	return len(fake.performWithContextArgsForCall)
+}
+
+func (fake *FakeContextClient) PerformWithContextArgsForCall(i int) (context.Context, lager.Logger, rep.Work) {
+	fake.performWithContextMutex.RLock()
+	defer fake.performWithContextMutex.RUnlock()
+	return fake.performWithContextArgsForCall[i].ctx, fake.performWithContextArgsForCall[i].logger, fake.performWithContextArgsForCall[i].work
+}
+
+func (fake *FakeContextClient) PerformWithContextReturns(result1 rep.Work, result2 error) {
+	fake.PerformWithContextStub = nil
+	fake.performWithContextReturns = struct {
+		result1 rep.Work
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) PerformWithContextReturnsOnCall(i int, result1 rep.Work, result2 error) {
+	fake.PerformWithContextStub = nil
+	if fake.performWithContextReturnsOnCall == nil {
+		fake.performWithContextReturnsOnCall = make(map[int]struct {
+			result1 rep.Work
+			result2 error
+		})
+	}
+	fake.performWithContextReturnsOnCall[i] = struct {
+		result1 rep.Work
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) StopLRPInstanceWithContext(ctx context.Context, logger lager.Logger, key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) error {
+	fake.stopLRPInstanceWithContextMutex.Lock()
+	ret, specificReturn := fake.stopLRPInstanceWithContextReturnsOnCall[len(fake.stopLRPInstanceWithContextArgsForCall)]
+	fake.stopLRPInstanceWithContextArgsForCall = append(fake.stopLRPInstanceWithContextArgsForCall, struct {
+		ctx         context.Context
+		logger      lager.Logger
+		key         models.ActualLRPKey
+		instanceKey models.ActualLRPInstanceKey
+	}{ctx, logger, key, instanceKey})
+	fake.recordInvocation("StopLRPInstanceWithContext", []interface{}{ctx, logger, key, instanceKey})
+	fake.stopLRPInstanceWithContextMutex.Unlock()
+	if fake.StopLRPInstanceWithContextStub != nil {
+		return fake.StopLRPInstanceWithContextStub(ctx, logger, key, instanceKey)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.stopLRPInstanceWithContextReturns.result1
+}
+
+func (fake *FakeContextClient) StopLRPInstanceWithContextCallCount() int {
+	fake.stopLRPInstanceWithContextMutex.RLock()
+	defer fake.stopLRPInstanceWithContextMutex.RUnlock()
+	return len(fake.stopLRPInstanceWithContextArgsForCall)
+}
+
+func (fake *FakeContextClient) StopLRPInstanceWithContextArgsForCall(i int) (context.Context, lager.Logger, models.ActualLRPKey, models.ActualLRPInstanceKey) {
+	fake.stopLRPInstanceWithContextMutex.RLock()
+	defer fake.stopLRPInstanceWithContextMutex.RUnlock()
+	return fake.stopLRPInstanceWithContextArgsForCall[i].ctx, fake.stopLRPInstanceWithContextArgsForCall[i].logger, fake.stopLRPInstanceWithContextArgsForCall[i].key, fake.stopLRPInstanceWithContextArgsForCall[i].instanceKey
+}
+
+func (fake *FakeContextClient) StopLRPInstanceWithContextReturns(result1 error) {
+	fake.StopLRPInstanceWithContextStub = nil
+	fake.stopLRPInstanceWithContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextClient) StopLRPInstanceWithContextReturnsOnCall(i int, result1 error) {
+	fake.StopLRPInstanceWithContextStub = nil
+	if fake.stopLRPInstanceWithContextReturnsOnCall == nil {
+		fake.stopLRPInstanceWithContextReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.stopLRPInstanceWithContextReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextClient) CancelTaskWithContext(ctx context.Context, logger lager.Logger, taskGuid string) error {
+	fake.cancelTaskWithContextMutex.Lock()
+	ret, specificReturn := fake.cancelTaskWithContextReturnsOnCall[len(fake.cancelTaskWithContextArgsForCall)]
+	fake.cancelTaskWithContextArgsForCall = append(fake.cancelTaskWithContextArgsForCall, struct {
+		ctx      context.Context
+		logger   lager.Logger
+		taskGuid string
+	}{ctx, logger, taskGuid})
+	fake.recordInvocation("CancelTaskWithContext", []interface{}{ctx, logger, taskGuid})
+	fake.cancelTaskWithContextMutex.Unlock()
+	if fake.CancelTaskWithContextStub != nil {
+		return fake.CancelTaskWithContextStub(ctx, logger, taskGuid)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.cancelTaskWithContextReturns.result1
+}
+
+func (fake *FakeContextClient) CancelTaskWithContextCallCount() int {
+	fake.cancelTaskWithContextMutex.RLock()
+	defer fake.cancelTaskWithContextMutex.RUnlock()
+	return len(fake.cancelTaskWithContextArgsForCall)
+}
+
+func (fake *FakeContextClient) CancelTaskWithContextArgsForCall(i int) (context.Context, lager.Logger, string) {
+	fake.cancelTaskWithContextMutex.RLock()
+	defer fake.cancelTaskWithContextMutex.RUnlock()
+	return fake.cancelTaskWithContextArgsForCall[i].ctx, fake.cancelTaskWithContextArgsForCall[i].logger, fake.cancelTaskWithContextArgsForCall[i].taskGuid
+}
+
+func (fake *FakeContextClient) CancelTaskWithContextReturns(result1 error) {
+	fake.CancelTaskWithContextStub = nil
+	fake.cancelTaskWithContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextClient) CancelTaskWithContextReturnsOnCall(i int, result1 error) {
+	fake.CancelTaskWithContextStub = nil
+	if fake.cancelTaskWithContextReturnsOnCall == nil {
+		fake.cancelTaskWithContextReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cancelTaskWithContextReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContextClient) StopLRPInstances(logger lager.Logger, instances []rep.LRPInstanceKeys) ([]rep.BatchResult, error) {
+	var instancesCopy []rep.LRPInstanceKeys
+	if instances != nil {
+		instancesCopy = make([]rep.LRPInstanceKeys, len(instances))
+		copy(instancesCopy, instances)
+	}
+	fake.stopLRPInstancesMutex.Lock()
+	ret, specificReturn := fake.stopLRPInstancesReturnsOnCall[len(fake.stopLRPInstancesArgsForCall)]
+	fake.stopLRPInstancesArgsForCall = append(fake.stopLRPInstancesArgsForCall, struct {
+		logger    lager.Logger
+		instances []rep.LRPInstanceKeys
+	}{logger, instancesCopy})
+	fake.recordInvocation("StopLRPInstances", []interface{}{logger, instancesCopy})
+	fake.stopLRPInstancesMutex.Unlock()
+	if fake.StopLRPInstancesStub != nil {
+		return fake.StopLRPInstancesStub(logger, instances)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.stopLRPInstancesReturns.result1, fake.stopLRPInstancesReturns.result2
+}
+
+func (fake *FakeContextClient) StopLRPInstancesCallCount() int {
+	fake.stopLRPInstancesMutex.RLock()
+	defer fake.stopLRPInstancesMutex.RUnlock()
+	return len(fake.stopLRPInstancesArgsForCall)
+}
+
+func (fake *FakeContextClient) StopLRPInstancesArgsForCall(i int) (lager.Logger, []rep.LRPInstanceKeys) {
+	fake.stopLRPInstancesMutex.RLock()
+	defer fake.stopLRPInstancesMutex.RUnlock()
+	return fake.stopLRPInstancesArgsForCall[i].logger, fake.stopLRPInstancesArgsForCall[i].instances
+}
+
+func (fake *FakeContextClient) StopLRPInstancesReturns(result1 []rep.BatchResult, result2 error) {
+	fake.StopLRPInstancesStub = nil
+	fake.stopLRPInstancesReturns = struct {
+		result1 []rep.BatchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) StopLRPInstancesReturnsOnCall(i int, result1 []rep.BatchResult, result2 error) {
+	fake.StopLRPInstancesStub = nil
+	if fake.stopLRPInstancesReturnsOnCall == nil {
+		fake.stopLRPInstancesReturnsOnCall = make(map[int]struct {
+			result1 []rep.BatchResult
+			result2 error
+		})
+	}
+	fake.stopLRPInstancesReturnsOnCall[i] = struct {
+		result1 []rep.BatchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) StopLRPInstancesWithContext(ctx context.Context, logger lager.Logger, instances []rep.LRPInstanceKeys) ([]rep.BatchResult, error) {
+	var instancesCopy []rep.LRPInstanceKeys
+	if instances != nil {
+		instancesCopy = make([]rep.LRPInstanceKeys, len(instances))
+		copy(instancesCopy, instances)
+	}
+	fake.stopLRPInstancesWithContextMutex.Lock()
+	ret, specificReturn := fake.stopLRPInstancesWithContextReturnsOnCall[len(fake.stopLRPInstancesWithContextArgsForCall)]
+	fake.stopLRPInstancesWithContextArgsForCall = append(fake.stopLRPInstancesWithContextArgsForCall, struct {
+		ctx       context.Context
+		logger    lager.Logger
+		instances []rep.LRPInstanceKeys
+	}{ctx, logger, instancesCopy})
+	fake.recordInvocation("StopLRPInstancesWithContext", []interface{}{ctx, logger, instancesCopy})
+	fake.stopLRPInstancesWithContextMutex.Unlock()
+	if fake.StopLRPInstancesWithContextStub != nil {
+		return fake.StopLRPInstancesWithContextStub(ctx, logger, instances)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.stopLRPInstancesWithContextReturns.result1, fake.stopLRPInstancesWithContextReturns.result2
+}
+
+func (fake *FakeContextClient) StopLRPInstancesWithContextCallCount() int {
+	fake.stopLRPInstancesWithContextMutex.RLock()
+	defer fake.stopLRPInstancesWithContextMutex.RUnlock()
+	return len(fake.stopLRPInstancesWithContextArgsForCall)
+}
+
+func (fake *FakeContextClient) StopLRPInstancesWithContextArgsForCall(i int) (context.Context, lager.Logger, []rep.LRPInstanceKeys) {
+	fake.stopLRPInstancesWithContextMutex.RLock()
+	defer fake.stopLRPInstancesWithContextMutex.RUnlock()
+	return fake.stopLRPInstancesWithContextArgsForCall[i].ctx, fake.stopLRPInstancesWithContextArgsForCall[i].logger, fake.stopLRPInstancesWithContextArgsForCall[i].instances
+}
+
+func (fake *FakeContextClient) StopLRPInstancesWithContextReturns(result1 []rep.BatchResult, result2 error) {
+	fake.StopLRPInstancesWithContextStub = nil
+	fake.stopLRPInstancesWithContextReturns = struct {
+		result1 []rep.BatchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) StopLRPInstancesWithContextReturnsOnCall(i int, result1 []rep.BatchResult, result2 error) {
+	fake.StopLRPInstancesWithContextStub = nil
+	if fake.stopLRPInstancesWithContextReturnsOnCall == nil {
+		fake.stopLRPInstancesWithContextReturnsOnCall = make(map[int]struct {
+			result1 []rep.BatchResult
+			result2 error
+		})
+	}
+	fake.stopLRPInstancesWithContextReturnsOnCall[i] = struct {
+		result1 []rep.BatchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) CancelTasks(logger lager.Logger, taskGuids []string) ([]rep.BatchResult, error) {
+	var taskGuidsCopy []string
+	if taskGuids != nil {
+		taskGuidsCopy = make([]string, len(taskGuids))
+		copy(taskGuidsCopy, taskGuids)
+	}
+	fake.cancelTasksMutex.Lock()
+	ret, specificReturn := fake.cancelTasksReturnsOnCall[len(fake.cancelTasksArgsForCall)]
+	fake.cancelTasksArgsForCall = append(fake.cancelTasksArgsForCall, struct {
+		logger    lager.Logger
+		taskGuids []string
+	}{logger, taskGuidsCopy})
+	fake.recordInvocation("CancelTasks", []interface{}{logger, taskGuidsCopy})
+	fake.cancelTasksMutex.Unlock()
+	if fake.CancelTasksStub != nil {
+		return fake.CancelTasksStub(logger, taskGuids)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.cancelTasksReturns.result1, fake.cancelTasksReturns.result2
+}
+
+func (fake *FakeContextClient) CancelTasksCallCount() int {
+	fake.cancelTasksMutex.RLock()
+	defer fake.cancelTasksMutex.RUnlock()
+	return len(fake.cancelTasksArgsForCall)
+}
+
+func (fake *FakeContextClient) CancelTasksArgsForCall(i int) (lager.Logger, []string) {
+	fake.cancelTasksMutex.RLock()
+	defer fake.cancelTasksMutex.RUnlock()
+	return fake.cancelTasksArgsForCall[i].logger, fake.cancelTasksArgsForCall[i].taskGuids
+}
+
+func (fake *FakeContextClient) CancelTasksReturns(result1 []rep.BatchResult, result2 error) {
+	fake.CancelTasksStub = nil
+	fake.cancelTasksReturns = struct {
+		result1 []rep.BatchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) CancelTasksReturnsOnCall(i int, result1 []rep.BatchResult, result2 error) {
+	fake.CancelTasksStub = nil
+	if fake.cancelTasksReturnsOnCall == nil {
+		fake.cancelTasksReturnsOnCall = make(map[int]struct {
+			result1 []rep.BatchResult
+			result2 error
+		})
+	}
+	fake.cancelTasksReturnsOnCall[i] = struct {
+		result1 []rep.BatchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) CancelTasksWithContext(ctx context.Context, logger lager.Logger, taskGuids []string) ([]rep.BatchResult, error) {
+	var taskGuidsCopy []string
+	if taskGuids != nil {
+		taskGuidsCopy = make([]string, len(taskGuids))
+		copy(taskGuidsCopy, taskGuids)
+	}
+	fake.cancelTasksWithContextMutex.Lock()
+	ret, specificReturn := fake.cancelTasksWithContextReturnsOnCall[len(fake.cancelTasksWithContextArgsForCall)]
+	fake.cancelTasksWithContextArgsForCall = append(fake.cancelTasksWithContextArgsForCall, struct {
+		ctx       context.Context
+		logger    lager.Logger
+		taskGuids []string
+	}{ctx, logger, taskGuidsCopy})
+	fake.recordInvocation("CancelTasksWithContext", []interface{}{ctx, logger, taskGuidsCopy})
+	fake.cancelTasksWithContextMutex.Unlock()
+	if fake.CancelTasksWithContextStub != nil {
+		return fake.CancelTasksWithContextStub(ctx, logger, taskGuids)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.cancelTasksWithContextReturns.result1, fake.cancelTasksWithContextReturns.result2
+}
+
+func (fake *FakeContextClient) CancelTasksWithContextCallCount() int {
+	fake.cancelTasksWithContextMutex.RLock()
+	defer fake.cancelTasksWithContextMutex.RUnlock()
+	return len(fake.cancelTasksWithContextArgsForCall)
+}
+
+func (fake *FakeContextClient) CancelTasksWithContextArgsForCall(i int) (context.Context, lager.Logger, []string) {
+	fake.cancelTasksWithContextMutex.RLock()
+	defer fake.cancelTasksWithContextMutex.RUnlock()
+	return fake.cancelTasksWithContextArgsForCall[i].ctx, fake.cancelTasksWithContextArgsForCall[i].logger, fake.cancelTasksWithContextArgsForCall[i].taskGuids
+}
+
+func (fake *FakeContextClient) CancelTasksWithContextReturns(result1 []rep.BatchResult, result2 error) {
+	fake.CancelTasksWithContextStub = nil
+	fake.cancelTasksWithContextReturns = struct {
+		result1 []rep.BatchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) CancelTasksWithContextReturnsOnCall(i int, result1 []rep.BatchResult, result2 error) {
+	fake.CancelTasksWithContextStub = nil
+	if fake.cancelTasksWithContextReturnsOnCall == nil {
+		fake.cancelTasksWithContextReturnsOnCall = make(map[int]struct {
+			result1 []rep.BatchResult
+			result2 error
+		})
+	}
+	fake.cancelTasksWithContextReturnsOnCall[i] = struct {
+		result1 []rep.BatchResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) StateSince(ctx context.Context, logger lager.Logger, since rep.StateVersion, wait time.Duration) (rep.CellState, rep.StateVersion, bool, error) {
+	fake.stateSinceMutex.Lock()
+	ret, specificReturn := fake.stateSinceReturnsOnCall[len(fake.stateSinceArgsForCall)]
+	fake.stateSinceArgsForCall = append(fake.stateSinceArgsForCall, struct {
+		ctx    context.Context
+		logger lager.Logger
+		since  rep.StateVersion
+		wait   time.Duration
+	}{ctx, logger, since, wait})
+	fake.recordInvocation("StateSince", []interface{}{ctx, logger, since, wait})
+	fake.stateSinceMutex.Unlock()
+	if fake.StateSinceStub != nil {
+		return fake.StateSinceStub(ctx, logger, since, wait)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3, ret.result4
+	}
+	return fake.stateSinceReturns.result1, fake.stateSinceReturns.result2, fake.stateSinceReturns.result3, fake.stateSinceReturns.result4
+}
+
+func (fake *FakeContextClient) StateSinceCallCount() int {
+	fake.stateSinceMutex.RLock()
+	defer fake.stateSinceMutex.RUnlock()
+	return len(fake.stateSinceArgsForCall)
+}
+
+func (fake *FakeContextClient) StateSinceArgsForCall(i int) (context.Context, lager.Logger, rep.StateVersion, time.Duration) {
+	fake.stateSinceMutex.RLock()
+	defer fake.stateSinceMutex.RUnlock()
+	return fake.stateSinceArgsForCall[i].ctx, fake.stateSinceArgsForCall[i].logger, fake.stateSinceArgsForCall[i].since, fake.stateSinceArgsForCall[i].wait
+}
+
+func (fake *FakeContextClient) StateSinceReturns(result1 rep.CellState, result2 rep.StateVersion, result3 bool, result4 error) {
+	fake.StateSinceStub = nil
+	fake.stateSinceReturns = struct {
+		result1 rep.CellState
+		result2 rep.StateVersion
+		result3 bool
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *FakeContextClient) StateSinceReturnsOnCall(i int, result1 rep.CellState, result2 rep.StateVersion, result3 bool, result4 error) {
+	fake.StateSinceStub = nil
+	if fake.stateSinceReturnsOnCall == nil {
+		fake.stateSinceReturnsOnCall = make(map[int]struct {
+			result1 rep.CellState
+			result2 rep.StateVersion
+			result3 bool
+			result4 error
+		})
+	}
+	fake.stateSinceReturnsOnCall[i] = struct {
+		result1 rep.CellState
+		result2 rep.StateVersion
+		result3 bool
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *FakeContextClient) Containers(logger lager.Logger, filter rep.ContainerFilter) ([]rep.ContainerInfo, error) {
+	fake.containersMutex.Lock()
+	ret, specificReturn := fake.containersReturnsOnCall[len(fake.containersArgsForCall)]
+	fake.containersArgsForCall = append(fake.containersArgsForCall, struct {
+		logger lager.Logger
+		filter rep.ContainerFilter
+	}{logger, filter})
+	fake.recordInvocation("Containers", []interface{}{logger, filter})
+	fake.containersMutex.Unlock()
+	if fake.ContainersStub != nil {
+		return fake.ContainersStub(logger, filter)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.containersReturns.result1, fake.containersReturns.result2
+}
+
+func (fake *FakeContextClient) ContainersCallCount() int {
+	fake.containersMutex.RLock()
+	defer fake.containersMutex.RUnlock()
+	return len(fake.containersArgsForCall)
+}
+
+func (fake *FakeContextClient) ContainersArgsForCall(i int) (lager.Logger, rep.ContainerFilter) {
+	fake.containersMutex.RLock()
+	defer fake.containersMutex.RUnlock()
+	return fake.containersArgsForCall[i].logger, fake.containersArgsForCall[i].filter
+}
+
+func (fake *FakeContextClient) ContainersReturns(result1 []rep.ContainerInfo, result2 error) {
+	fake.ContainersStub = nil
+	fake.containersReturns = struct {
+		result1 []rep.ContainerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) ContainersReturnsOnCall(i int, result1 []rep.ContainerInfo, result2 error) {
+	fake.ContainersStub = nil
+	if fake.containersReturnsOnCall == nil {
+		fake.containersReturnsOnCall = make(map[int]struct {
+			result1 []rep.ContainerInfo
+			result2 error
+		})
+	}
+	fake.containersReturnsOnCall[i] = struct {
+		result1 []rep.ContainerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) ContainersWithContext(ctx context.Context, logger lager.Logger, filter rep.ContainerFilter) ([]rep.ContainerInfo, error) {
+	fake.containersWithContextMutex.Lock()
+	ret, specificReturn := fake.containersWithContextReturnsOnCall[len(fake.containersWithContextArgsForCall)]
+	fake.containersWithContextArgsForCall = append(fake.containersWithContextArgsForCall, struct {
+		ctx    context.Context
+		logger lager.Logger
+		filter rep.ContainerFilter
+	}{ctx, logger, filter})
+	fake.recordInvocation("ContainersWithContext", []interface{}{ctx, logger, filter})
+	fake.containersWithContextMutex.Unlock()
+	if fake.ContainersWithContextStub != nil {
+		return fake.ContainersWithContextStub(ctx, logger, filter)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.containersWithContextReturns.result1, fake.containersWithContextReturns.result2
+}
+
+func (fake *FakeContextClient) ContainersWithContextCallCount() int {
+	fake.containersWithContextMutex.RLock()
+	defer fake.containersWithContextMutex.RUnlock()
+	return len(fake.containersWithContextArgsForCall)
+}
+
+func (fake *FakeContextClient) ContainersWithContextArgsForCall(i int) (context.Context, lager.Logger, rep.ContainerFilter) {
+	fake.containersWithContextMutex.RLock()
+	defer fake.containersWithContextMutex.RUnlock()
+	return fake.containersWithContextArgsForCall[i].ctx, fake.containersWithContextArgsForCall[i].logger, fake.containersWithContextArgsForCall[i].filter
+}
+
+func (fake *FakeContextClient) ContainersWithContextReturns(result1 []rep.ContainerInfo, result2 error) {
+	fake.ContainersWithContextStub = nil
+	fake.containersWithContextReturns = struct {
+		result1 []rep.ContainerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) ContainersWithContextReturnsOnCall(i int, result1 []rep.ContainerInfo, result2 error) {
+	fake.ContainersWithContextStub = nil
+	if fake.containersWithContextReturnsOnCall == nil {
+		fake.containersWithContextReturnsOnCall = make(map[int]struct {
+			result1 []rep.ContainerInfo
+			result2 error
+		})
+	}
+	fake.containersWithContextReturnsOnCall[i] = struct {
+		result1 []rep.ContainerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) Container(logger lager.Logger, guid string) (rep.ContainerInfo, error) {
+	fake.containerMutex.Lock()
+	ret, specificReturn := fake.containerReturnsOnCall[len(fake.containerArgsForCall)]
+	fake.containerArgsForCall = append(fake.containerArgsForCall, struct {
+		logger lager.Logger
+		guid   string
+	}{logger, guid})
+	fake.recordInvocation("Container", []interface{}{logger, guid})
+	fake.containerMutex.Unlock()
+	if fake.ContainerStub != nil {
+		return fake.ContainerStub(logger, guid)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.containerReturns.result1, fake.containerReturns.result2
+}
+
+func (fake *FakeContextClient) ContainerCallCount() int {
+	fake.containerMutex.RLock()
+	defer fake.containerMutex.RUnlock()
+	return len(fake.containerArgsForCall)
+}
+
+func (fake *FakeContextClient) ContainerArgsForCall(i int) (lager.Logger, string) {
+	fake.containerMutex.RLock()
+	defer fake.containerMutex.RUnlock()
+	return fake.containerArgsForCall[i].logger, fake.containerArgsForCall[i].guid
+}
+
+func (fake *FakeContextClient) ContainerReturns(result1 rep.ContainerInfo, result2 error) {
+	fake.ContainerStub = nil
+	fake.containerReturns = struct {
+		result1 rep.ContainerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) ContainerReturnsOnCall(i int, result1 rep.ContainerInfo, result2 error) {
+	fake.ContainerStub = nil
+	if fake.containerReturnsOnCall == nil {
+		fake.containerReturnsOnCall = make(map[int]struct {
+			result1 rep.ContainerInfo
+			result2 error
+		})
+	}
+	fake.containerReturnsOnCall[i] = struct {
+		result1 rep.ContainerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) ContainerWithContext(ctx context.Context, logger lager.Logger, guid string) (rep.ContainerInfo, error) {
+	fake.containerWithContextMutex.Lock()
+	ret, specificReturn := fake.containerWithContextReturnsOnCall[len(fake.containerWithContextArgsForCall)]
+	fake.containerWithContextArgsForCall = append(fake.containerWithContextArgsForCall, struct {
+		ctx    context.Context
+		logger lager.Logger
+		guid   string
+	}{ctx, logger, guid})
+	fake.recordInvocation("ContainerWithContext", []interface{}{ctx, logger, guid})
+	fake.containerWithContextMutex.Unlock()
+	if fake.ContainerWithContextStub != nil {
+		return fake.ContainerWithContextStub(ctx, logger, guid)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.containerWithContextReturns.result1, fake.containerWithContextReturns.result2
+}
+
+func (fake *FakeContextClient) ContainerWithContextCallCount() int {
+	fake.containerWithContextMutex.RLock()
+	defer fake.containerWithContextMutex.RUnlock()
+	return len(fake.containerWithContextArgsForCall)
+}
+
+func (fake *FakeContextClient) ContainerWithContextArgsForCall(i int) (context.Context, lager.Logger, string) {
+	fake.containerWithContextMutex.RLock()
+	defer fake.containerWithContextMutex.RUnlock()
+	return fake.containerWithContextArgsForCall[i].ctx, fake.containerWithContextArgsForCall[i].logger, fake.containerWithContextArgsForCall[i].guid
+}
+
+func (fake *FakeContextClient) ContainerWithContextReturns(result1 rep.ContainerInfo, result2 error) {
+	fake.ContainerWithContextStub = nil
+	fake.containerWithContextReturns = struct {
+		result1 rep.ContainerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) ContainerWithContextReturnsOnCall(i int, result1 rep.ContainerInfo, result2 error) {
+	fake.ContainerWithContextStub = nil
+	if fake.containerWithContextReturnsOnCall == nil {
+		fake.containerWithContextReturnsOnCall = make(map[int]struct {
+			result1 rep.ContainerInfo
+			result2 error
+		})
+	}
+	fake.containerWithContextReturnsOnCall[i] = struct {
+		result1 rep.ContainerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.stateMutex.RLock()
+	defer fake.stateMutex.RUnlock()
+	fake.performMutex.RLock()
+	defer fake.performMutex.RUnlock()
+	fake.stopLRPInstanceMutex.RLock()
+	defer fake.stopLRPInstanceMutex.RUnlock()
+	fake.cancelTaskMutex.RLock()
+	defer fake.cancelTaskMutex.RUnlock()
+	fake.setStateClientMutex.RLock()
+	defer fake.setStateClientMutex.RUnlock()
+	fake.stateClientTimeoutMutex.RLock()
+	defer fake.stateClientTimeoutMutex.RUnlock()
+	fake.stateWithContextMutex.RLock()
+	defer fake.stateWithContextMutex.RUnlock()
+	fake.performWithContextMutex.RLock()
+	defer fake.performWithContextMutex.RUnlock()
+	fake.stopLRPInstanceWithContextMutex.RLock()
+	defer fake.stopLRPInstanceWithContextMutex.RUnlock()
+	fake.cancelTaskWithContextMutex.RLock()
+	defer fake.cancelTaskWithContextMutex.RUnlock()
+	fake.stopLRPInstancesMutex.RLock()
+	defer fake.stopLRPInstancesMutex.RUnlock()
+	fake.stopLRPInstancesWithContextMutex.RLock()
+	defer fake.stopLRPInstancesWithContextMutex.RUnlock()
+	fake.cancelTasksMutex.RLock()
+	defer fake.cancelTasksMutex.RUnlock()
+	fake.cancelTasksWithContextMutex.RLock()
+	defer fake.cancelTasksWithContextMutex.RUnlock()
+	fake.stateSinceMutex.RLock()
+	defer fake.stateSinceMutex.RUnlock()
+	fake.containersMutex.RLock()
+	defer fake.containersMutex.RUnlock()
+	fake.containersWithContextMutex.RLock()
+	defer fake.containersWithContextMutex.RUnlock()
+	fake.containerMutex.RLock()
+	defer fake.containerMutex.RUnlock()
+	fake.containerWithContextMutex.RLock()
+	defer fake.containerWithContextMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeContextClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ rep.ContextClient = new(FakeContextClient)
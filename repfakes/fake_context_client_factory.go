@@ -0,0 +1,169 @@
+// This file was generated by counterfeiter
+package repfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/rep"
+)
+
+type FakeContextClientFactory struct {
+	CreateClientStub        func(address, url string) (rep.Client, error)
+	createClientMutex       sync.RWMutex
+	createClientArgsForCall []struct {
+		address string
+		url     string
+	}
+	createClientReturns struct {
+		result1 rep.Client
+		result2 error
+	}
+	createClientReturnsOnCall map[int]struct {
+		result1 rep.Client
+		result2 error
+	}
+	CreateContextClientStub        func(address string, url string) (rep.ContextClient, error)
+	createContextClientMutex       sync.RWMutex
+	createContextClientArgsForCall []struct {
+		address string
+		url     string
+	}
+	createContextClientReturns struct {
+		result1 rep.ContextClient
+		result2 error
+	}
+	createContextClientReturnsOnCall map[int]struct {
+		result1 rep.ContextClient
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeContextClientFactory) CreateClient(address string, url string) (rep.Client, error) {
+	fake.createClientMutex.Lock()
+	ret, specificReturn := fake.createClientReturnsOnCall[len(fake.createClientArgsForCall)]
+	fake.createClientArgsForCall = append(fake.createClientArgsForCall, struct {
+		address string
+		url     string
+	}{address, url})
+	fake.recordInvocation("CreateClient", []interface{}{address, url})
+	fake.createClientMutex.Unlock()
+	if fake.CreateClientStub != nil {
+		return fake.CreateClientStub(address, url)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createClientReturns.result1, fake.createClientReturns.result2
+}
+
+func (fake *FakeContextClientFactory) CreateClientCallCount() int {
+	fake.createClientMutex.RLock()
+	defer fake.createClientMutex.RUnlock()
+	return len(fake.createClientArgsForCall)
+}
+
+func (fake *FakeContextClientFactory) CreateClientArgsForCall(i int) (string, string) {
+	fake.createClientMutex.RLock()
+	defer fake.createClientMutex.RUnlock()
+	return fake.createClientArgsForCall[i].address, fake.createClientArgsForCall[i].url
+}
+
+func (fake *FakeContextClientFactory) CreateClientReturns(result1 rep.Client, result2 error) {
+	fake.CreateClientStub = nil
+	fake.createClientReturns = struct {
+		result1 rep.Client
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClientFactory) CreateClientReturnsOnCall(i int, result1 rep.Client, result2 error) {
+	fake.CreateClientStub = nil
+	if fake.createClientReturnsOnCall == nil {
+		fake.createClientReturnsOnCall = make(map[int]struct {
+			result1 rep.Client
+			result2 error
+		})
+	}
+	fake.createClientReturnsOnCall[i] = struct {
+		result1 rep.Client
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClientFactory) CreateContextClient(address string, url string) (rep.ContextClient, error) {
+	fake.createContextClientMutex.Lock()
+	ret, specificReturn := fake.createContextClientReturnsOnCall[len(fake.createContextClientArgsForCall)]
+	fake.createContextClientArgsForCall = append(fake.createContextClientArgsForCall, struct {
+		address string
+		url     string
+	}{address, url})
+	fake.recordInvocation("CreateContextClient", []interface{}{address, url})
+	fake.createContextClientMutex.Unlock()
+	if fake.CreateContextClientStub != nil {
+		return fake.CreateContextClientStub(address, url)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createContextClientReturns.result1, fake.createContextClientReturns.result2
+}
+
+func (fake *FakeContextClientFactory) CreateContextClientCallCount() int {
+	fake.createContextClientMutex.RLock()
+	defer fake.createContextClientMutex.RUnlock()
+	return len(fake.createContextClientArgsForCall)
+}
+
+func (fake *FakeContextClientFactory) CreateContextClientArgsForCall(i int) (string, string) {
+	fake.createContextClientMutex.RLock()
+	defer fake.createContextClientMutex.RUnlock()
+	return fake.createContextClientArgsForCall[i].address, fake.createContextClientArgsForCall[i].url
+}
+
+func (fake *FakeContextClientFactory) CreateContextClientReturns(result1 rep.ContextClient, result2 error) {
+	fake.CreateContextClientStub = nil
+	fake.createContextClientReturns = struct {
+		result1 rep.ContextClient
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClientFactory) CreateContextClientReturnsOnCall(i int, result1 rep.ContextClient, result2 error) {
+	fake.CreateContextClientStub = nil
+	if fake.createContextClientReturnsOnCall == nil {
+		fake.createContextClientReturnsOnCall = make(map[int]struct {
+			result1 rep.ContextClient
+			result2 error
+		})
+	}
+	fake.createContextClientReturnsOnCall[i] = struct {
+		result1 rep.ContextClient
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeContextClientFactory) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createClientMutex.RLock()
+	defer fake.createClientMutex.RUnlock()
+	fake.createContextClientMutex.RLock()
+	defer fake.createContextClientMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeContextClientFactory) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ rep.ContextClientFactory = new(FakeContextClientFactory)
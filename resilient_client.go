@@ -0,0 +1,507 @@
+package rep
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+var (
+	ErrCircuitOpen           = errors.New("circuit breaker open")
+	ErrContextClientRequired = errors.New("the wrapped client does not implement rep.ContextClient")
+)
+
+// ResilienceConfig configures the retries and circuit breakers of a
+// ResilientClientFactory. Every call but Perform is retried up to MaxRetries
+// times with a jittered exponential backoff. Perform is never retried, since
+// the cell may have started some of the work. A breaker opens
+// after BreakerThreshold consecutive failures and lets a single trial call
+// through once BreakerCooldown has passed. A zero BreakerThreshold disables
+// the breakers.
+type ResilienceConfig struct {
+	MaxRetries       int
+	InitialBackoff   time.Duration
+	MaxBackoff       time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// backoff returns the delay before the given retry, picked at random between
+// half and all of the exponential backoff.
+func (config ResilienceConfig) backoff(retry int) time.Duration {
+	delay := config.InitialBackoff
+	for i := 0; i < retry && (config.MaxBackoff == 0 || delay < config.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if config.MaxBackoff > 0 && delay > config.MaxBackoff {
+		delay = config.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     clock.Clock
+
+	lock     sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.clock.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		return true
+	case BreakerHalfOpen:
+		return false
+	}
+	return true
+}
+
+func (b *circuitBreaker) record(err error) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err == nil {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.clock.Now()
+	}
+}
+
+// abandon gives the trial call back when its caller gave up before the cell
+// answered, so that the next call is let through as the trial instead.
+func (b *circuitBreaker) abandon() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.state = BreakerOpen
+	}
+}
+
+func (b *circuitBreaker) State() BreakerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.state
+}
+
+// ResilientClientFactory wraps the clients of another factory with retries
+// and a circuit breaker per cell address. Clients created for the same
+// address share a breaker. It is a ContextClientFactory; when the wrapped
+// client only implements Client, contexts are not passed on and the calls it
+// lacks fail with ErrContextClientRequired.
+//
+// Only transport errors and server errors count against a cell and are
+// retried. A cell that refuses a request with a 4xx status has answered it,
+// and a call whose caller gave up says nothing about the cell.
+//
+// Like NewClientFactory, it is meant for the components that call cells, such
+// as the auctioneer; the rep itself creates no cell clients. They should
+// Prune the breakers of cells that leave the registry.
+type ResilientClientFactory struct {
+	factory ClientFactory
+	config  ResilienceConfig
+	clock   clock.Clock
+
+	lock     sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func NewResilientClientFactory(factory ClientFactory, config ResilienceConfig, clock clock.Clock) *ResilientClientFactory {
+	return &ResilientClientFactory{
+		factory:  factory,
+		config:   config,
+		clock:    clock,
+		breakers: map[string]*circuitBreaker{},
+	}
+}
+
+func (factory *ResilientClientFactory) CreateClient(address, url string) (Client, error) {
+	return factory.CreateContextClient(address, url)
+}
+
+func (factory *ResilientClientFactory) CreateContextClient(address, url string) (ContextClient, error) {
+	contextClient, adapted, err := factory.createWrappedClient(address, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resilientClient{
+		ContextClient: contextClient,
+		adapted:       adapted,
+		config:        factory.config,
+		clock:         factory.clock,
+		breaker:       factory.breaker(address),
+	}, nil
+}
+
+// createWrappedClient creates a client with the wrapped factory, and adapts
+// it if it is not a ContextClient.
+func (factory *ResilientClientFactory) createWrappedClient(address, url string) (ContextClient, bool, error) {
+	if contextFactory, ok := factory.factory.(ContextClientFactory); ok {
+		client, err := contextFactory.CreateContextClient(address, url)
+		return client, false, err
+	}
+
+	client, err := factory.factory.CreateClient(address, url)
+	if err != nil {
+		return nil, false, err
+	}
+	if contextClient, ok := client.(ContextClient); ok {
+		return contextClient, false, nil
+	}
+	return contextAdapter{client}, true, nil
+}
+
+func (factory *ResilientClientFactory) breaker(address string) *circuitBreaker {
+	factory.lock.Lock()
+	defer factory.lock.Unlock()
+
+	breaker, ok := factory.breakers[address]
+	if !ok {
+		breaker = &circuitBreaker{
+			threshold: factory.config.BreakerThreshold,
+			cooldown:  factory.config.BreakerCooldown,
+			clock:     factory.clock,
+			state:     BreakerClosed,
+		}
+		factory.breakers[address] = breaker
+	}
+	return breaker
+}
+
+// Prune forgets the breakers of every address but the given ones, so that
+// cells that have left the registry do not accumulate.
+func (factory *ResilientClientFactory) Prune(addresses []string) {
+	keep := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		keep[address] = struct{}{}
+	}
+
+	factory.lock.Lock()
+	defer factory.lock.Unlock()
+
+	for address := range factory.breakers {
+		if _, ok := keep[address]; !ok {
+			delete(factory.breakers, address)
+		}
+	}
+}
+
+// BreakerStates returns the state of the breaker of every address a client
+// has been created for.
+func (factory *ResilientClientFactory) BreakerStates() map[string]BreakerState {
+	factory.lock.Lock()
+	defer factory.lock.Unlock()
+
+	states := make(map[string]BreakerState, len(factory.breakers))
+	for address, breaker := range factory.breakers {
+		states[address] = breaker.State()
+	}
+	return states
+}
+
+type resilientClient struct {
+	ContextClient
+	adapted bool
+	config  ResilienceConfig
+	clock   clock.Clock
+	breaker *circuitBreaker
+}
+
+func (c *resilientClient) State(logger lager.Logger) (CellState, error) {
+	return c.StateWithContext(context.Background(), logger)
+}
+
+func (c *resilientClient) StateWithContext(ctx context.Context, logger lager.Logger) (CellState, error) {
+	var state CellState
+	err := c.retry(ctx, logger, func() error {
+		var err error
+		state, err = c.ContextClient.StateWithContext(ctx, logger)
+		return err
+	})
+	return state, err
+}
+
+func (c *resilientClient) StateSince(ctx context.Context, logger lager.Logger, since StateVersion, wait time.Duration) (CellState, StateVersion, bool, error) {
+	var state CellState
+	var version StateVersion
+	var changed bool
+	err := c.retry(ctx, logger, func() error {
+		var err error
+		state, version, changed, err = c.ContextClient.StateSince(ctx, logger, since, wait)
+		return err
+	})
+	return state, version, changed, err
+}
+
+func (c *resilientClient) Perform(logger lager.Logger, work Work) (Work, error) {
+	return c.PerformWithContext(context.Background(), logger, work)
+}
+
+func (c *resilientClient) PerformWithContext(ctx context.Context, logger lager.Logger, work Work) (Work, error) {
+	if !c.breaker.allow() {
+		return Work{}, ErrCircuitOpen
+	}
+
+	failedWork, err := c.ContextClient.PerformWithContext(ctx, logger, work)
+	c.record(ctx, err)
+	return failedWork, err
+}
+
+func (c *resilientClient) StopLRPInstance(logger lager.Logger, key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) error {
+	return c.StopLRPInstanceWithContext(context.Background(), logger, key, instanceKey)
+}
+
+func (c *resilientClient) StopLRPInstanceWithContext(ctx context.Context, logger lager.Logger, key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) error {
+	return c.retry(ctx, logger, func() error {
+		return c.ContextClient.StopLRPInstanceWithContext(ctx, logger, key, instanceKey)
+	})
+}
+
+func (c *resilientClient) CancelTask(logger lager.Logger, taskGuid string) error {
+	return c.CancelTaskWithContext(context.Background(), logger, taskGuid)
+}
+
+func (c *resilientClient) CancelTaskWithContext(ctx context.Context, logger lager.Logger, taskGuid string) error {
+	return c.retry(ctx, logger, func() error {
+		return c.ContextClient.CancelTaskWithContext(ctx, logger, taskGuid)
+	})
+}
+
+func (c *resilientClient) StopLRPInstances(logger lager.Logger, instances []LRPInstanceKeys) ([]BatchResult, error) {
+	return c.StopLRPInstancesWithContext(context.Background(), logger, instances)
+}
+
+func (c *resilientClient) StopLRPInstancesWithContext(ctx context.Context, logger lager.Logger, instances []LRPInstanceKeys) ([]BatchResult, error) {
+	if c.adapted {
+		return nil, ErrContextClientRequired
+	}
+
+	var results []BatchResult
+	err := c.retry(ctx, logger, func() error {
+		var err error
+		results, err = c.ContextClient.StopLRPInstancesWithContext(ctx, logger, instances)
+		return err
+	})
+	return results, err
+}
+
+func (c *resilientClient) CancelTasks(logger lager.Logger, taskGuids []string) ([]BatchResult, error) {
+	return c.CancelTasksWithContext(context.Background(), logger, taskGuids)
+}
+
+func (c *resilientClient) CancelTasksWithContext(ctx context.Context, logger lager.Logger, taskGuids []string) ([]BatchResult, error) {
+	if c.adapted {
+		return nil, ErrContextClientRequired
+	}
+
+	var results []BatchResult
+	err := c.retry(ctx, logger, func() error {
+		var err error
+		results, err = c.ContextClient.CancelTasksWithContext(ctx, logger, taskGuids)
+		return err
+	})
+	return results, err
+}
+
+func (c *resilientClient) Containers(logger lager.Logger, filter ContainerFilter) ([]ContainerInfo, error) {
+	return c.ContainersWithContext(context.Background(), logger, filter)
+}
+
+func (c *resilientClient) ContainersWithContext(ctx context.Context, logger lager.Logger, filter ContainerFilter) ([]ContainerInfo, error) {
+	if c.adapted {
+		return nil, ErrContextClientRequired
+	}
+
+	var infos []ContainerInfo
+	err := c.retry(ctx, logger, func() error {
+		var err error
+		infos, err = c.ContextClient.ContainersWithContext(ctx, logger, filter)
+		return err
+	})
+	return infos, err
+}
+
+func (c *resilientClient) Container(logger lager.Logger, guid string) (ContainerInfo, error) {
+	return c.ContainerWithContext(context.Background(), logger, guid)
+}
+
+func (c *resilientClient) ContainerWithContext(ctx context.Context, logger lager.Logger, guid string) (ContainerInfo, error) {
+	if c.adapted {
+		return ContainerInfo{}, ErrContextClientRequired
+	}
+
+	var info ContainerInfo
+	err := c.retry(ctx, logger, func() error {
+		var err error
+		info, err = c.ContextClient.ContainerWithContext(ctx, logger, guid)
+		return err
+	})
+	return info, err
+}
+
+func (c *resilientClient) retry(ctx context.Context, logger lager.Logger, call func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if !c.breaker.allow() {
+			if err == nil {
+				err = ErrCircuitOpen
+			}
+			return err
+		}
+
+		err = call()
+		if !c.record(ctx, err) || attempt >= c.config.MaxRetries {
+			return err
+		}
+
+		delay := c.config.backoff(attempt)
+		logger.Info("retrying", lager.Data{"attempt": attempt + 1, "delay": delay.String(), "error": err.Error()})
+		if delay == 0 {
+			continue
+		}
+
+		timer := c.clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// record tells the breaker how a call went, and reports whether the call is
+// worth retrying.
+func (c *resilientClient) record(ctx context.Context, err error) bool {
+	switch {
+	case err == nil || refusedByCell(err):
+		c.breaker.record(nil)
+		return false
+	case ctx.Err() != nil:
+		c.breaker.abandon()
+		return false
+	}
+
+	c.breaker.record(err)
+	return true
+}
+
+// refusedByCell reports whether the cell answered the call with an error that
+// a retry would not change.
+func refusedByCell(err error) bool {
+	if err == ErrContainerNotFound {
+		return true
+	}
+	statusErr, ok := err.(*StatusCodeError)
+	return ok && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500
+}
+
+// contextAdapter makes a ContextClient of a Client that is not one, so that
+// the resilient client can wrap it. Contexts are dropped, and the calls the
+// Client has no equivalent for fail.
+type contextAdapter struct {
+	Client
+}
+
+func (a contextAdapter) StateWithContext(ctx context.Context, logger lager.Logger) (CellState, error) {
+	return a.State(logger)
+}
+
+func (a contextAdapter) StateSince(ctx context.Context, logger lager.Logger, since StateVersion, wait time.Duration) (CellState, StateVersion, bool, error) {
+	state, err := a.State(logger)
+	if err != nil {
+		return CellState{}, StateVersion{}, false, err
+	}
+	return state, StateVersion{}, true, nil
+}
+
+func (a contextAdapter) PerformWithContext(ctx context.Context, logger lager.Logger, work Work) (Work, error) {
+	return a.Perform(logger, work)
+}
+
+func (a contextAdapter) StopLRPInstanceWithContext(ctx context.Context, logger lager.Logger, key models.ActualLRPKey, instanceKey models.ActualLRPInstanceKey) error {
+	return a.StopLRPInstance(logger, key, instanceKey)
+}
+
+func (a contextAdapter) CancelTaskWithContext(ctx context.Context, logger lager.Logger, taskGuid string) error {
+	return a.CancelTask(logger, taskGuid)
+}
+
+func (a contextAdapter) StopLRPInstances(logger lager.Logger, instances []LRPInstanceKeys) ([]BatchResult, error) {
+	return nil, ErrContextClientRequired
+}
+
+func (a contextAdapter) StopLRPInstancesWithContext(ctx context.Context, logger lager.Logger, instances []LRPInstanceKeys) ([]BatchResult, error) {
+	return nil, ErrContextClientRequired
+}
+
+func (a contextAdapter) CancelTasks(logger lager.Logger, taskGuids []string) ([]BatchResult, error) {
+	return nil, ErrContextClientRequired
+}
+
+func (a contextAdapter) CancelTasksWithContext(ctx context.Context, logger lager.Logger, taskGuids []string) ([]BatchResult, error) {
+	return nil, ErrContextClientRequired
+}
+
+func (a contextAdapter) Containers(logger lager.Logger, filter ContainerFilter) ([]ContainerInfo, error) {
+	return nil, ErrContextClientRequired
+}
+
+func (a contextAdapter) ContainersWithContext(ctx context.Context, logger lager.Logger, filter ContainerFilter) ([]ContainerInfo, error) {
+	return nil, ErrContextClientRequired
+}
+
+func (a contextAdapter) Container(logger lager.Logger, guid string) (ContainerInfo, error) {
+	return ContainerInfo{}, ErrContextClientRequired
+}
+
+func (a contextAdapter) ContainerWithContext(ctx context.Context, logger lager.Logger, guid string) (ContainerInfo, error) {
+	return ContainerInfo{}, ErrContextClientRequired
+}
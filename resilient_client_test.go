@@ -0,0 +1,306 @@
+package rep_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/repfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResilientClientFactory", func() {
+	var (
+		logger      *lagertest.TestLogger
+		clock       *fakeclock.FakeClock
+		fakeFactory *repfakes.FakeClientFactory
+		fakeClient  *repfakes.FakeContextClient
+		config      rep.ResilienceConfig
+		factory     *rep.ResilientClientFactory
+		resilient   rep.ContextClient
+		boom        error
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		clock = fakeclock.NewFakeClock(time.Now())
+		fakeClient = &repfakes.FakeContextClient{}
+		fakeFactory = &repfakes.FakeClientFactory{}
+		fakeFactory.CreateClientReturns(fakeClient, nil)
+		config = rep.ResilienceConfig{MaxRetries: 2}
+		boom = errors.New("boom")
+	})
+
+	JustBeforeEach(func() {
+		factory = rep.NewResilientClientFactory(fakeFactory, config, clock)
+		var err error
+		resilient, err = factory.CreateContextClient("cell-address", "https://cell.example.com")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("creates clients with the wrapped factory", func() {
+		address, url := fakeFactory.CreateClientArgsForCall(0)
+		Expect(address).To(Equal("cell-address"))
+		Expect(url).To(Equal("https://cell.example.com"))
+	})
+
+	Context("when the wrapped factory creates context clients", func() {
+		var contextFactory *repfakes.FakeContextClientFactory
+
+		BeforeEach(func() {
+			contextFactory = &repfakes.FakeContextClientFactory{}
+			contextFactory.CreateContextClientReturns(fakeClient, nil)
+		})
+
+		It("creates the clients as context clients", func() {
+			factory = rep.NewResilientClientFactory(contextFactory, config, clock)
+			client, err := factory.CreateContextClient("cell-address", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contextFactory.CreateContextClientCallCount()).To(Equal(1))
+			Expect(contextFactory.CreateClientCallCount()).To(BeZero())
+
+			fakeClient.ContainersWithContextReturns([]rep.ContainerInfo{{Guid: "container-guid"}}, nil)
+			infos, err := client.Containers(logger, rep.ContainerFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(infos).To(HaveLen(1))
+		})
+	})
+
+	Context("when the wrapped client is not a context client", func() {
+		var plainClient *repfakes.FakeClient
+
+		BeforeEach(func() {
+			plainClient = &repfakes.FakeClient{}
+			fakeFactory.CreateClientReturns(plainClient, nil)
+		})
+
+		It("calls the methods the client has", func() {
+			plainClient.StateReturnsOnCall(0, rep.CellState{}, boom)
+			plainClient.StateReturnsOnCall(1, rep.CellState{Zone: "z1"}, nil)
+
+			state, err := resilient.StateWithContext(context.Background(), logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Zone).To(Equal("z1"))
+			Expect(plainClient.StateCallCount()).To(Equal(2))
+		})
+
+		Context("with a breaker", func() {
+			BeforeEach(func() {
+				config.BreakerThreshold = 1
+			})
+
+			It("fails the calls the client lacks without tripping it", func() {
+				_, err := resilient.CancelTasks(logger, []string{"task-guid"})
+				Expect(err).To(Equal(rep.ErrContextClientRequired))
+				_, err = resilient.Containers(logger, rep.ContainerFilter{})
+				Expect(err).To(Equal(rep.ErrContextClientRequired))
+				Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerClosed))
+			})
+		})
+	})
+
+	Describe("retries", func() {
+		It("retries idempotent calls until they succeed", func() {
+			fakeClient.StateWithContextReturnsOnCall(0, rep.CellState{}, boom)
+			fakeClient.StateWithContextReturnsOnCall(1, rep.CellState{Zone: "z1"}, nil)
+
+			state, err := resilient.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Zone).To(Equal("z1"))
+			Expect(fakeClient.StateWithContextCallCount()).To(Equal(2))
+		})
+
+		It("gives up after the configured number of retries", func() {
+			fakeClient.CancelTaskWithContextReturns(boom)
+			Expect(resilient.CancelTask(logger, "task-guid")).To(MatchError(boom))
+			Expect(fakeClient.CancelTaskWithContextCallCount()).To(Equal(3))
+
+			fakeClient.StopLRPInstanceWithContextReturns(boom)
+			err := resilient.StopLRPInstance(logger, models.ActualLRPKey{}, models.ActualLRPInstanceKey{})
+			Expect(err).To(MatchError(boom))
+			Expect(fakeClient.StopLRPInstanceWithContextCallCount()).To(Equal(3))
+		})
+
+		It("does not retry a missing container", func() {
+			fakeClient.ContainerWithContextReturns(rep.ContainerInfo{}, rep.ErrContainerNotFound)
+			_, err := resilient.Container(logger, "missing-guid")
+			Expect(err).To(Equal(rep.ErrContainerNotFound))
+			Expect(fakeClient.ContainerWithContextCallCount()).To(Equal(1))
+		})
+
+		It("does not retry a request the cell refuses", func() {
+			fakeClient.CancelTaskWithContextReturns(&rep.StatusCodeError{StatusCode: 400})
+			Expect(resilient.CancelTask(logger, "task-guid")).To(MatchError("http error: status code 400 (Bad Request)"))
+			Expect(fakeClient.CancelTaskWithContextCallCount()).To(Equal(1))
+		})
+
+		It("retries server errors", func() {
+			fakeClient.CancelTaskWithContextReturns(&rep.StatusCodeError{StatusCode: 503})
+			Expect(resilient.CancelTask(logger, "task-guid")).To(HaveOccurred())
+			Expect(fakeClient.CancelTaskWithContextCallCount()).To(Equal(3))
+		})
+
+		It("does not retry once the caller has given up", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			fakeClient.CancelTaskWithContextStub = func(context.Context, lager.Logger, string) error {
+				cancel()
+				return boom
+			}
+			Expect(resilient.CancelTaskWithContext(ctx, logger, "task-guid")).To(MatchError(boom))
+			Expect(fakeClient.CancelTaskWithContextCallCount()).To(Equal(1))
+		})
+
+		It("never retries perform", func() {
+			fakeClient.PerformWithContextReturns(rep.Work{}, boom)
+			_, err := resilient.Perform(logger, rep.Work{})
+			Expect(err).To(MatchError(boom))
+			Expect(fakeClient.PerformWithContextCallCount()).To(Equal(1))
+		})
+
+		Context("with a backoff", func() {
+			BeforeEach(func() {
+				config.InitialBackoff = time.Second
+				config.MaxBackoff = 2 * time.Second
+			})
+
+			It("waits between attempts", func() {
+				fakeClient.CancelTaskWithContextReturns(boom)
+				errs := make(chan error)
+				go func() {
+					errs <- resilient.CancelTask(logger, "task-guid")
+				}()
+
+				Eventually(fakeClient.CancelTaskWithContextCallCount).Should(Equal(1))
+				clock.WaitForWatcherAndIncrement(time.Second)
+				Eventually(fakeClient.CancelTaskWithContextCallCount).Should(Equal(2))
+				clock.WaitForWatcherAndIncrement(2 * time.Second)
+				Eventually(errs).Should(Receive(MatchError(boom)))
+				Expect(fakeClient.CancelTaskWithContextCallCount()).To(Equal(3))
+			})
+
+			It("stops waiting when the context is done", func() {
+				fakeClient.CancelTaskWithContextReturns(boom)
+				ctx, cancel := context.WithCancel(context.Background())
+				errs := make(chan error)
+				go func() {
+					errs <- resilient.CancelTaskWithContext(ctx, logger, "task-guid")
+				}()
+
+				Eventually(clock.WatcherCount).Should(Equal(1))
+				cancel()
+				Eventually(errs).Should(Receive(MatchError(boom)))
+				Expect(fakeClient.CancelTaskWithContextCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("circuit breaker", func() {
+		BeforeEach(func() {
+			config = rep.ResilienceConfig{BreakerThreshold: 2, BreakerCooldown: 10 * time.Second}
+			fakeClient.StateWithContextReturns(rep.CellState{}, boom)
+		})
+
+		It("opens after consecutive failures and short-circuits calls", func() {
+			Expect(factory.BreakerStates()).To(Equal(map[string]rep.BreakerState{"cell-address": rep.BreakerClosed}))
+
+			resilient.State(logger)
+			resilient.State(logger)
+			Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerOpen))
+
+			_, err := resilient.State(logger)
+			Expect(err).To(MatchError(rep.ErrCircuitOpen))
+			_, err = resilient.Perform(logger, rep.Work{})
+			Expect(err).To(MatchError(rep.ErrCircuitOpen))
+			Expect(fakeClient.StateWithContextCallCount()).To(Equal(2))
+			Expect(fakeClient.PerformWithContextCallCount()).To(BeZero())
+		})
+
+		It("lets a trial call through after the cooldown and closes on success", func() {
+			resilient.State(logger)
+			resilient.State(logger)
+
+			clock.Increment(10 * time.Second)
+			fakeClient.StateWithContextReturns(rep.CellState{}, nil)
+			_, err := resilient.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerClosed))
+		})
+
+		It("reopens when the trial call fails", func() {
+			resilient.State(logger)
+			resilient.State(logger)
+
+			clock.Increment(10 * time.Second)
+			fakeClient.StateWithContextStub = func(context.Context, lager.Logger) (rep.CellState, error) {
+				defer GinkgoRecover()
+				Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerHalfOpen))
+				return rep.CellState{}, boom
+			}
+			_, err := resilient.State(logger)
+			Expect(err).To(MatchError(boom))
+			Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerOpen))
+		})
+
+		It("does not count refused requests against the cell", func() {
+			fakeClient.StateWithContextReturns(rep.CellState{}, &rep.StatusCodeError{StatusCode: 400})
+			resilient.State(logger)
+			resilient.State(logger)
+			Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerClosed))
+		})
+
+		It("does not count calls whose caller gave up against the cell", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			fakeClient.PerformWithContextReturns(rep.Work{}, context.Canceled)
+			resilient.PerformWithContext(ctx, logger, rep.Work{})
+			resilient.PerformWithContext(ctx, logger, rep.Work{})
+			Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerClosed))
+		})
+
+		It("lets the next call through as the trial when the caller of the trial gives up", func() {
+			resilient.State(logger)
+			resilient.State(logger)
+
+			clock.Increment(10 * time.Second)
+			ctx, cancel := context.WithCancel(context.Background())
+			fakeClient.StateWithContextStub = func(context.Context, lager.Logger) (rep.CellState, error) {
+				cancel()
+				return rep.CellState{}, context.Canceled
+			}
+			_, err := resilient.StateWithContext(ctx, logger)
+			Expect(err).To(Equal(context.Canceled))
+			Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerOpen))
+
+			fakeClient.StateWithContextStub = nil
+			fakeClient.StateWithContextReturns(rep.CellState{}, nil)
+			_, err = resilient.State(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(factory.BreakerStates()["cell-address"]).To(Equal(rep.BreakerClosed))
+		})
+
+		It("shares the breaker between clients for the same address", func() {
+			resilient.State(logger)
+			resilient.State(logger)
+
+			other, err := factory.CreateClient("cell-address", "")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = other.State(logger)
+			Expect(err).To(MatchError(rep.ErrCircuitOpen))
+		})
+
+		It("forgets the breakers of pruned addresses", func() {
+			_, err := factory.CreateClient("other-address", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			factory.Prune([]string{"other-address"})
+			Expect(factory.BreakerStates()).To(Equal(map[string]rep.BreakerState{"other-address": rep.BreakerClosed}))
+		})
+	})
+})
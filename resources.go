@@ -13,6 +13,10 @@ import (
 
 var ErrorIncompatibleRootfs = errors.New("rootfs not found")
 
+// CellCordonedReason is the failure reason given for work sent to a cordoned
+// cell.
+const CellCordonedReason = "cell is cordoned"
+
 type CellState struct {
 	RootFSProviders        RootFSProviders
 	AvailableResources     Resources
@@ -25,6 +29,27 @@ type CellState struct {
 	VolumeDrivers          []string
 	PlacementTags          []string
 	OptionalPlacementTags  []string
+	Labels                 map[string]string `json:",omitempty"`
+	Topology               []string          `json:",omitempty"`
+
+	// Cordoned cells take no new work, but unlike evacuating cells they keep
+	// running their containers.
+	Cordoned bool `json:",omitempty"`
+
+	// ResourceCeiling is the physical limit on a single container when the
+	// cell overcommits memory or disk. Zero values are not enforced.
+	ResourceCeiling *Resources `json:",omitempty"`
+
+	// Usage is only reported by cells configured to measure it.
+	Usage *Usage `json:",omitempty"`
+}
+
+// Usage is the measured usage of the containers on a cell, as opposed to
+// their reservations. CPU is the fraction of the cell's cpu in use.
+type Usage struct {
+	MemoryMB int32
+	DiskMB   int32
+	CPU      float64
 }
 
 func NewCellState(
@@ -55,6 +80,11 @@ func NewCellState(
 	}
 }
 
+// Schedulable reports whether the cell accepts new work.
+func (c *CellState) Schedulable() bool {
+	return !c.Evacuating && !c.Cordoned
+}
+
 func (c *CellState) AddLRP(lrp *LRP) {
 	c.AvailableResources.Subtract(&lrp.Resource)
 	c.StartingContainerCount += 1
@@ -68,21 +98,15 @@ func (c *CellState) AddTask(task *Task) {
 }
 
 func (c *CellState) ResourceMatch(res *Resource) error {
-	problems := map[string]struct{}{}
-
-	if c.AvailableResources.DiskMB < res.DiskMB {
-		problems["disk"] = struct{}{}
-	}
-	if c.AvailableResources.MemoryMB < res.MemoryMB {
-		problems["memory"] = struct{}{}
-	}
-	if c.AvailableResources.Containers < 1 {
-		problems["containers"] = struct{}{}
-	}
-	if len(problems) == 0 {
+	shortfalls := c.resourceShortfalls(res)
+	if len(shortfalls) == 0 {
 		return nil
 	}
 
+	problems := map[string]struct{}{}
+	for _, shortfall := range shortfalls {
+		problems[shortfall.Resource] = struct{}{}
+	}
 	return InsufficientResourcesError{Problems: problems}
 }
 
@@ -110,6 +134,32 @@ func (c CellState) ComputeScore(res *Resource, startingContainerWeight float64)
 	return remainingResources.ComputeScore(&c.TotalResources) + startingContainerScore
 }
 
+// AntiAffinityWeights configures the penalty applied for each LRP already on
+// a cell that shares the process guid or domain of the LRP being placed.
+type AntiAffinityWeights struct {
+	ProcessGuid float64 `json:"process_guid"`
+	Domain      float64 `json:"domain"`
+}
+
+func (c *CellState) AntiAffinityScore(key *models.ActualLRPKey, weights AntiAffinityWeights) float64 {
+	if weights.ProcessGuid == 0 && weights.Domain == 0 {
+		return 0
+	}
+
+	var sameProcess, sameDomain int
+	for i := range c.LRPs {
+		lrp := &c.LRPs[i]
+		if lrp.ProcessGuid == key.ProcessGuid {
+			sameProcess++
+		}
+		if lrp.Domain == key.Domain {
+			sameDomain++
+		}
+	}
+
+	return float64(sameProcess)*weights.ProcessGuid + float64(sameDomain)*weights.Domain
+}
+
 func (c *CellState) MatchRootFS(rootfs string) bool {
 	rootFSURL, err := url.Parse(rootfs)
 	if err != nil {
@@ -147,6 +197,10 @@ func (c *CellState) MatchPlacementTags(desiredPlacementTags []string) bool {
 	return requiredTags.isSubset(desiredTags) && desiredTags.isSubset(allTags)
 }
 
+func (c *CellState) MatchLabelSelector(selector LabelSelector) bool {
+	return selector.Matches(c.Labels)
+}
+
 type placementTagSet map[string]struct{}
 
 func (set placementTagSet) union(other placementTagSet) placementTagSet {
@@ -177,37 +231,72 @@ func toSet(slice []string) placementTagSet {
 	return tags
 }
 
+// MaxPids and CPUWeight are only enforced when the cell reports a non-zero
+// total for them, and are omitted from the JSON when unset so that older
+// auctioneers keep seeing the same payload. Extended holds operator defined
+// countable resources, keyed by name.
 type Resources struct {
 	MemoryMB   int32
 	DiskMB     int32
 	Containers int
+	MaxPids    int32            `json:",omitempty"`
+	CPUWeight  int32            `json:",omitempty"`
+	Extended   map[string]int32 `json:",omitempty"`
 }
 
 func NewResources(memoryMb, diskMb int32, containerCount int) Resources {
-	return Resources{memoryMb, diskMb, containerCount}
+	return Resources{MemoryMB: memoryMb, DiskMB: diskMb, Containers: containerCount}
 }
 
 func (r *Resources) Copy() Resources {
-	return *r
+	resources := *r
+	resources.Extended = copyExtended(r.Extended)
+	return resources
 }
 
 func (r *Resources) Subtract(res *Resource) {
 	r.MemoryMB -= res.MemoryMB
 	r.DiskMB -= res.DiskMB
+	r.MaxPids -= res.MaxPids
+	r.CPUWeight -= res.CPUWeight
 	r.Containers -= 1
+	for name, amount := range res.Extended {
+		if _, ok := r.Extended[name]; ok {
+			r.Extended[name] -= amount
+		}
+	}
 }
 
 func (r *Resources) ComputeScore(total *Resources) float64 {
 	fractionUsedMemory := 1.0 - float64(r.MemoryMB)/float64(total.MemoryMB)
 	fractionUsedDisk := 1.0 - float64(r.DiskMB)/float64(total.DiskMB)
 	fractionUsedContainers := 1.0 - float64(r.Containers)/float64(total.Containers)
-	return (fractionUsedMemory + fractionUsedDisk + fractionUsedContainers) / 3.0
+
+	score := fractionUsedMemory + fractionUsedDisk + fractionUsedContainers
+	dimensions := 3.0
+	if total.MaxPids > 0 {
+		score += 1.0 - float64(r.MaxPids)/float64(total.MaxPids)
+		dimensions++
+	}
+	if total.CPUWeight > 0 {
+		score += 1.0 - float64(r.CPUWeight)/float64(total.CPUWeight)
+		dimensions++
+	}
+	for name, amount := range total.Extended {
+		if amount > 0 {
+			score += 1.0 - float64(r.Extended[name])/float64(amount)
+			dimensions++
+		}
+	}
+	return score / dimensions
 }
 
 type Resource struct {
-	MemoryMB int32
-	DiskMB   int32
-	MaxPids  int32
+	MemoryMB  int32
+	DiskMB    int32
+	MaxPids   int32
+	CPUWeight int32            `json:",omitempty"`
+	Extended  map[string]int32 `json:",omitempty"`
 }
 
 func NewResource(memoryMb, diskMb int32, maxPids int32) Resource {
@@ -215,17 +304,36 @@ func NewResource(memoryMb, diskMb int32, maxPids int32) Resource {
 }
 
 func (r *Resource) Valid() bool {
+	for _, amount := range r.Extended {
+		if amount < 0 {
+			return false
+		}
+	}
 	return r.DiskMB >= 0 && r.MemoryMB >= 0
 }
 
 func (r *Resource) Copy() Resource {
-	return NewResource(r.MemoryMB, r.DiskMB, r.MaxPids)
+	resource := *r
+	resource.Extended = copyExtended(r.Extended)
+	return resource
+}
+
+func copyExtended(extended map[string]int32) map[string]int32 {
+	if extended == nil {
+		return nil
+	}
+	copied := make(map[string]int32, len(extended))
+	for name, amount := range extended {
+		copied[name] = amount
+	}
+	return copied
 }
 
 type PlacementConstraint struct {
 	PlacementTags []string
 	VolumeDrivers []string
 	RootFs        string
+	LabelSelector LabelSelector `json:",omitempty"`
 }
 
 func NewPlacementConstraint(rootFs string, placementTags, volumeDrivers []string) PlacementConstraint {
@@ -276,6 +384,15 @@ func (task Task) Copy() Task {
 type Work struct {
 	LRPs  []LRP
 	Tasks []Task
+	// FailureReasons is keyed by the Identifier of a failed LRP or Task.
+	FailureReasons map[string]string `json:",omitempty"`
+}
+
+func (w *Work) AddFailureReason(identifier, reason string) {
+	if w.FailureReasons == nil {
+		w.FailureReasons = map[string]string{}
+	}
+	w.FailureReasons[identifier] = reason
 }
 
 type StackPathMap map[string]string
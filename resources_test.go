@@ -1,6 +1,8 @@
 package rep_test
 
 import (
+	"encoding/json"
+
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/rep"
 	. "github.com/onsi/ginkgo"
@@ -46,6 +48,38 @@ var _ = Describe("Resources", func() {
 		)
 	})
 
+	Describe("Schedulable", func() {
+		It("is true for a cell that is neither evacuating nor cordoned", func() {
+			Expect(cellState.Schedulable()).To(BeTrue())
+		})
+
+		It("is false for an evacuating cell", func() {
+			cellState.Evacuating = true
+			Expect(cellState.Schedulable()).To(BeFalse())
+		})
+
+		It("is false for a cordoned cell", func() {
+			cellState.Cordoned = true
+			Expect(cellState.Schedulable()).To(BeFalse())
+		})
+	})
+
+	Describe("MatchRootFS", func() {
+		It("matches preloaded stacks", func() {
+			Expect(cellState.MatchRootFS(linuxRootFSURL)).To(BeTrue())
+			Expect(cellState.MatchRootFS(models.PreloadedRootFS("windows"))).To(BeFalse())
+		})
+
+		It("enforces the patterns of a pattern provider", func() {
+			provider, err := rep.NewPatternRootFSProvider(rep.RootFSPatterns{Hosts: []string{"registry.internal"}}, rep.RootFSPatterns{})
+			Expect(err).NotTo(HaveOccurred())
+			cellState.RootFSProviders["docker"] = provider
+
+			Expect(cellState.MatchRootFS("docker://registry.internal/team/app")).To(BeTrue())
+			Expect(cellState.MatchRootFS("docker:///library/busybox")).To(BeFalse())
+		})
+	})
+
 	Describe("MatchPlacementTags", func() {
 		Context("when cell state does not have placement tags", func() {
 			It("does not allow lrps with placement tags", func() {
@@ -104,6 +138,39 @@ var _ = Describe("Resources", func() {
 		})
 	})
 
+	Describe("MatchLabelSelector", func() {
+		var state rep.CellState
+
+		BeforeEach(func() {
+			state = rep.CellState{
+				PlacementTags: []string{"foo"},
+				Labels:        map[string]string{"disk": "ssd", "rack": "r1"},
+			}
+		})
+
+		It("matches an empty selector", func() {
+			Expect(state.MatchLabelSelector(nil)).To(BeTrue())
+		})
+
+		It("requires every requirement to match", func() {
+			selector, err := rep.ParseLabelSelector("disk in (ssd, nvme), !gpu")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.MatchLabelSelector(selector)).To(BeTrue())
+
+			selector, err = rep.ParseLabelSelector("disk in (ssd, nvme), rack=r2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.MatchLabelSelector(selector)).To(BeFalse())
+		})
+
+		It("is independent of the placement tags", func() {
+			selector, err := rep.ParseLabelSelector("disk=ssd")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.MatchLabelSelector(selector)).To(BeTrue())
+			Expect(state.MatchPlacementTags([]string{})).To(BeFalse())
+			Expect(state.MatchPlacementTags([]string{"foo"})).To(BeTrue())
+		})
+	})
+
 	Describe("Resource Matching", func() {
 		var requiredResource rep.Resource
 		var err error
@@ -173,12 +240,180 @@ var _ = Describe("Resources", func() {
 			})
 		})
 
+		Context("when the cell does not track pids or cpu weight", func() {
+			BeforeEach(func() {
+				requiredResource.MaxPids = 5000
+				requiredResource.CPUWeight = 100
+			})
+
+			It("does not return an error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the cell tracks pids and cpu weight", func() {
+			BeforeEach(func() {
+				cellState.TotalResources.MaxPids = 1000
+				cellState.AvailableResources.MaxPids = 100
+				cellState.TotalResources.CPUWeight = 400
+				cellState.AvailableResources.CPUWeight = 50
+			})
+
+			Context("when insufficient pids", func() {
+				BeforeEach(func() {
+					requiredResource.MaxPids = 200
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("insufficient resources: pids"))
+				})
+			})
+
+			Context("when the work does not limit its pids", func() {
+				BeforeEach(func() {
+					requiredResource.MaxPids = 0
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("insufficient resources: pids"))
+				})
+			})
+
+			Context("when insufficient cpu weight", func() {
+				BeforeEach(func() {
+					requiredResource.CPUWeight = 60
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("insufficient resources: cpu"))
+				})
+			})
+
+			Context("when both fit", func() {
+				BeforeEach(func() {
+					requiredResource.MaxPids = 100
+					requiredResource.CPUWeight = 50
+				})
+
+				It("does not return an error", func() {
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when extended resources are requested", func() {
+			BeforeEach(func() {
+				cellState.TotalResources.Extended = map[string]int32{"ssd-slots": 2}
+				cellState.AvailableResources.Extended = map[string]int32{"ssd-slots": 1}
+			})
+
+			Context("when the cell has enough of them", func() {
+				BeforeEach(func() {
+					requiredResource.Extended = map[string]int32{"ssd-slots": 1}
+				})
+
+				It("does not return an error", func() {
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the cell does not have enough of them", func() {
+				BeforeEach(func() {
+					requiredResource.Extended = map[string]int32{"ssd-slots": 2}
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("insufficient resources: ssd-slots"))
+				})
+			})
+
+			Context("when the cell does not provide them", func() {
+				BeforeEach(func() {
+					requiredResource.Extended = map[string]int32{"licence-seats": 1}
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("insufficient resources: licence-seats"))
+				})
+			})
+		})
+
 		Context("when there is sufficient room", func() {
 			It("does not return an error", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 	})
+
+	Describe("AddLRP", func() {
+		It("subtracts the pids and cpu weight of the lrp from the available resources", func() {
+			cellState.AvailableResources.MaxPids = 100
+			cellState.AvailableResources.CPUWeight = 50
+
+			lrp := BuildLRP("pg-5", "domain", 0, linuxRootFSURL, 10, 20, 30)
+			lrp.CPUWeight = 10
+			cellState.AddLRP(lrp)
+
+			Expect(cellState.AvailableResources.MaxPids).To(BeEquivalentTo(70))
+			Expect(cellState.AvailableResources.CPUWeight).To(BeEquivalentTo(40))
+		})
+
+		It("subtracts the extended resources of the lrp without touching copies", func() {
+			cellState.AvailableResources.Extended = map[string]int32{"ssd-slots": 2}
+			original := cellState.AvailableResources.Copy()
+
+			lrp := BuildLRP("pg-5", "domain", 0, linuxRootFSURL, 10, 20, 30)
+			lrp.Extended = map[string]int32{"ssd-slots": 1, "untracked": 1}
+			cellState.AddLRP(lrp)
+
+			Expect(cellState.AvailableResources.Extended).To(Equal(map[string]int32{"ssd-slots": 1}))
+			Expect(original.Extended).To(Equal(map[string]int32{"ssd-slots": 2}))
+		})
+	})
+
+	Describe("AddTask", func() {
+		It("subtracts the extended resources of the task", func() {
+			cellState.AvailableResources.Extended = map[string]int32{"licence-seats": 4}
+
+			task := BuildTask("tg-5", "domain", linuxRootFSURL, 10, 20, 30, nil)
+			task.Extended = map[string]int32{"licence-seats": 3}
+			cellState.AddTask(task)
+
+			Expect(cellState.AvailableResources.Extended).To(Equal(map[string]int32{"licence-seats": 1}))
+		})
+	})
+
+	Describe("ComputeScore", func() {
+		It("takes tracked pids and cpu weight into account", func() {
+			res := rep.NewResource(10, 10, 10)
+			untracked := cellState.ComputeScore(&res, 0)
+
+			cellState.TotalResources.MaxPids = 1000
+			cellState.AvailableResources.MaxPids = 10
+			tracked := cellState.ComputeScore(&res, 0)
+
+			Expect(tracked).To(BeNumerically(">", untracked))
+		})
+
+		It("takes tracked extended resources into account", func() {
+			res := rep.NewResource(10, 10, 10)
+			untracked := cellState.ComputeScore(&res, 0)
+
+			cellState.TotalResources.Extended = map[string]int32{"ssd-slots": 4}
+			cellState.AvailableResources.Extended = map[string]int32{"ssd-slots": 0}
+			tracked := cellState.ComputeScore(&res, 0)
+
+			Expect(tracked).To(BeNumerically(">", untracked))
+		})
+	})
+
+	Describe("JSON", func() {
+		It("omits pids and cpu weight when they are not tracked", func() {
+			payload, err := json.Marshal(rep.NewResources(1000, 2000, 10))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload).To(MatchJSON(`{"MemoryMB": 1000, "DiskMB": 2000, "Containers": 10}`))
+		})
+	})
 })
 
 func BuildLRP(guid, domain string, index int, rootFS string, memoryMB, diskMB, maxPids int32) *rep.LRP {
@@ -2,7 +2,12 @@ package rep
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
 )
 
 type RootFSProvider interface {
@@ -15,6 +20,7 @@ type RootFSProviderType string
 const (
 	RootFSProviderTypeArbitrary RootFSProviderType = "arbitrary"
 	RootFSProviderTypeFixedSet  RootFSProviderType = "fixed_set"
+	RootFSProviderTypePattern   RootFSProviderType = "pattern"
 )
 
 type RootFSProviders map[string]RootFSProvider
@@ -29,13 +35,15 @@ func (p RootFSProviders) Copy() RootFSProviders {
 
 func (p RootFSProviders) Match(rootFS url.URL) bool {
 	provider, ok := p[rootFS.Scheme]
-	if !ok {
+	if !ok || provider == nil {
 		return false
 	}
 
 	return provider.Match(rootFS)
 }
 
+// UnmarshalJSON keeps providers of unknown types as UnsupportedRootFSProviders
+// so that cells can advertise provider types this version does not know.
 func (providers *RootFSProviders) UnmarshalJSON(payload []byte) error {
 	var providerEnvelope map[string]json.RawMessage
 	err := json.Unmarshal(payload, &providerEnvelope)
@@ -46,7 +54,10 @@ func (providers *RootFSProviders) UnmarshalJSON(payload []byte) error {
 	*providers = RootFSProviders{}
 
 	for key, value := range providerEnvelope {
-		provider, err := unmarshalRootFSProvider(value)
+		provider, err := UnmarshalRootFSProvider(value)
+		if unknown, ok := err.(UnknownRootFSProviderTypeError); ok {
+			provider, err = UnsupportedRootFSProvider{ProviderType: unknown.Type, Payload: value}, nil
+		}
 		if err != nil {
 			return err
 		}
@@ -56,28 +67,82 @@ func (providers *RootFSProviders) UnmarshalJSON(payload []byte) error {
 	return nil
 }
 
+type UnknownRootFSProviderTypeError struct {
+	Type RootFSProviderType
+}
+
+func (e UnknownRootFSProviderTypeError) Error() string {
+	return fmt.Sprintf("unknown rootfs provider type: %q", string(e.Type))
+}
+
+// A RootFSProviderUnmarshaler decodes the JSON of a provider, including its
+// "type" field. Providers encode themselves by implementing json.Marshaler.
+type RootFSProviderUnmarshaler func(payload []byte) (RootFSProvider, error)
+
+var (
+	rootFSProviderTypesLock sync.RWMutex
+	rootFSProviderTypes     = map[RootFSProviderType]RootFSProviderUnmarshaler{
+		RootFSProviderTypeArbitrary: func([]byte) (RootFSProvider, error) {
+			return ArbitraryRootFSProvider{}, nil
+		},
+		RootFSProviderTypeFixedSet: func(payload []byte) (RootFSProvider, error) {
+			var provider FixedSetRootFSProvider
+			err := provider.UnmarshalJSON(payload)
+			return provider, err
+		},
+		RootFSProviderTypePattern: func(payload []byte) (RootFSProvider, error) {
+			var provider PatternRootFSProvider
+			err := provider.UnmarshalJSON(payload)
+			return provider, err
+		},
+	}
+)
+
+func RegisterRootFSProviderType(providerType RootFSProviderType, unmarshal RootFSProviderUnmarshaler) {
+	rootFSProviderTypesLock.Lock()
+	defer rootFSProviderTypesLock.Unlock()
+	rootFSProviderTypes[providerType] = unmarshal
+}
+
 type rootFSProviderEnvelope struct {
 	Type RootFSProviderType `json:"type"`
 }
 
-func unmarshalRootFSProvider(payload []byte) (RootFSProvider, error) {
+// UnmarshalRootFSProvider returns an UnknownRootFSProviderTypeError for types
+// that have not been registered.
+func UnmarshalRootFSProvider(payload []byte) (RootFSProvider, error) {
 	var envelope rootFSProviderEnvelope
 	err := json.Unmarshal(payload, &envelope)
 	if err != nil {
 		return nil, err
 	}
 
-	switch envelope.Type {
-	case RootFSProviderTypeArbitrary:
-		return ArbitraryRootFSProvider{}, nil
-	case RootFSProviderTypeFixedSet:
-		var provider FixedSetRootFSProvider
-		err := provider.UnmarshalJSON(payload)
-		return provider, err
+	rootFSProviderTypesLock.RLock()
+	unmarshal, ok := rootFSProviderTypes[envelope.Type]
+	rootFSProviderTypesLock.RUnlock()
+	if !ok {
+		return nil, UnknownRootFSProviderTypeError{Type: envelope.Type}
 	}
 
-	return nil, nil
+	return unmarshal(payload)
+}
 
+// UnsupportedRootFSProvider stands in for a provider of an unknown type. It
+// never matches and marshals back to the payload it was read from.
+type UnsupportedRootFSProvider struct {
+	ProviderType RootFSProviderType
+	Payload      json.RawMessage
+}
+
+func (provider UnsupportedRootFSProvider) Type() RootFSProviderType { return provider.ProviderType }
+
+func (UnsupportedRootFSProvider) Match(url.URL) bool { return false }
+
+func (provider UnsupportedRootFSProvider) MarshalJSON() ([]byte, error) {
+	if len(provider.Payload) == 0 {
+		return json.Marshal(map[string]string{"type": string(provider.ProviderType)})
+	}
+	return provider.Payload, nil
 }
 
 type ArbitraryRootFSProvider struct{}
@@ -103,7 +168,17 @@ func NewFixedSetRootFSProvider(rootfses ...string) FixedSetRootFSProvider {
 func (FixedSetRootFSProvider) Type() RootFSProviderType { return RootFSProviderTypeFixedSet }
 
 func (provider FixedSetRootFSProvider) Match(rootfs url.URL) bool {
-	return provider.FixedSet.Contains(rootfs.Opaque)
+	constraint := rootfs.Query().Get(StackVersionParam)
+	if constraint == "" {
+		return provider.FixedSet.Contains(rootfs.Opaque)
+	}
+
+	stacks := make([]string, 0, len(provider.FixedSet))
+	for stack := range provider.FixedSet {
+		stacks = append(stacks, stack)
+	}
+	_, ok := BestStack(stacks, rootfs.Opaque, constraint)
+	return ok
 }
 
 func (provider FixedSetRootFSProvider) MarshalJSON() ([]byte, error) {
@@ -143,6 +218,147 @@ func (provider *FixedSetRootFSProvider) UnmarshalJSON(payload []byte) error {
 	return nil
 }
 
+// DefaultRegistryHost is the host of rootfs URLs that do not name one, such
+// as docker:///busybox.
+const DefaultRegistryHost = "docker.io"
+
+// RootFSPatterns match a rootfs URL by its registry host, by a path.Match
+// glob against its repository, or by a regular expression against the whole
+// URL. NewPatternRootFSProvider compiles the regular expressions once;
+// patterns that were not built by it compile them on every match.
+type RootFSPatterns struct {
+	Hosts        []string `json:"hosts,omitempty"`
+	Repositories []string `json:"repositories,omitempty"`
+	Regexps      []string `json:"regexps,omitempty"`
+
+	regexps []*regexp.Regexp
+}
+
+func (p *RootFSPatterns) compile() error {
+	for _, glob := range p.Repositories {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern %q: %s", glob, err)
+		}
+	}
+
+	var err error
+	p.regexps, err = p.compiledRegexps()
+	return err
+}
+
+// compiledRegexps returns the compiled regular expressions, compiling them
+// unless they already have been.
+func (p *RootFSPatterns) compiledRegexps() ([]*regexp.Regexp, error) {
+	if len(p.regexps) == len(p.Regexps) {
+		return p.regexps, nil
+	}
+
+	regexps := make([]*regexp.Regexp, 0, len(p.Regexps))
+	for _, expr := range p.Regexps {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp %q: %s", expr, err)
+		}
+		regexps = append(regexps, re)
+	}
+	return regexps, nil
+}
+
+func (p *RootFSPatterns) empty() bool {
+	return len(p.Hosts) == 0 && len(p.Repositories) == 0 && len(p.Regexps) == 0
+}
+
+// match returns an error if the patterns are invalid, since they cannot then
+// be known not to match.
+func (p *RootFSPatterns) match(rootfs url.URL) (bool, error) {
+	host := rootfs.Host
+	if host == "" {
+		host = DefaultRegistryHost
+	}
+	for _, h := range p.Hosts {
+		if h == host {
+			return true, nil
+		}
+	}
+
+	repository := strings.TrimPrefix(rootfs.Path, "/")
+	for _, glob := range p.Repositories {
+		ok, err := path.Match(glob, repository)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+
+	regexps, err := p.compiledRegexps()
+	if err != nil {
+		return false, err
+	}
+	for _, re := range regexps {
+		if re.MatchString(rootfs.String()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PatternRootFSProvider matches rootfs URLs that are not denied and, when
+// there is an allowlist, are allowed. It matches nothing if its patterns are
+// invalid.
+type PatternRootFSProvider struct {
+	Allow RootFSPatterns `json:"allow"`
+	Deny  RootFSPatterns `json:"deny"`
+}
+
+func NewPatternRootFSProvider(allow, deny RootFSPatterns) (PatternRootFSProvider, error) {
+	provider := PatternRootFSProvider{Allow: allow, Deny: deny}
+	if err := provider.Allow.compile(); err != nil {
+		return PatternRootFSProvider{}, err
+	}
+	if err := provider.Deny.compile(); err != nil {
+		return PatternRootFSProvider{}, err
+	}
+	return provider, nil
+}
+
+func (PatternRootFSProvider) Type() RootFSProviderType { return RootFSProviderTypePattern }
+
+func (provider PatternRootFSProvider) Match(rootfs url.URL) bool {
+	denied, err := provider.Deny.match(rootfs)
+	if err != nil || denied {
+		return false
+	}
+	if provider.Allow.empty() {
+		return true
+	}
+	allowed, err := provider.Allow.match(rootfs)
+	return err == nil && allowed
+}
+
+func (provider PatternRootFSProvider) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  RootFSProviderType `json:"type"`
+		Allow RootFSPatterns     `json:"allow"`
+		Deny  RootFSPatterns     `json:"deny"`
+	}{provider.Type(), provider.Allow, provider.Deny})
+}
+
+func (provider *PatternRootFSProvider) UnmarshalJSON(payload []byte) error {
+	var p struct {
+		Allow RootFSPatterns `json:"allow"`
+		Deny  RootFSPatterns `json:"deny"`
+	}
+	err := json.Unmarshal(payload, &p)
+	if err != nil {
+		return err
+	}
+
+	*provider, err = NewPatternRootFSProvider(p.Allow, p.Deny)
+	return err
+}
+
 type StringSet map[string]struct{}
 
 func NewStringSet(entries ...string) StringSet {
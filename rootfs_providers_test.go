@@ -13,6 +13,7 @@ var _ = Describe("RootFSProviders", func() {
 	var (
 		arbitrary rep.ArbitraryRootFSProvider
 		fixedSet  rep.FixedSetRootFSProvider
+		pattern   rep.PatternRootFSProvider
 		providers rep.RootFSProviders
 
 		providersJSON string
@@ -21,9 +22,18 @@ var _ = Describe("RootFSProviders", func() {
 	BeforeEach(func() {
 		arbitrary = rep.ArbitraryRootFSProvider{}
 		fixedSet = rep.NewFixedSetRootFSProvider("baz", "quux")
+
+		var err error
+		pattern, err = rep.NewPatternRootFSProvider(
+			rep.RootFSPatterns{Hosts: []string{"registry.internal"}, Repositories: []string{"library/*"}},
+			rep.RootFSPatterns{Repositories: []string{"library/untrusted"}, Regexps: []string{"#latest$"}},
+		)
+		Expect(err).NotTo(HaveOccurred())
+
 		providers = rep.RootFSProviders{
-			"foo": arbitrary,
-			"bar": fixedSet,
+			"foo":    arbitrary,
+			"bar":    fixedSet,
+			"docker": pattern,
 		}
 
 		providersJSON = `{
@@ -33,6 +43,11 @@ var _ = Describe("RootFSProviders", func() {
 				"bar": {
 					"type": "fixed_set",
 					"set": {"baz":{}, "quux":{}}
+				},
+				"docker": {
+					"type": "pattern",
+					"allow": {"hosts": ["registry.internal"], "repositories": ["library/*"]},
+					"deny": {"repositories": ["library/untrusted"], "regexps": ["#latest$"]}
 				}
 			}`
 
@@ -53,6 +68,57 @@ var _ = Describe("RootFSProviders", func() {
 		Expect(providersResult).To(Equal(providers))
 	})
 
+	Context("when a provider has an unknown type", func() {
+		const futureJSON = `{"future": {"type": "from_the_future", "settings": {"a": 1}}}`
+
+		It("keeps it as an unsupported provider that never matches", func() {
+			var providersResult rep.RootFSProviders
+			err := json.Unmarshal([]byte(futureJSON), &providersResult)
+			Expect(err).NotTo(HaveOccurred())
+
+			provider := providersResult["future"]
+			Expect(provider).To(BeAssignableToTypeOf(rep.UnsupportedRootFSProvider{}))
+			Expect(provider.Type()).To(Equal(rep.RootFSProviderType("from_the_future")))
+
+			rootFS, err := url.Parse("future://anything")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(providersResult.Match(*rootFS)).To(BeFalse())
+		})
+
+		It("serializes it back unchanged", func() {
+			var providersResult rep.RootFSProviders
+			err := json.Unmarshal([]byte(futureJSON), &providersResult)
+			Expect(err).NotTo(HaveOccurred())
+
+			payload, err := json.Marshal(providersResult)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload).To(MatchJSON(futureJSON))
+		})
+
+		It("is a typed error when unmarshaled on its own", func() {
+			_, err := rep.UnmarshalRootFSProvider([]byte(`{"type": "from_the_future"}`))
+			Expect(err).To(Equal(rep.UnknownRootFSProviderTypeError{Type: "from_the_future"}))
+		})
+	})
+
+	Context("when a provider type is registered", func() {
+		It("is used to unmarshal providers of that type", func() {
+			rep.RegisterRootFSProviderType("registered", func([]byte) (rep.RootFSProvider, error) {
+				return rep.ArbitraryRootFSProvider{}, nil
+			})
+
+			provider, err := rep.UnmarshalRootFSProvider([]byte(`{"type": "registered"}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provider).To(Equal(rep.ArbitraryRootFSProvider{}))
+		})
+	})
+
+	It("does not match a scheme with a nil provider", func() {
+		rootFS, err := url.Parse("nil://anything")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rep.RootFSProviders{"nil": nil}.Match(*rootFS)).To(BeFalse())
+	})
+
 	Describe("Match", func() {
 		Describe("ArbitraryRootFSProvider", func() {
 			It("matches any URL", func() {
@@ -77,6 +143,72 @@ var _ = Describe("RootFSProviders", func() {
 
 				Expect(fixedSet.Match(*rootFS)).To(BeFalse())
 			})
+
+			It("matches a version constraint against versioned entries", func() {
+				versioned := rep.NewFixedSetRootFSProvider("cflinuxfs3@1.179", "cflinuxfs3@1.181", "cflinuxfs3")
+
+				rootFS, err := url.Parse("preloaded:cflinuxfs3?version=>=1.180")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(versioned.Match(*rootFS)).To(BeTrue())
+
+				rootFS, err = url.Parse("preloaded:cflinuxfs3?version=>1.181")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(versioned.Match(*rootFS)).To(BeFalse())
+			})
+		})
+
+		Describe("PatternRootFSProvider", func() {
+			match := func(provider rep.PatternRootFSProvider, rootfs string) bool {
+				rootFS, err := url.Parse(rootfs)
+				ExpectWithOffset(1, err).NotTo(HaveOccurred())
+				return provider.Match(*rootFS)
+			}
+
+			It("matches an allowed host", func() {
+				Expect(match(pattern, "docker://registry.internal/team/app#v1")).To(BeTrue())
+				Expect(match(pattern, "docker://registry.example.com/team/app#v1")).To(BeFalse())
+			})
+
+			It("matches an allowed repository on the default registry", func() {
+				Expect(match(pattern, "docker:///library/busybox")).To(BeTrue())
+				Expect(match(pattern, "docker:///someone/busybox")).To(BeFalse())
+			})
+
+			It("does not match a denied repository even when allowed", func() {
+				Expect(match(pattern, "docker://registry.internal/library/untrusted")).To(BeFalse())
+			})
+
+			It("does not match a url matching a denied regexp", func() {
+				Expect(match(pattern, "docker://registry.internal/team/app#latest")).To(BeFalse())
+			})
+
+			It("matches anything not denied when there is no allowlist", func() {
+				denyOnly, err := rep.NewPatternRootFSProvider(rep.RootFSPatterns{}, rep.RootFSPatterns{Hosts: []string{rep.DefaultRegistryHost}})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(match(denyOnly, "docker://registry.internal/team/app")).To(BeTrue())
+				Expect(match(denyOnly, "docker:///library/busybox")).To(BeFalse())
+			})
+
+			It("applies the regexps of a provider that was not built by NewPatternRootFSProvider", func() {
+				literal := rep.PatternRootFSProvider{Deny: rep.RootFSPatterns{Regexps: []string{"#latest$"}}}
+
+				Expect(match(literal, "docker://registry.internal/team/app#v1")).To(BeTrue())
+				Expect(match(literal, "docker://registry.internal/team/app#latest")).To(BeFalse())
+			})
+
+			It("matches nothing when its patterns are invalid", func() {
+				literal := rep.PatternRootFSProvider{Deny: rep.RootFSPatterns{Regexps: []string{"("}}}
+				Expect(match(literal, "docker://registry.internal/team/app#v1")).To(BeFalse())
+			})
+
+			It("rejects invalid patterns", func() {
+				_, err := rep.NewPatternRootFSProvider(rep.RootFSPatterns{Regexps: []string{"("}}, rep.RootFSPatterns{})
+				Expect(err).To(HaveOccurred())
+
+				_, err = rep.NewPatternRootFSProvider(rep.RootFSPatterns{}, rep.RootFSPatterns{Repositories: []string{"["}})
+				Expect(err).To(HaveOccurred())
+			})
 		})
 
 		Describe("RootFSProviders", func() {
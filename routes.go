@@ -6,13 +6,24 @@ const (
 	StateRoute   = "STATE"
 	PerformRoute = "PERFORM"
 
-	StopLRPInstanceRoute = "StopLRPInstance"
-	CancelTaskRoute      = "CancelTask"
+	StopLRPInstanceRoute  = "StopLRPInstance"
+	CancelTaskRoute       = "CancelTask"
+	StopLRPInstancesRoute = "StopLRPInstances"
+	CancelTasksRoute      = "CancelTasks"
+
+	ContainersRoute = "Containers"
+	ContainerRoute  = "Container"
 
 	Sim_ResetRoute = "RESET"
 
-	PingRoute     = "Ping"
-	EvacuateRoute = "Evacuate"
+	PingRoute             = "Ping"
+	EvacuateRoute         = "Evacuate"
+	EvacuationStatusRoute = "EvacuationStatus"
+	AbortEvacuationRoute  = "AbortEvacuation"
+	CordonRoute           = "Cordon"
+	UncordonRoute         = "Uncordon"
+	HealthRoute           = "Health"
+	ReadyRoute            = "Ready"
 )
 
 func NewRoutes(secure bool) rata.Routes {
@@ -25,6 +36,11 @@ func NewRoutes(secure bool) rata.Routes {
 
 			rata.Route{Path: "/v1/lrps/:process_guid/instances/:instance_guid/stop", Method: "POST", Name: StopLRPInstanceRoute},
 			rata.Route{Path: "/v1/tasks/:task_guid/cancel", Method: "POST", Name: CancelTaskRoute},
+			rata.Route{Path: "/v1/lrps/stop", Method: "POST", Name: StopLRPInstancesRoute},
+			rata.Route{Path: "/v1/tasks/cancel", Method: "POST", Name: CancelTasksRoute},
+
+			rata.Route{Path: "/v1/containers", Method: "GET", Name: ContainersRoute},
+			rata.Route{Path: "/v1/containers/:guid", Method: "GET", Name: ContainerRoute},
 
 			rata.Route{Path: "/sim/reset", Method: "POST", Name: Sim_ResetRoute},
 		)
@@ -34,6 +50,12 @@ func NewRoutes(secure bool) rata.Routes {
 		routes = append(routes,
 			rata.Route{Path: "/ping", Method: "GET", Name: PingRoute},
 			rata.Route{Path: "/evacuate", Method: "POST", Name: EvacuateRoute},
+			rata.Route{Path: "/evacuation", Method: "GET", Name: EvacuationStatusRoute},
+			rata.Route{Path: "/evacuation/abort", Method: "POST", Name: AbortEvacuationRoute},
+			rata.Route{Path: "/cordon", Method: "POST", Name: CordonRoute},
+			rata.Route{Path: "/uncordon", Method: "POST", Name: UncordonRoute},
+			rata.Route{Path: "/health", Method: "GET", Name: HealthRoute},
+			rata.Route{Path: "/ready", Method: "GET", Name: ReadyRoute},
 		)
 	}
 	return routes
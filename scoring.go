@@ -1,18 +1,34 @@
 package rep
 
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+)
+
 type ScoreFunc func(*CellState, *Resource, float64) float64
 type ScoreTypeFunc func(*ScoreType)
 
 type ScoreType struct {
-	Compute ScoreFunc
+	Compute      ScoreFunc
+	AntiAffinity AntiAffinityWeights
 }
 
-func NewScoreType(f ScoreTypeFunc) *ScoreType {
+func NewScoreType(fs ...ScoreTypeFunc) *ScoreType {
 	var st ScoreType
-	f(&st)
+	for _, f := range fs {
+		f(&st)
+	}
 	return &st
 }
 
+// ComputeLRP scores the placement of an LRP, adding the anti-affinity
+// penalty for instances of the same process already on the cell.
+func (st *ScoreType) ComputeLRP(c *CellState, lrp *LRP, startingContainerWeight float64) float64 {
+	return st.Compute(c, &lrp.Resource, startingContainerWeight) + c.AntiAffinityScore(&lrp.ActualLRPKey, st.AntiAffinity)
+}
+
 func BestFitFashion(st *ScoreType) {
 	st.Compute = bestFit
 }
@@ -21,21 +37,264 @@ func WorstFitFashion(st *ScoreType) {
 	st.Compute = computeScore
 }
 
+// UsageAwareFashion mixes the spread score with the usage measured on the
+// cell, steering work away from cells that are busier than their
+// reservations suggest.
+func UsageAwareFashion(st *ScoreType) {
+	st.Compute = usageAware(UsageAwareWeights)
+}
+
+func WithAntiAffinity(weights AntiAffinityWeights) ScoreTypeFunc {
+	return func(st *ScoreType) {
+		st.AntiAffinity = weights
+	}
+}
+
+// ScoreWeights assigns a weight to each resource dimension of a cell. Pids,
+// cpu weight and extended resources only contribute when the cell tracks
+// them. The Extended weight applies to each extended resource. Usage is the
+// share, from 0 to 1, of a usage-aware score that comes from measured usage
+// rather than reservations.
+type ScoreWeights struct {
+	MemoryMB   float64 `json:"memory_mb"`
+	DiskMB     float64 `json:"disk_mb"`
+	Containers float64 `json:"containers"`
+	MaxPids    float64 `json:"max_pids"`
+	CPUWeight  float64 `json:"cpu_weight"`
+	Extended   float64 `json:"extended"`
+	Usage      float64 `json:"usage"`
+}
+
+var (
+	BinPackWeights    = ScoreWeights{MemoryMB: 1, DiskMB: 1, Containers: 3, MaxPids: 1, CPUWeight: 1, Extended: 1}
+	SpreadWeights     = ScoreWeights{MemoryMB: 1, DiskMB: 1, Containers: 1, MaxPids: 1, CPUWeight: 1, Extended: 1}
+	MemoryOnlyWeights = ScoreWeights{MemoryMB: 1}
+	UsageAwareWeights = ScoreWeights{MemoryMB: 1, DiskMB: 1, Containers: 1, MaxPids: 1, CPUWeight: 1, Extended: 1, Usage: 0.5}
+)
+
+const (
+	BinPackStrategy    = "binpack"
+	SpreadStrategy     = "spread"
+	MemoryOnlyStrategy = "memory-only"
+	WeightedStrategy   = "weighted"
+	UsageAwareStrategy = "usage-aware"
+)
+
+var (
+	ErrUnknownScoreStrategy = errors.New("unknown score strategy")
+	ErrMissingScoreWeights  = errors.New("score strategy requires weights")
+)
+
+// A ScoreStrategy builds a ScoreFunc from a set of weights. A nil weights
+// argument means the strategy should use its defaults.
+type ScoreStrategy func(weights *ScoreWeights) (ScoreFunc, error)
+
+var (
+	scoreStrategiesLock sync.RWMutex
+	scoreStrategies     = map[string]ScoreStrategy{
+		BinPackStrategy:    binPackStrategy,
+		SpreadStrategy:     spreadStrategy,
+		MemoryOnlyStrategy: memoryOnlyStrategy,
+		WeightedStrategy:   weightedStrategy,
+		UsageAwareStrategy: usageAwareStrategy,
+	}
+)
+
+func RegisterScoreStrategy(name string, strategy ScoreStrategy) {
+	scoreStrategiesLock.Lock()
+	defer scoreStrategiesLock.Unlock()
+	scoreStrategies[name] = strategy
+}
+
+func ScoreStrategies() []string {
+	scoreStrategiesLock.RLock()
+	defer scoreStrategiesLock.RUnlock()
+
+	names := make([]string, 0, len(scoreStrategies))
+	for name := range scoreStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func NewScoreTypeForStrategy(name string, weights *ScoreWeights) (*ScoreType, error) {
+	scoreStrategiesLock.RLock()
+	strategy, ok := scoreStrategies[name]
+	scoreStrategiesLock.RUnlock()
+	if !ok {
+		return nil, ErrUnknownScoreStrategy
+	}
+
+	compute, err := strategy(weights)
+	if err != nil {
+		return nil, err
+	}
+	return &ScoreType{Compute: compute}, nil
+}
+
+func binPackStrategy(weights *ScoreWeights) (ScoreFunc, error) {
+	w := BinPackWeights
+	if weights != nil {
+		w = *weights
+	}
+	return func(c *CellState, res *Resource, _ float64) float64 {
+		return weightedFractionRemaining(c, res, &w)
+	}, nil
+}
+
+func spreadStrategy(weights *ScoreWeights) (ScoreFunc, error) {
+	w := SpreadWeights
+	if weights != nil {
+		w = *weights
+	}
+	return func(c *CellState, res *Resource, startingContainerWeight float64) float64 {
+		return spreadScore(c, res, &w, startingContainerWeight)
+	}, nil
+}
+
+func memoryOnlyStrategy(*ScoreWeights) (ScoreFunc, error) {
+	return spreadStrategy(&MemoryOnlyWeights)
+}
+
+// weightedStrategy scores a cell with operator supplied weights. A positive
+// weight spreads work across cells along that dimension, a negative weight
+// packs it.
+func weightedStrategy(weights *ScoreWeights) (ScoreFunc, error) {
+	if weights == nil {
+		return nil, ErrMissingScoreWeights
+	}
+	w := *weights
+	return func(c *CellState, res *Resource, startingContainerWeight float64) float64 {
+		remaining := c.AvailableResources.Copy()
+		remaining.Subtract(res)
+		total := &c.TotalResources
+
+		var score, sum float64
+		for _, d := range dimensions(&remaining, total, &w) {
+			score += d.weight * (1.0 - d.fraction)
+			sum += math.Abs(d.weight)
+		}
+		if sum == 0 {
+			return 0
+		}
+		return score/sum + float64(c.StartingContainerCount)*startingContainerWeight
+	}, nil
+}
+
+func usageAwareStrategy(weights *ScoreWeights) (ScoreFunc, error) {
+	w := UsageAwareWeights
+	if weights != nil {
+		w = *weights
+	}
+	return usageAware(w), nil
+}
+
+// usageAware scores every cell on the same scale: the usage of a cell that
+// does not report it is estimated from its reservations, so that such cells
+// neither look idle nor are scored on their reservations alone.
+func usageAware(weights ScoreWeights) ScoreFunc {
+	return func(c *CellState, res *Resource, startingContainerWeight float64) float64 {
+		usage := estimatedUsage(c)
+		if c.Usage != nil {
+			usage = *c.Usage
+		}
+
+		reservations := spreadScore(c, res, &weights, startingContainerWeight)
+		return (1-weights.Usage)*reservations + weights.Usage*usageScore(c, &usage, res)
+	}
+}
+
+// estimatedUsage takes a cell to use all it has reserved, and a share of its
+// cpu in proportion to the cpu weight it has reserved or, when it does not
+// track cpu weight, to its containers.
+func estimatedUsage(c *CellState) Usage {
+	total, available := &c.TotalResources, &c.AvailableResources
+	usage := Usage{}
+	if total.MemoryMB > available.MemoryMB {
+		usage.MemoryMB = total.MemoryMB - available.MemoryMB
+	}
+	if total.DiskMB > available.DiskMB {
+		usage.DiskMB = total.DiskMB - available.DiskMB
+	}
+
+	switch {
+	case total.CPUWeight > 0:
+		usage.CPU = float64(total.CPUWeight-available.CPUWeight) / float64(total.CPUWeight)
+	case total.Containers > 0:
+		usage.CPU = float64(total.Containers-available.Containers) / float64(total.Containers)
+	}
+	usage.CPU = math.Max(0, math.Min(1, usage.CPU))
+	return usage
+}
+
+// usageScore is the average fraction of the cell's memory, disk and cpu in
+// use, counting the reservation of the new work as used.
+func usageScore(c *CellState, usage *Usage, res *Resource) float64 {
+	score := usage.CPU
+	dimensions := 1.0
+	if c.TotalResources.MemoryMB > 0 {
+		score += float64(usage.MemoryMB+res.MemoryMB) / float64(c.TotalResources.MemoryMB)
+		dimensions++
+	}
+	if c.TotalResources.DiskMB > 0 {
+		score += float64(usage.DiskMB+res.DiskMB) / float64(c.TotalResources.DiskMB)
+		dimensions++
+	}
+	return score / dimensions
+}
+
 func computeScore(c *CellState, res *Resource, startingContainerWeight float64) float64 {
-	remainingResources := c.AvailableResources.Copy()
-	remainingResources.Subtract(res)
-	startingContainerScore := float64(c.StartingContainerCount) * startingContainerWeight
-	return remainingResources.ComputeScore(&c.TotalResources) + startingContainerScore
+	return spreadScore(c, res, &SpreadWeights, startingContainerWeight)
 }
 
 func bestFit(c *CellState, res *Resource, startingContainerWeight float64) float64 {
-	total := c.TotalResources
-	remainingResources := c.AvailableResources.Copy()
-	remainingResources.Subtract(res)
+	return weightedFractionRemaining(c, res, &BinPackWeights)
+}
+
+func spreadScore(c *CellState, res *Resource, weights *ScoreWeights, startingContainerWeight float64) float64 {
+	startingContainerScore := float64(c.StartingContainerCount) * startingContainerWeight
+	return 1.0 - weightedFractionRemaining(c, res, weights) + startingContainerScore
+}
+
+func weightedFractionRemaining(c *CellState, res *Resource, weights *ScoreWeights) float64 {
+	remaining := c.AvailableResources.Copy()
+	remaining.Subtract(res)
+
+	var score, sum float64
+	for _, d := range dimensions(&remaining, &c.TotalResources, weights) {
+		score += d.weight * d.fraction
+		sum += d.weight
+	}
+	if sum == 0 {
+		return 0
+	}
+	return score / sum
+}
+
+type scoreDimension struct {
+	weight   float64
+	fraction float64
+}
 
-	fractionUsedMemory := float64(remainingResources.MemoryMB) / float64(total.MemoryMB)
-	fractionUsedDisk := float64(remainingResources.DiskMB) / float64(total.DiskMB)
-	fractionUsedContainers := ((float64(remainingResources.Containers) / float64(total.Containers)) * 3) // give more weigh to cells with a high container load
+// dimensions returns the fraction of each weighted resource that remains on
+// the cell, skipping dimensions the cell does not track.
+func dimensions(remaining, total *Resources, weights *ScoreWeights) []scoreDimension {
+	ds := []scoreDimension{}
+	add := func(weight, r, t float64) {
+		if weight == 0 || t <= 0 {
+			return
+		}
+		ds = append(ds, scoreDimension{weight: weight, fraction: r / t})
+	}
 
-	return (fractionUsedMemory + fractionUsedDisk + fractionUsedContainers) / 5.0
+	add(weights.MemoryMB, float64(remaining.MemoryMB), float64(total.MemoryMB))
+	add(weights.DiskMB, float64(remaining.DiskMB), float64(total.DiskMB))
+	add(weights.Containers, float64(remaining.Containers), float64(total.Containers))
+	add(weights.MaxPids, float64(remaining.MaxPids), float64(total.MaxPids))
+	add(weights.CPUWeight, float64(remaining.CPUWeight), float64(total.CPUWeight))
+	for name, amount := range total.Extended {
+		add(weights.Extended, float64(remaining.Extended[name]), float64(amount))
+	}
+	return ds
 }
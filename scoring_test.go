@@ -0,0 +1,279 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scoring", func() {
+	var (
+		cells    map[string]*rep.CellState
+		resource rep.Resource
+	)
+
+	newCell := func(availMemory, availDisk int32, availContainers int) *rep.CellState {
+		state := rep.NewCellState(
+			nil,
+			rep.NewResources(availMemory, availDisk, availContainers),
+			rep.NewResources(1000, 1000, 10),
+			nil,
+			nil,
+			"my-zone",
+			0,
+			false,
+			nil,
+			nil,
+			nil,
+		)
+		return &state
+	}
+
+	place := func(st *rep.ScoreType) string {
+		winner := ""
+		best := 0.0
+		for name, cell := range cells {
+			score := st.Compute(cell, &resource, 0)
+			if winner == "" || score < best {
+				winner, best = name, score
+			}
+		}
+		return winner
+	}
+
+	forStrategy := func(name string, weights *rep.ScoreWeights) *rep.ScoreType {
+		st, err := rep.NewScoreTypeForStrategy(name, weights)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return st
+	}
+
+	BeforeEach(func() {
+		resource = rep.NewResource(100, 100, 0)
+		cells = map[string]*rep.CellState{
+			"empty":       newCell(1000, 1000, 10),
+			"half-full":   newCell(500, 500, 5),
+			"memory-full": newCell(200, 900, 9),
+			"disk-full":   newCell(900, 200, 9),
+		}
+	})
+
+	It("lists the built-in strategies", func() {
+		Expect(rep.ScoreStrategies()).To(ContainElement(rep.BinPackStrategy))
+		Expect(rep.ScoreStrategies()).To(ContainElement(rep.SpreadStrategy))
+		Expect(rep.ScoreStrategies()).To(ContainElement(rep.MemoryOnlyStrategy))
+		Expect(rep.ScoreStrategies()).To(ContainElement(rep.WeightedStrategy))
+		Expect(rep.ScoreStrategies()).To(ContainElement(rep.UsageAwareStrategy))
+	})
+
+	It("scores the same as the formulas it replaces", func() {
+		// binpack: (memory + disk + 3 * containers remaining) / 5
+		// spread: fraction used averaged over memory, disk and containers,
+		// plus the weight of each starting container
+		expected := map[string]struct{ binPack, spread float64 }{
+			"empty":       {binPack: 0.9, spread: 0.1 + 0.5},
+			"half-full":   {binPack: 0.4, spread: 0.6 + 0.5},
+			"memory-full": {binPack: 0.66, spread: 1.3/3 + 0.5},
+			"disk-full":   {binPack: 0.66, spread: 1.3/3 + 0.5},
+		}
+
+		for name, cell := range cells {
+			cell.StartingContainerCount = 2
+			Expect(forStrategy(rep.BinPackStrategy, nil).Compute(cell, &resource, 0.25)).To(
+				BeNumerically("~", expected[name].binPack, 1e-9), name)
+			Expect(forStrategy(rep.SpreadStrategy, nil).Compute(cell, &resource, 0.25)).To(
+				BeNumerically("~", expected[name].spread, 1e-9), name)
+		}
+	})
+
+	Describe("placement", func() {
+		It("binpack favours the cell with the least headroom", func() {
+			Expect(place(forStrategy(rep.BinPackStrategy, nil))).To(Equal("half-full"))
+		})
+
+		It("spread favours the emptiest cell", func() {
+			Expect(place(forStrategy(rep.SpreadStrategy, nil))).To(Equal("empty"))
+		})
+
+		It("memory-only ignores disk usage", func() {
+			delete(cells, "empty")
+			Expect(place(forStrategy(rep.MemoryOnlyStrategy, nil))).To(Equal("disk-full"))
+		})
+
+		It("weighted packs along dimensions with negative weights", func() {
+			st := forStrategy(rep.WeightedStrategy, &rep.ScoreWeights{MemoryMB: 1, DiskMB: -1})
+			Expect(place(st)).To(Equal("disk-full"))
+		})
+
+		It("spread accounts for extended resources the cells track", func() {
+			delete(cells, "half-full")
+			delete(cells, "memory-full")
+			delete(cells, "disk-full")
+			cells["empty-but-no-ssd"] = newCell(1000, 1000, 10)
+			for _, cell := range cells {
+				cell.TotalResources.Extended = map[string]int32{"ssd-slots": 4}
+			}
+			cells["empty"].AvailableResources.Extended = map[string]int32{"ssd-slots": 4}
+			cells["empty-but-no-ssd"].AvailableResources.Extended = map[string]int32{"ssd-slots": 0}
+
+			Expect(place(forStrategy(rep.SpreadStrategy, nil))).To(Equal("empty"))
+		})
+
+		It("binpack accepts tuned weights", func() {
+			st := forStrategy(rep.BinPackStrategy, &rep.ScoreWeights{DiskMB: 1})
+			Expect(place(st)).To(Equal("disk-full"))
+		})
+	})
+
+	Describe("anti-affinity", func() {
+		var lrp *rep.LRP
+
+		BeforeEach(func() {
+			lrp = BuildLRP("pg-1", "domain", 3, "", 100, 100, 0)
+
+			cells["empty"].LRPs = []rep.LRP{
+				*BuildLRP("pg-1", "domain", 0, "", 10, 10, 0),
+				*BuildLRP("pg-1", "domain", 1, "", 10, 10, 0),
+				*BuildLRP("pg-1", "domain", 2, "", 10, 10, 0),
+			}
+			cells["half-full"].LRPs = []rep.LRP{
+				*BuildLRP("pg-2", "domain", 0, "", 10, 10, 0),
+			}
+			cells["memory-full"].LRPs = []rep.LRP{
+				*BuildLRP("pg-3", "other-domain", 0, "", 10, 10, 0),
+			}
+		})
+
+		It("does not penalize cells when no weights are configured", func() {
+			st := rep.NewScoreType(rep.WorstFitFashion)
+			Expect(st.ComputeLRP(cells["empty"], lrp, 0)).To(Equal(st.Compute(cells["empty"], &lrp.Resource, 0)))
+		})
+
+		It("penalizes cells hosting instances of the same process", func() {
+			st := rep.NewScoreType(rep.WorstFitFashion, rep.WithAntiAffinity(rep.AntiAffinityWeights{ProcessGuid: 1}))
+			Expect(cells["empty"].AntiAffinityScore(&lrp.ActualLRPKey, st.AntiAffinity)).To(Equal(3.0))
+
+			winner, best := "", 0.0
+			for name, cell := range cells {
+				score := st.ComputeLRP(cell, lrp, 0)
+				if winner == "" || score < best {
+					winner, best = name, score
+				}
+			}
+			Expect(winner).NotTo(Equal("empty"))
+		})
+
+		It("optionally penalizes cells hosting the same domain", func() {
+			weights := rep.AntiAffinityWeights{Domain: 0.5}
+			Expect(cells["half-full"].AntiAffinityScore(&lrp.ActualLRPKey, weights)).To(Equal(0.5))
+			Expect(cells["memory-full"].AntiAffinityScore(&lrp.ActualLRPKey, weights)).To(BeZero())
+		})
+
+		It("accounts for lrps added during the auction", func() {
+			weights := rep.AntiAffinityWeights{ProcessGuid: 1}
+			cells["disk-full"].AddLRP(BuildLRP("pg-1", "domain", 4, "", 10, 10, 0))
+			Expect(cells["disk-full"].AntiAffinityScore(&lrp.ActualLRPKey, weights)).To(Equal(1.0))
+		})
+	})
+
+	Describe("usage-aware", func() {
+		It("estimates the usage of a cell that does not report it from its reservations", func() {
+			st := forStrategy(rep.UsageAwareStrategy, nil)
+			for name, cell := range cells {
+				cell.StartingContainerCount = 2
+				unreported := st.Compute(cell, &resource, 0.25)
+
+				cell.Usage = &rep.Usage{
+					MemoryMB: cell.TotalResources.MemoryMB - cell.AvailableResources.MemoryMB,
+					DiskMB:   cell.TotalResources.DiskMB - cell.AvailableResources.DiskMB,
+					CPU:      float64(cell.TotalResources.Containers-cell.AvailableResources.Containers) / float64(cell.TotalResources.Containers),
+				}
+				Expect(unreported).To(BeNumerically("~", st.Compute(cell, &resource, 0.25)), name)
+			}
+		})
+
+		It("does not favour a cell for not reporting its usage", func() {
+			cells = map[string]*rep.CellState{
+				"reporting":   newCell(500, 500, 5),
+				"unreporting": newCell(400, 400, 4),
+			}
+			cells["reporting"].Usage = &rep.Usage{MemoryMB: 500, DiskMB: 500, CPU: 0.5}
+
+			Expect(place(rep.NewScoreType(rep.UsageAwareFashion))).To(Equal("reporting"))
+		})
+
+		It("weighs the starting containers the same whether or not the cell reports usage", func() {
+			cells = map[string]*rep.CellState{
+				"reporting":   newCell(500, 500, 5),
+				"unreporting": newCell(500, 500, 5),
+			}
+			cells["reporting"].Usage = &rep.Usage{MemoryMB: 500, DiskMB: 500, CPU: 0.5}
+			cells["reporting"].StartingContainerCount = 1
+			cells["unreporting"].StartingContainerCount = 2
+
+			st := forStrategy(rep.UsageAwareStrategy, nil)
+			reporting := st.Compute(cells["reporting"], &resource, 0.25)
+			unreporting := st.Compute(cells["unreporting"], &resource, 0.25)
+			Expect(unreporting - reporting).To(BeNumerically("~", (1-rep.UsageAwareWeights.Usage)*0.25))
+		})
+
+		It("steers work away from cells that are busier than their reservations", func() {
+			cells = map[string]*rep.CellState{
+				"idle": newCell(500, 500, 5),
+				"hot":  newCell(500, 500, 5),
+			}
+			cells["idle"].Usage = &rep.Usage{MemoryMB: 50, DiskMB: 50, CPU: 0.1}
+			cells["hot"].Usage = &rep.Usage{MemoryMB: 450, DiskMB: 100, CPU: 0.9}
+
+			Expect(place(rep.NewScoreType(rep.UsageAwareFashion))).To(Equal("idle"))
+		})
+
+		It("prefers a busier but emptier cell when usage is given no share", func() {
+			cells = map[string]*rep.CellState{
+				"idle-but-reserved": newCell(200, 200, 2),
+				"hot-but-empty":     newCell(1000, 1000, 10),
+			}
+			cells["idle-but-reserved"].Usage = &rep.Usage{CPU: 0.1}
+			cells["hot-but-empty"].Usage = &rep.Usage{MemoryMB: 900, CPU: 0.9}
+
+			weights := rep.SpreadWeights
+			Expect(place(forStrategy(rep.UsageAwareStrategy, &weights))).To(Equal("hot-but-empty"))
+			weights.Usage = 1
+			Expect(place(forStrategy(rep.UsageAwareStrategy, &weights))).To(Equal("idle-but-reserved"))
+		})
+	})
+
+	Context("when the strategy is unknown", func() {
+		It("returns an error", func() {
+			_, err := rep.NewScoreTypeForStrategy("first-fit", nil)
+			Expect(err).To(MatchError(rep.ErrUnknownScoreStrategy))
+		})
+	})
+
+	Context("when the weighted strategy is missing weights", func() {
+		It("returns an error", func() {
+			_, err := rep.NewScoreTypeForStrategy(rep.WeightedStrategy, nil)
+			Expect(err).To(MatchError(rep.ErrMissingScoreWeights))
+		})
+	})
+
+	Context("when registering a custom strategy", func() {
+		BeforeEach(func() {
+			rep.RegisterScoreStrategy("always-zero", func(*rep.ScoreWeights) (rep.ScoreFunc, error) {
+				return func(*rep.CellState, *rep.Resource, float64) float64 { return 0 }, nil
+			})
+		})
+
+		AfterEach(func() {
+			rep.DeregisterScoreStrategy("always-zero")
+		})
+
+		It("is listed with the built-in strategies", func() {
+			Expect(rep.ScoreStrategies()).To(ContainElement("always-zero"))
+		})
+
+		It("can be selected by name", func() {
+			st := forStrategy("always-zero", nil)
+			Expect(st.Compute(cells["empty"], &resource, 0)).To(BeZero())
+		})
+	})
+})
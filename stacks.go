@@ -0,0 +1,202 @@
+package rep
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A preloaded stack may be advertised at several versions by naming it
+// "<stack>@<version>", e.g. "cflinuxfs3@1.180". Rootfs URLs select a version
+// with a constraint, e.g. "preloaded:cflinuxfs3?version=>=1.180".
+const (
+	StackVersionSeparator = "@"
+	StackVersionParam     = "version"
+)
+
+var ErrInvalidVersionConstraint = errors.New("invalid version constraint")
+
+// SplitStack splits a stack entry into its name and version. The version is
+// empty for unversioned entries.
+func SplitStack(entry string) (string, string) {
+	parts := strings.SplitN(entry, StackVersionSeparator, 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+type StackVersion []int
+
+func ParseStackVersion(version string) (StackVersion, error) {
+	parts := strings.Split(version, ".")
+	v := make(StackVersion, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid stack version %q", version)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1. Missing components are treated as zero, so
+// 1.180 equals 1.180.0.
+func (v StackVersion) Compare(other StackVersion) int {
+	for i := 0; i < len(v) || i < len(other); i++ {
+		var a, b int
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(other) {
+			b = other[i]
+		}
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+	}
+	return 0
+}
+
+type versionBound struct {
+	operator string
+	version  StackVersion
+}
+
+// VersionConstraint is a comma separated list of bounds, all of which must
+// hold, e.g. ">=1.180,<2". A bare version means "=".
+type VersionConstraint []versionBound
+
+var versionOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+func ParseVersionConstraint(constraint string) (VersionConstraint, error) {
+	c := VersionConstraint{}
+	for _, expr := range strings.Split(constraint, ",") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+
+		operator := "="
+		for _, op := range versionOperators {
+			if strings.HasPrefix(expr, op) {
+				operator = op
+				expr = strings.TrimSpace(strings.TrimPrefix(expr, op))
+				break
+			}
+		}
+
+		version, err := ParseStackVersion(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", ErrInvalidVersionConstraint, err)
+		}
+		c = append(c, versionBound{operator: operator, version: version})
+	}
+
+	if len(c) == 0 {
+		return nil, ErrInvalidVersionConstraint
+	}
+	return c, nil
+}
+
+func (c VersionConstraint) Allows(version StackVersion) bool {
+	for _, bound := range c {
+		cmp := version.Compare(bound.version)
+		var ok bool
+		switch bound.operator {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// BestStack picks the entry to use for stack from a list of stack entries.
+// Without a constraint an exact entry wins, followed by the highest version.
+// With a constraint only versioned entries it allows are considered.
+func BestStack(entries []string, stack, constraint string) (string, bool) {
+	var c VersionConstraint
+	if constraint == "" {
+		for _, entry := range entries {
+			if entry == stack {
+				return entry, true
+			}
+		}
+	} else {
+		var err error
+		c, err = ParseVersionConstraint(constraint)
+		if err != nil {
+			return "", false
+		}
+	}
+
+	best, bestVersion := "", StackVersion(nil)
+	for _, entry := range entries {
+		name, version := SplitStack(entry)
+		if name != stack || version == "" {
+			continue
+		}
+
+		v, err := ParseStackVersion(version)
+		if err != nil || (c != nil && !c.Allows(v)) {
+			continue
+		}
+
+		if best == "" || v.Compare(bestVersion) > 0 {
+			best, bestVersion = entry, v
+		}
+	}
+	return best, best != ""
+}
+
+// Stacks returns the stack entries of the map along with the bare name of
+// every versioned stack, which resolves to its highest version.
+func (m StackPathMap) Stacks() []string {
+	set := NewStringSet()
+	for entry := range m {
+		name, _ := SplitStack(entry)
+		set[entry] = struct{}{}
+		set[name] = struct{}{}
+	}
+
+	stacks := make([]string, 0, len(set))
+	for stack := range set {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+	return stacks
+}
+
+// PathForStack returns the path of the best entry for stack that satisfies
+// the version constraint.
+func (m StackPathMap) PathForStack(stack, constraint string) (string, bool) {
+	entries := make([]string, 0, len(m))
+	for entry := range m {
+		entries = append(entries, entry)
+	}
+
+	entry, ok := BestStack(entries, stack, constraint)
+	if !ok {
+		return "", false
+	}
+	return m[entry], true
+}
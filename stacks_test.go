@@ -0,0 +1,113 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stacks", func() {
+	Describe("SplitStack", func() {
+		It("splits a versioned stack into its name and version", func() {
+			name, version := rep.SplitStack("cflinuxfs3@1.180.0")
+			Expect(name).To(Equal("cflinuxfs3"))
+			Expect(version).To(Equal("1.180.0"))
+		})
+
+		It("returns an empty version for an unversioned stack", func() {
+			name, version := rep.SplitStack("cflinuxfs3")
+			Expect(name).To(Equal("cflinuxfs3"))
+			Expect(version).To(BeEmpty())
+		})
+	})
+
+	Describe("ParseVersionConstraint", func() {
+		allows := func(constraint, version string) bool {
+			c, err := rep.ParseVersionConstraint(constraint)
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			v, err := rep.ParseStackVersion(version)
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			return c.Allows(v)
+		}
+
+		It("supports comparison operators", func() {
+			Expect(allows(">=1.180", "1.180")).To(BeTrue())
+			Expect(allows(">=1.180", "1.179.9")).To(BeFalse())
+			Expect(allows(">1.180", "1.180.0")).To(BeFalse())
+			Expect(allows("<2", "1.999")).To(BeTrue())
+			Expect(allows("<=1.180", "1.181")).To(BeFalse())
+			Expect(allows("!=1.180", "1.181")).To(BeTrue())
+		})
+
+		It("treats a bare version as an exact match", func() {
+			Expect(allows("1.180", "1.180.0")).To(BeTrue())
+			Expect(allows("1.180", "1.181")).To(BeFalse())
+		})
+
+		It("requires every bound in a list to hold", func() {
+			Expect(allows(">=1.180, <2", "1.200")).To(BeTrue())
+			Expect(allows(">=1.180, <2", "2.0")).To(BeFalse())
+		})
+
+		It("rejects invalid constraints", func() {
+			_, err := rep.ParseVersionConstraint(">=one")
+			Expect(err).To(MatchError(ContainSubstring(rep.ErrInvalidVersionConstraint.Error())))
+
+			_, err = rep.ParseVersionConstraint("")
+			Expect(err).To(MatchError(rep.ErrInvalidVersionConstraint))
+		})
+	})
+
+	Describe("StackPathMap", func() {
+		var stackPathMap rep.StackPathMap
+
+		BeforeEach(func() {
+			stackPathMap = rep.StackPathMap{
+				"cflinuxfs3@1.179": "/path/1.179",
+				"cflinuxfs3@1.181": "/path/1.181",
+				"cflinuxfs3@2.0":   "/path/2.0",
+				"windows":          "/path/windows",
+			}
+		})
+
+		It("lists each entry along with the bare name of versioned stacks", func() {
+			Expect(stackPathMap.Stacks()).To(Equal([]string{
+				"cflinuxfs3", "cflinuxfs3@1.179", "cflinuxfs3@1.181", "cflinuxfs3@2.0", "windows",
+			}))
+		})
+
+		It("picks the highest version satisfying the constraint", func() {
+			path, ok := stackPathMap.PathForStack("cflinuxfs3", ">=1.180,<2")
+			Expect(ok).To(BeTrue())
+			Expect(path).To(Equal("/path/1.181"))
+		})
+
+		It("picks the highest version when there is no constraint", func() {
+			path, ok := stackPathMap.PathForStack("cflinuxfs3", "")
+			Expect(ok).To(BeTrue())
+			Expect(path).To(Equal("/path/2.0"))
+		})
+
+		It("prefers an exact entry when there is no constraint", func() {
+			stackPathMap["cflinuxfs3"] = "/path/unversioned"
+			path, ok := stackPathMap.PathForStack("cflinuxfs3", "")
+			Expect(ok).To(BeTrue())
+			Expect(path).To(Equal("/path/unversioned"))
+		})
+
+		It("does not match unversioned entries against a constraint", func() {
+			_, ok := stackPathMap.PathForStack("windows", ">=1")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("does not match when no version satisfies the constraint", func() {
+			_, ok := stackPathMap.PathForStack("cflinuxfs3", ">2")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("does not match an invalid constraint", func() {
+			_, ok := stackPathMap.PathForStack("cflinuxfs3", "latest")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,59 @@
+package rep
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StateWaitParam is the query parameter of the state route that asks the
+// cell to hold the request, for at most the given duration, until its state
+// no longer matches the If-None-Match header.
+const StateWaitParam = "wait"
+
+// MaxStateWait bounds how long a cell holds a long-polling state request.
+const MaxStateWait = 5 * time.Minute
+
+var ErrInvalidStateETag = errors.New("invalid state etag")
+
+// StateVersion identifies a state of a cell. The number increases every time
+// the state changes, and the epoch changes every time the rep restarts, so
+// that versions handed out by an earlier rep are never mistaken for current
+// ones.
+type StateVersion struct {
+	Epoch  int64
+	Number uint64
+}
+
+func (v StateVersion) IsZero() bool {
+	return v == StateVersion{}
+}
+
+func (v StateVersion) ETag() string {
+	return fmt.Sprintf(`"%d.%d"`, v.Epoch, v.Number)
+}
+
+func ParseStateETag(etag string) (StateVersion, error) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return StateVersion{}, ErrInvalidStateETag
+	}
+
+	parts := strings.Split(etag[1:len(etag)-1], ".")
+	if len(parts) != 2 {
+		return StateVersion{}, ErrInvalidStateETag
+	}
+
+	epoch, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return StateVersion{}, ErrInvalidStateETag
+	}
+	number, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return StateVersion{}, ErrInvalidStateETag
+	}
+
+	return StateVersion{Epoch: epoch, Number: number}, nil
+}
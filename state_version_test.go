@@ -0,0 +1,32 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StateVersion", func() {
+	It("round trips through an etag", func() {
+		version := rep.StateVersion{Epoch: 1500000000, Number: 42}
+		Expect(version.ETag()).To(Equal(`"1500000000.42"`))
+
+		parsed, err := rep.ParseStateETag(version.ETag())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(version))
+	})
+
+	It("accepts weak etags", func() {
+		parsed, err := rep.ParseStateETag(`W/"1.2"`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(rep.StateVersion{Epoch: 1, Number: 2}))
+	})
+
+	It("rejects etags it did not hand out", func() {
+		for _, etag := range []string{"", "1.2", `"1"`, `"a.b"`, `"1.-2"`} {
+			_, err := rep.ParseStateETag(etag)
+			Expect(err).To(MatchError(rep.ErrInvalidStateETag), etag)
+		}
+	})
+})
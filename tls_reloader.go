@@ -0,0 +1,202 @@
+package rep
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cfhttp"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+// CertificateReloader keeps the TLS configuration of a rep server or client
+// in step with its cert, key and CA bundle files. The files are checked for
+// changes at most once per check interval, when a new connection is set up,
+// so connections that are already established are never disturbed. While
+// migrating to a new CA, the CA bundle should hold both the old and the new
+// CA so that peers with either kind of certificate are trusted.
+type CertificateReloader struct {
+	logger        lager.Logger
+	clock         clock.Clock
+	files         []string
+	checkInterval time.Duration
+
+	lock      sync.Mutex
+	checkedAt time.Time
+	modTimes  []time.Time
+	config    *tls.Config
+}
+
+func NewCertificateReloader(
+	logger lager.Logger,
+	clock clock.Clock,
+	certFile, keyFile, caCertFile string,
+	checkInterval time.Duration,
+) (*CertificateReloader, error) {
+	r := &CertificateReloader{
+		logger:        logger.Session("certificate-reloader"),
+		clock:         clock,
+		files:         []string{certFile, keyFile, caCertFile},
+		checkInterval: checkInterval,
+	}
+
+	modTimes, err := r.modTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := cfhttp.NewTLSConfig(certFile, keyFile, caCertFile)
+	if err != nil {
+		return nil, err
+	}
+
+	r.checkedAt = clock.Now()
+	r.modTimes = modTimes
+	r.config = config
+	return r, nil
+}
+
+// ServerTLSConfig returns a configuration for a TLS listener that picks up
+// the latest certificate and CA bundle for every handshake.
+func (r *CertificateReloader) ServerTLSConfig() *tls.Config {
+	config := r.current().Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		return r.current(), nil
+	}
+	return config
+}
+
+// DialTLS returns a DialTLSContext function for an http.Transport that sets
+// up every new connection with the latest certificate and CA bundle. Dialing
+// follows ctx, and the handshake is abandoned once handshakeTimeout has
+// passed or ctx is done.
+func (r *CertificateReloader) DialTLS(
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+	sessionCache tls.ClientSessionCache,
+	handshakeTimeout time.Duration,
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		config := r.current().Clone()
+		config.ServerName = host
+		config.ClientSessionCache = sessionCache
+
+		tlsConn := tls.Client(conn, config)
+		if err := handshake(ctx, tlsConn, handshakeTimeout); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// handshake bounds the handshake with a deadline on the connection, and
+// interrupts it when ctx is done.
+func handshake(ctx context.Context, conn *tls.Conn, timeout time.Duration) error {
+	deadline, hasDeadline := ctx.Deadline()
+	if timeout > 0 {
+		if timeoutAt := time.Now().Add(timeout); !hasDeadline || timeoutAt.Before(deadline) {
+			deadline, hasDeadline = timeoutAt, true
+		}
+	}
+	if hasDeadline {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	finished := make(chan struct{})
+	interrupted := make(chan struct{})
+	go func() {
+		defer close(interrupted)
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-finished:
+		}
+	}()
+
+	err := conn.Handshake()
+	close(finished)
+	<-interrupted
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+// current returns the configuration built from the files, first reloading
+// them if they have changed. If they cannot be loaded, for instance because
+// only some of them have been replaced so far, the previous configuration is
+// kept and the files are checked again after the next interval.
+func (r *CertificateReloader) current() *tls.Config {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.checkInterval <= 0 || r.clock.Since(r.checkedAt) < r.checkInterval {
+		return r.config
+	}
+	r.checkedAt = r.clock.Now()
+
+	modTimes, err := r.modTimes()
+	if err != nil {
+		r.logger.Error("failed-to-stat-files", err)
+		return r.config
+	}
+	if !r.changed(modTimes) {
+		return r.config
+	}
+
+	config, err := cfhttp.NewTLSConfig(r.files[0], r.files[1], r.files[2])
+	if err != nil {
+		r.logger.Error("failed-to-reload", err)
+		return r.config
+	}
+
+	r.logger.Info("reloaded", lager.Data{"files": r.files})
+	r.modTimes = modTimes
+	r.config = config
+	return r.config
+}
+
+func (r *CertificateReloader) modTimes() ([]time.Time, error) {
+	modTimes := make([]time.Time, len(r.files))
+	for i, file := range r.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *CertificateReloader) changed(modTimes []time.Time) bool {
+	for i := range modTimes {
+		if !modTimes[i].Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package rep_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"time"
+
+	"code.cloudfoundry.org/cfhttp"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("CertificateReloader", func() {
+	const checkInterval = time.Minute
+
+	var (
+		fixturePath      string
+		serverDir        string
+		clientDir        string
+		clock            *fakeclock.FakeClock
+		logger           *lagertest.TestLogger
+		serverReloader   *rep.CertificateReloader
+		clientReloader   *rep.CertificateReloader
+		server           *httptest.Server
+		release          chan struct{}
+		peerCertificates chan *x509.Certificate
+	)
+
+	fixture := func(name string) []byte {
+		contents, err := ioutil.ReadFile(path.Join(fixturePath, name))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return contents
+	}
+
+	// install writes the files of a cert set and makes sure their
+	// modification time changes, however quickly they are rewritten.
+	var installedAt time.Time
+	install := func(dir string, files map[string][]byte) {
+		installedAt = installedAt.Add(time.Second)
+		for name, contents := range files {
+			file := path.Join(dir, name)
+			ExpectWithOffset(1, ioutil.WriteFile(file, contents, 0600)).To(Succeed())
+			ExpectWithOffset(1, os.Chtimes(file, installedAt, installedAt)).To(Succeed())
+		}
+	}
+
+	bothCAs := func() []byte {
+		return append(fixture("blue-certs/server-ca.crt"), fixture("green-certs/server-ca.crt")...)
+	}
+
+	certificate := func(name string) *x509.Certificate {
+		block, _ := pem.Decode(fixture(name))
+		cert, err := x509.ParseCertificate(block.Bytes)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return cert
+	}
+
+	newClient := func() *http.Client {
+		httpClient := cfhttp.NewClient()
+		_, err := rep.NewClientFactory(httpClient, httpClient, &rep.TLSConfig{RequireTLS: true, Reloader: clientReloader})
+		Expect(err).NotTo(HaveOccurred())
+		return httpClient
+	}
+
+	get := func(httpClient *http.Client, route string) *http.Response {
+		resp, err := httpClient.Get(server.URL + route)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		ExpectWithOffset(1, resp.StatusCode).To(Equal(http.StatusOK))
+		resp.Body.Close()
+		return resp
+	}
+
+	BeforeEach(func() {
+		fixturePath = path.Join(os.Getenv("GOPATH"), "src/code.cloudfoundry.org/rep/cmd/rep/fixtures")
+		clock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("test")
+		installedAt = time.Now()
+
+		var err error
+		serverDir, err = ioutil.TempDir("", "server-certs")
+		Expect(err).NotTo(HaveOccurred())
+		clientDir, err = ioutil.TempDir("", "client-certs")
+		Expect(err).NotTo(HaveOccurred())
+
+		install(serverDir, map[string][]byte{
+			"server.crt": fixture("blue-certs/server.crt"),
+			"server.key": fixture("blue-certs/server.key"),
+			"ca.crt":     bothCAs(),
+		})
+		install(clientDir, map[string][]byte{
+			"client.crt": fixture("blue-certs/client.crt"),
+			"client.key": fixture("blue-certs/client.key"),
+			"ca.crt":     bothCAs(),
+		})
+
+		serverReloader, err = rep.NewCertificateReloader(logger, clock,
+			path.Join(serverDir, "server.crt"), path.Join(serverDir, "server.key"), path.Join(serverDir, "ca.crt"), checkInterval)
+		Expect(err).NotTo(HaveOccurred())
+		clientReloader, err = rep.NewCertificateReloader(logger, clock,
+			path.Join(clientDir, "client.crt"), path.Join(clientDir, "client.key"), path.Join(clientDir, "ca.crt"), checkInterval)
+		Expect(err).NotTo(HaveOccurred())
+
+		release = make(chan struct{})
+		peerCertificates = make(chan *x509.Certificate, 10)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {
+			peerCertificates <- r.TLS.PeerCertificates[0]
+		})
+		mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		})
+		server = httptest.NewUnstartedServer(mux)
+		server.TLS = serverReloader.ServerTLSConfig()
+		server.StartTLS()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(serverDir)
+		os.RemoveAll(clientDir)
+	})
+
+	It("fails to start when the files are invalid", func() {
+		_, err := rep.NewCertificateReloader(logger, clock,
+			path.Join(serverDir, "server.crt"), path.Join(clientDir, "client.key"), path.Join(serverDir, "ca.crt"), checkInterval)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("serves the rotated server certificate to new connections", func() {
+		resp := get(newClient(), "/fast")
+		Expect(resp.TLS.PeerCertificates[0].Equal(certificate("blue-certs/server.crt"))).To(BeTrue())
+
+		install(serverDir, map[string][]byte{
+			"server.crt": fixture("green-certs/server.crt"),
+			"server.key": fixture("green-certs/server.key"),
+		})
+		clock.Increment(checkInterval)
+
+		resp = get(newClient(), "/fast")
+		Expect(resp.TLS.PeerCertificates[0].Equal(certificate("green-certs/server.crt"))).To(BeTrue())
+	})
+
+	It("presents the rotated client certificate on new connections", func() {
+		httpClient := newClient()
+		get(httpClient, "/fast")
+		Expect((<-peerCertificates).Equal(certificate("blue-certs/client.crt"))).To(BeTrue())
+
+		install(clientDir, map[string][]byte{
+			"client.crt": fixture("green-certs/client.crt"),
+			"client.key": fixture("green-certs/client.key"),
+		})
+		clock.Increment(checkInterval)
+		httpClient.Transport.(*http.Transport).CloseIdleConnections()
+
+		get(httpClient, "/fast")
+		Expect((<-peerCertificates).Equal(certificate("green-certs/client.crt"))).To(BeTrue())
+	})
+
+	It("does not drop connections during a rotation", func() {
+		oldClient := newClient()
+		get(oldClient, "/fast")
+
+		inFlight := make(chan *http.Response)
+		go func() {
+			defer GinkgoRecover()
+			inFlight <- get(oldClient, "/slow")
+		}()
+		Consistently(inFlight).ShouldNot(Receive())
+
+		install(serverDir, map[string][]byte{
+			"server.crt": fixture("green-certs/server.crt"),
+			"server.key": fixture("green-certs/server.key"),
+		})
+		install(clientDir, map[string][]byte{
+			"client.crt": fixture("green-certs/client.crt"),
+			"client.key": fixture("green-certs/client.key"),
+		})
+		clock.Increment(checkInterval)
+
+		get(newClient(), "/fast")
+		get(oldClient, "/fast")
+
+		close(release)
+		var resp *http.Response
+		Eventually(inFlight).Should(Receive(&resp))
+		Expect(resp.TLS.PeerCertificates[0].Equal(certificate("blue-certs/server.crt"))).To(BeTrue())
+	})
+
+	It("keeps the previous certificate while the files are only partly replaced", func() {
+		install(serverDir, map[string][]byte{
+			"server.crt": fixture("green-certs/server.crt"),
+		})
+		clock.Increment(checkInterval)
+
+		resp := get(newClient(), "/fast")
+		Expect(resp.TLS.PeerCertificates[0].Equal(certificate("blue-certs/server.crt"))).To(BeTrue())
+		Expect(logger).To(gbytes.Say("failed-to-reload"))
+
+		install(serverDir, map[string][]byte{
+			"server.key": fixture("green-certs/server.key"),
+		})
+		clock.Increment(checkInterval)
+
+		resp = get(newClient(), "/fast")
+		Expect(resp.TLS.PeerCertificates[0].Equal(certificate("green-certs/server.crt"))).To(BeTrue())
+	})
+
+	It("only trusts peers signed by a CA in the bundle", func() {
+		install(clientDir, map[string][]byte{
+			"ca.crt": fixture("green-certs/server-ca.crt"),
+		})
+		clock.Increment(checkInterval)
+
+		_, err := newClient().Get(server.URL + "/fast")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("when the server stalls the handshake", func() {
+		var listener net.Listener
+
+		clientWithHandshakeTimeout := func(timeout time.Duration) *http.Client {
+			httpClient := &http.Client{Transport: &http.Transport{TLSHandshakeTimeout: timeout}}
+			_, err := rep.NewClientFactory(httpClient, httpClient, &rep.TLSConfig{RequireTLS: true, Reloader: clientReloader})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			return httpClient
+		}
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+				}
+			}()
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		It("gives up after the handshake timeout", func() {
+			httpClient := clientWithHandshakeTimeout(100 * time.Millisecond)
+
+			errs := make(chan error, 1)
+			go func() {
+				_, err := httpClient.Get("https://" + listener.Addr().String() + "/fast")
+				errs <- err
+			}()
+			Eventually(errs).Should(Receive(HaveOccurred()))
+		})
+
+		It("gives up when the request is cancelled", func() {
+			httpClient := clientWithHandshakeTimeout(time.Hour)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			req, err := http.NewRequest("GET", "https://"+listener.Addr().String()+"/fast", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			errs := make(chan error, 1)
+			go func() {
+				_, err := httpClient.Do(req.WithContext(ctx))
+				errs <- err
+			}()
+			Consistently(errs).ShouldNot(Receive())
+			cancel()
+			Eventually(errs).Should(Receive(HaveOccurred()))
+		})
+	})
+
+	It("uses the same tls settings as cfhttp", func() {
+		config := serverReloader.ServerTLSConfig()
+		Expect(config.MinVersion).To(BeEquivalentTo(tls.VersionTLS12))
+		Expect(config.ClientAuth).To(Equal(tls.RequireAndVerifyClientCert))
+	})
+})
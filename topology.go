@@ -0,0 +1,60 @@
+package rep
+
+// TopologyPath is the location of the cell from the broadest level of the
+// topology to the narrowest, e.g. region, zone and rack. Cells without a
+// configured topology are located by their zone alone.
+func (c *CellState) TopologyPath() []string {
+	if len(c.Topology) > 0 {
+		return c.Topology
+	}
+	if c.Zone != "" {
+		return []string{c.Zone}
+	}
+	return nil
+}
+
+// TopologySpreadScore scores placing an instance of processGuid on c, given
+// every cell in the auction. At each level of c's topology path it adds the
+// fraction of the process's instances already in the same domain, so lower
+// scores spread instances across zones and, within a zone, across racks.
+func TopologySpreadScore(c *CellState, cells []*CellState, processGuid string) float64 {
+	path := c.TopologyPath()
+	counts := make([]int, len(path))
+	total := 0
+
+	for _, cell := range cells {
+		instances := 0
+		for i := range cell.LRPs {
+			if cell.LRPs[i].ProcessGuid == processGuid {
+				instances++
+			}
+		}
+		if instances == 0 {
+			continue
+		}
+		total += instances
+
+		depth := commonPrefix(path, cell.TopologyPath())
+		for level := 0; level < depth; level++ {
+			counts[level] += instances
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	score := 0.0
+	for _, count := range counts {
+		score += float64(count) / float64(total)
+	}
+	return score
+}
+
+func commonPrefix(a, b []string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
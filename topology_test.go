@@ -0,0 +1,81 @@
+package rep_test
+
+import (
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Topology", func() {
+	newCell := func(zone string, topology []string, processGuids ...string) *rep.CellState {
+		state := rep.NewCellState(nil, rep.NewResources(1000, 1000, 10), rep.NewResources(1000, 1000, 10), nil, nil, zone, 0, false, nil, nil, nil)
+		state.Topology = topology
+		for i, processGuid := range processGuids {
+			state.LRPs = append(state.LRPs, *BuildLRP(processGuid, "domain", i, "", 10, 10, 0))
+		}
+		return &state
+	}
+
+	Describe("TopologyPath", func() {
+		It("returns the configured topology", func() {
+			cell := newCell("z1", []string{"region", "z1", "rack-1"})
+			Expect(cell.TopologyPath()).To(Equal([]string{"region", "z1", "rack-1"}))
+		})
+
+		It("falls back to the zone", func() {
+			Expect(newCell("z1", nil).TopologyPath()).To(Equal([]string{"z1"}))
+			Expect(newCell("", nil).TopologyPath()).To(BeEmpty())
+		})
+	})
+
+	Describe("TopologySpreadScore", func() {
+		var cells map[string]*rep.CellState
+
+		BeforeEach(func() {
+			cells = map[string]*rep.CellState{
+				"z1-rack-1-a": newCell("z1", []string{"region", "z1", "rack-1"}, "pg-1"),
+				"z1-rack-1-b": newCell("z1", []string{"region", "z1", "rack-1"}),
+				"z1-rack-2":   newCell("z1", []string{"region", "z1", "rack-2"}),
+				"z2-rack-1":   newCell("z2", []string{"region", "z2", "rack-1"}, "pg-2"),
+			}
+		})
+
+		score := func(name string) float64 {
+			all := []*rep.CellState{}
+			for _, cell := range cells {
+				all = append(all, cell)
+			}
+			return rep.TopologySpreadScore(cells[name], all, "pg-1")
+		}
+
+		It("is zero when the process has no instances", func() {
+			for _, cell := range cells {
+				Expect(rep.TopologySpreadScore(cell, []*rep.CellState{cell}, "pg-3")).To(BeZero())
+			}
+		})
+
+		It("prefers another zone over another rack in the same zone", func() {
+			Expect(score("z2-rack-1")).To(BeNumerically("<", score("z1-rack-2")))
+		})
+
+		It("prefers another rack over another host in the same rack", func() {
+			Expect(score("z1-rack-2")).To(BeNumerically("<", score("z1-rack-1-b")))
+		})
+
+		It("counts instances at every shared level", func() {
+			Expect(score("z1-rack-1-b")).To(Equal(3.0))
+			Expect(score("z1-rack-2")).To(Equal(2.0))
+			Expect(score("z2-rack-1")).To(Equal(1.0))
+		})
+
+		It("spreads across zones for cells without a topology", func() {
+			cells = map[string]*rep.CellState{
+				"z1-a": newCell("z1", nil, "pg-1"),
+				"z1-b": newCell("z1", nil),
+				"z2":   newCell("z2", nil),
+			}
+			Expect(score("z2")).To(BeZero())
+			Expect(score("z1-b")).To(Equal(1.0))
+		})
+	})
+})